@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"todo/internal/todo/services"
+	"todo/internal/todo/storage"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+// imports export of Trello, Todoist or json export of this service for user, prints report of import as json
+func main() {
+	_ = godotenv.Load()
+
+	var source, file, name, dsn string
+	var userID uint
+	var dryRun bool
+	flag.StringVar(&source, "source", "", "source of export: 'trello', 'todoist' or 'todo'")
+	flag.StringVar(&file, "file", "", "path to export file")
+	flag.UintVar(&userID, "user", 0, "id of user who imports boards")
+	flag.StringVar(&name, "name", "", "board name for Todoist csv")
+	flag.BoolVar(&dryRun, "dry-run", false, "only report what will be imported")
+	flag.StringVar(&dsn, "db", os.Getenv("DBDSN"), "DBDSN for database")
+	flag.Parse()
+
+	if source == "" || file == "" || userID == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	db, err := storage.Connection(dsn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	log := zap.NewNop()
+	service := services.NewImportService(storage.NewImportStore(db, log), log)
+
+	report, err := service.Import(source, data, name, dryRun, userID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import error:", err)
+		os.Exit(1)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	if err := out.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -53,19 +56,43 @@ func Migrate(db *pgxpool.Pool, migrationPath string, direction string) error {
 		return err
 	}
 
+	var names []string
 	for _, file := range files {
 		if strings.HasSuffix(file.Name(), fmt.Sprintf(".%s.sql", direction)) {
-			sqlFilePath := filepath.Join(migrationPath, file.Name())
-			err := executeMigration(db, sqlFilePath)
-			if err != nil {
-				return err
-			}
+			names = append(names, file.Name())
+		}
+	}
+
+	// files are sorted by number, so 10_ goes after 9_, down migrations run in reverse order
+	sort.Slice(names, func(i, j int) bool {
+		return migrationNumber(names[i]) < migrationNumber(names[j])
+	})
+	if direction == "down" {
+		slices.Reverse(names)
+	}
+
+	for _, name := range names {
+		sqlFilePath := filepath.Join(migrationPath, name)
+		err := executeMigration(db, sqlFilePath)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// number before the first "_" of migration file name
+func migrationNumber(name string) int {
+	prefix, _, _ := strings.Cut(name, "_")
+	n, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
 func executeMigration(db *pgxpool.Pool, sqlFilePath string) error {
 	schemaSQL, err := os.ReadFile(sqlFilePath)
 	if err != nil {
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"todo/internal/tg/dto"
+)
+
+// UploadAttachment attaches file to task, todo service checks content by sha256 of it
+func (c *Client) UploadAttachment(tgUserID int64, taskID uint, filename string, content []byte) (*dto.Attachment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	path := fmt.Sprintf("/internal/tg/%d/tasks/%d/attachments", tgUserID, taskID)
+	headers := map[string]string{"X-Checksum-SHA256": hex.EncodeToString(sum[:])}
+
+	status, respBody, err := c.send(http.MethodPost, path, body.Bytes(), form.FormDataContentType(), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachment dto.Attachment
+	if err := decodeResponse(status, respBody, &attachment); err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"todo/pkg/signature"
+
+	"go.uber.org/zap"
+)
+
+// Client calls internal routes of todo service, every request is signed with the shared secret
+type Client struct {
+	appURL string
+	secret string
+	client *http.Client
+}
+
+// Error is a non 2xx response of todo service
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("todo service responded %d: %s", e.Status, e.Message)
+}
+
+func New(appURL string, secret string) *Client {
+	return &Client{
+		appURL: appURL,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// post sends signed json and returns status code and response body
+func (c *Client) post(path string, payload any) (int, []byte, error) {
+	return c.request(http.MethodPost, path, payload)
+}
+
+// request sends signed request, payload is encoded to json when not nil
+func (c *Client) request(method string, path string, payload any) (int, []byte, error) {
+	var jsonStr []byte
+	if payload != nil {
+		var err error
+		jsonStr, err = json.Marshal(payload)
+		if err != nil {
+			zap.S().Error("error marshalling DTO", zap.Error(err))
+			return 0, nil, err
+		}
+	}
+
+	return c.send(method, path, jsonStr, "application/json", nil)
+}
+
+// send signs body and sends it with content type and extra headers
+func (c *Client) send(method string, path string, body []byte, contentType string, headers map[string]string) (int, []byte, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", c.appURL, path), bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	signature.Sign(req, body, c.secret)
+
+	response, err := c.client.Do(req)
+	if err != nil {
+		zap.S().Error("error calling todo service", zap.String("path", path), zap.Error(err))
+		return 0, nil, err
+	}
+	defer response.Body.Close()
+
+	respBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		zap.S().Error("error reading response body", zap.Error(err))
+		return response.StatusCode, nil, err
+	}
+
+	return response.StatusCode, respBody, nil
+}
+
+// userRequest calls api on behalf of linked telegram user and decodes response to out
+func (c *Client) userRequest(tgUserID int64, method string, path string, payload any, out any) error {
+	status, body, err := c.request(method, fmt.Sprintf("/internal/tg/%d%s", tgUserID, path), payload)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(status, body, out)
+}
+
+// decodeResponse turns non 2xx response to Error and decodes json body to out
+func decodeResponse(status int, body []byte, out any) error {
+	if status < 200 || status >= 300 {
+		return &Error{Status: status, Message: string(bytes.TrimSpace(body))}
+	}
+
+	if out != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"todo/internal/tg/dto"
+)
+
+var ErrInvalidCode = errors.New("link code is invalid or expired")
+
+func (c *Client) LinkTelegram(code string, tgUserID int64, chatID int64, tgName string) error {
+	dto := dto.LinkTelegram{
+		Code:     code,
+		TgUserID: tgUserID,
+		ChatID:   chatID,
+		TgName:   tgName,
+	}
+
+	status, body, err := c.post("/link-telegram", dto)
+	if err != nil {
+		return err
+	}
+
+	if status == http.StatusNotFound {
+		return ErrInvalidCode
+	}
+
+	if status != http.StatusCreated {
+		return fmt.Errorf("todo service responded %d: %s", status, string(body))
+	}
+
+	return nil
+}
+
+// ActivateTelegram turns notifications of linked user on again, true if they were stopped
+func (c *Client) ActivateTelegram(tgUserID int64, chatID int64) (bool, error) {
+	var res dto.TgActivated
+	err := c.userRequest(tgUserID, http.MethodPost, "/telegram/activate", dto.TgActivate{ChatID: chatID}, &res)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Reactivated, nil
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+	"todo/internal/tg/dto"
+)
+
+func (c *Client) GetNotifications(tgUserID int64) (*dto.NotificationSettings, error) {
+	var settings dto.NotificationSettings
+	err := c.userRequest(tgUserID, http.MethodGet, "/notifications", nil, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+func (c *Client) UpdateNotifications(tgUserID int64, body dto.NotificationSettings) (*dto.NotificationSettings, error) {
+	var settings dto.NotificationSettings
+	err := c.userRequest(tgUserID, http.MethodPut, "/notifications", body, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"todo/internal/tg/dto"
+)
+
+func (c *Client) GetBoards(tgUserID int64) ([]dto.Board, error) {
+	var boards []dto.Board
+	err := c.userRequest(tgUserID, http.MethodGet, "/boards", nil, &boards)
+	if err != nil {
+		return nil, err
+	}
+
+	return boards, nil
+}
+
+func (c *Client) LinkBoardChat(tgUserID int64, boardID uint, chatID int64) error {
+	body := dto.PutBoardChat{ChatId: chatID}
+
+	return c.userRequest(tgUserID, http.MethodPut, fmt.Sprintf("/boards/%d/chat", boardID), body, nil)
+}
+
+func (c *Client) UnlinkBoardChat(tgUserID int64, boardID uint) error {
+	return c.userRequest(tgUserID, http.MethodDelete, fmt.Sprintf("/boards/%d/chat", boardID), nil, nil)
+}
+
+func (c *Client) GetTasks(tgUserID int64, boardID uint) ([]dto.Task, error) {
+	path := "/tasks"
+	if boardID != 0 {
+		path = fmt.Sprintf("/tasks?board_id=%d", boardID)
+	}
+
+	var tasks []dto.Task
+	err := c.userRequest(tgUserID, http.MethodGet, path, nil, &tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetMyTasks gives tasks assigned to user with status, the same as in digest
+func (c *Client) GetMyTasks(tgUserID int64, statusID uint) ([]dto.Task, error) {
+	var tasks []dto.Task
+	err := c.userRequest(tgUserID, http.MethodGet, fmt.Sprintf("/tasks/my?status=%d", statusID), nil, &tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (c *Client) GetTask(tgUserID int64, id uint) (*dto.Task, error) {
+	var task dto.Task
+	err := c.userRequest(tgUserID, http.MethodGet, fmt.Sprintf("/tasks/%d", id), nil, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (c *Client) CreateTask(tgUserID int64, body dto.PostTask) (*dto.Task, error) {
+	var task dto.Task
+	err := c.userRequest(tgUserID, http.MethodPost, "/tasks", body, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// CreateTaskFromTemplate creates task by template name, board is optional when name is unique
+func (c *Client) CreateTaskFromTemplate(tgUserID int64, body dto.PostTaskFromTemplate) (*dto.Task, error) {
+	var task dto.Task
+	err := c.userRequest(tgUserID, http.MethodPost, "/tasks/from-template", body, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (c *Client) SetTaskStatus(tgUserID int64, id uint, statusID uint) (*dto.Task, error) {
+	body := dto.PutTaskStatus{StatusId: statusID}
+
+	var task dto.Task
+	err := c.userRequest(tgUserID, http.MethodPut, fmt.Sprintf("/tasks/%d/status", id), body, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func (c *Client) DeleteTask(tgUserID int64, id uint) error {
+	return c.userRequest(tgUserID, http.MethodDelete, fmt.Sprintf("/tasks/%d", id), nil, nil)
+}
+
+func (c *Client) GetStatuses(tgUserID int64) ([]dto.Status, error) {
+	var statuses []dto.Status
+	err := c.userRequest(tgUserID, http.MethodGet, "/statuses", nil, &statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+func (c *Client) SnoozeTask(tgUserID int64, id uint, minutes int) error {
+	body := dto.PostSnooze{Minutes: minutes}
+
+	return c.userRequest(tgUserID, http.MethodPost, fmt.Sprintf("/tasks/%d/snooze", id), body, nil)
+}
+
+func (c *Client) AddComment(tgUserID int64, id uint, text string) error {
+	body := dto.PostComment{Text: text}
+
+	return c.userRequest(tgUserID, http.MethodPost, fmt.Sprintf("/tasks/%d/comments", id), body, nil)
+}
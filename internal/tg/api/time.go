@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"todo/internal/tg/dto"
+)
+
+// StartTimer starts timer on task, running timer of user is stopped and returned in response
+func (c *Client) StartTimer(tgUserID int64, taskID uint, note string) (*dto.TimerStarted, error) {
+	body := dto.PostTimer{Note: note}
+
+	var started dto.TimerStarted
+	err := c.userRequest(tgUserID, http.MethodPost, fmt.Sprintf("/tasks/%d/timer/start", taskID), body, &started)
+	if err != nil {
+		return nil, err
+	}
+
+	return &started, nil
+}
+
+func (c *Client) StopTimer(tgUserID int64) (*dto.TimeEntry, error) {
+	var stopped dto.TimeEntry
+	err := c.userRequest(tgUserID, http.MethodPost, "/timer/stop", nil, &stopped)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stopped, nil
+}
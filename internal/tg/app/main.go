@@ -5,11 +5,15 @@ import (
 	"net/http"
 	"time"
 	"todo/internal/tg/api"
+	"todo/internal/tg/commands"
 	"todo/internal/tg/config"
 	"todo/internal/tg/handler"
+	"todo/internal/tg/sender"
 	"todo/internal/tg/service"
+	"todo/internal/tg/updates"
 
 	"todo/pkg/logger"
+	"todo/pkg/signature"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jasonlvhit/gocron"
@@ -24,6 +28,7 @@ func StartTgBot() {
 	cfg, err := config.GetConfig()
 	if err != nil {
 		fmt.Println(err.Error())
+		return
 	}
 
 	// init logger
@@ -47,66 +52,66 @@ func StartTgBot() {
 		}
 	}()
 
-	serv := service.New(log, bot)
+	snd := sender.New(bot, log)
+
+	serv := service.New(log, snd)
+
+	todoAPI := api.New(cfg.ToDoAppURL, cfg.InternalSecret)
 
 	h := handler.New(serv, log)
 
 	r := chi.NewRouter()
 
+	// routes for todo service, requests must be signed with INTERNAL_SECRET.
+	// Bot runs as a single instance (one getUpdates poller, rate limits and delivered keys are in memory),
+	// so nonces are kept in memory too
+	r.Group(func(r chi.Router) {
+		r.Use(signature.Verify(cfg.InternalSecret, signature.DefaultWindow))
+		r.Post("/create-task", h.CreateTask)
+		r.Post("/remind-task", h.RemindTask)
+		r.Post("/task-status", h.TaskStatusChanged)
+		r.Post("/task-comment", h.CommentAdded)
+		r.Post("/assign-task", h.AssignedTask)
+		r.Post("/due-task", h.DueTask)
+		r.Post("/notify", h.Notify)
+		r.Post("/scheduler", h.Scheduler)
+	})
+
+	cmd := commands.New(bot, bot.Self.ID, snd, todoAPI, log)
+
+	// sender waits for free slot of chat, so chats are handled apart from each other
+	chats := updates.NewChatQueue(cmd, log)
+
+	if cfg.Mode == config.ModeWebhook {
+		r.Post(cfg.WebhookPath, updates.Webhook(cfg.WebhookSecret, chats, log))
+	}
+
 	srv := &http.Server{
 		Addr:    cfg.TgAddress,
 		Handler: r,
 	}
 
-	go func() {
+	if cfg.Mode == config.ModeWebhook {
+		if err := updates.SetWebhook(bot, cfg.WebhookURL+cfg.WebhookPath, cfg.WebhookSecret); err != nil {
+			log.Fatal("error set webhook", zap.Error(err))
+		}
+		log.Info("receiving updates by webhook", zap.String("path", cfg.WebhookPath))
+
+		// updates are handled by http server
 		if err := srv.ListenAndServe(); err != nil {
 			log.Error("failed to start server", zap.Error(err))
 		}
-	}()
-
-	r.Post("/create-task", h.CreateTask)
-	r.Post("/scheduler", h.Scheduler)
-
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-
-	updates, err := bot.GetUpdatesChan(u)
-	if err != nil {
 		return
 	}
 
-	for update := range updates {
-		if update.Message == nil { // ignore non-Message Updates
-			continue
-		}
-
-		if update.Message.IsCommand() {
-			chatID := update.Message.Chat.ID
-			tgUsername := update.Message.From.UserName
-
-			if tgUsername == "" {
-				bot.Send(tgbotapi.NewMessage(chatID, "У вас не установлен Telegram username"))
-				return
-			}
-
-			switch update.Message.Command() {
-			case "start":
-				err := api.AddChatID(tgUsername, chatID, cfg.ToDoAppURL)
-				if err != nil {
-					bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при регистрации. Попробуйте снова."))
-					return
-				}
-
-				bot.Send(tgbotapi.NewMessage(chatID, "Вы зарегистрированы!"))
-			case "tasks":
-				err := api.SendAllTasks(tgUsername, chatID, cfg.ToDoAppURL)
-				if err != nil {
-					bot.Send(tgbotapi.NewMessage(chatID, "Ошибка при получении списка задач. Попробуйте снова."))
-					return
-				}
-			}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil {
+			log.Error("failed to start server", zap.Error(err))
 		}
+	}()
 
+	// polling is used for development
+	if err := updates.Poll(bot, chats, log); err != nil {
+		log.Error("error receiving updates", zap.Error(err))
 	}
-
 }
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+// bot api gives files up to 20 MB, todo service accepts the same
+const attachmentMaxBytes = 20 << 20
+
+// id of task in text of notification, like "#12 Название"
+var notificationTaskID = regexp.MustCompile(`#(\d+)`)
+
+var errFileTooLarge = errors.New("file is too large")
+
+// url of file contains token of bot, so it is never logged
+var fileClient = &http.Client{Timeout: time.Minute}
+
+// replyTaskID finds task of bot message the message replies to
+func (c *Commands) replyTaskID(message *tgbotapi.Message) (uint, bool) {
+	reply := message.ReplyToMessage
+	if reply == nil || reply.From == nil || reply.From.ID != c.botID {
+		return 0, false
+	}
+
+	match := notificationTaskID.FindStringSubmatch(reply.Text)
+	if match == nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(match[1], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(id), true
+}
+
+// messageFile gives document or the largest size of photo
+func messageFile(message *tgbotapi.Message) (fileID string, filename string, size int, ok bool) {
+	if message.Document != nil {
+		filename = message.Document.FileName
+		if filename == "" {
+			filename = "file"
+		}
+		return message.Document.FileID, filename, message.Document.FileSize, true
+	}
+
+	if message.Photo != nil && len(*message.Photo) > 0 {
+		photos := *message.Photo
+		photo := photos[len(photos)-1]
+		filename = fmt.Sprintf("photo_%s.jpg", time.Unix(int64(message.Date), 0).Format("20060102_150405"))
+		return photo.FileID, filename, photo.FileSize, true
+	}
+
+	return "", "", 0, false
+}
+
+// attach saves file of message to task of notification it replies to
+func (c *Commands) attach(message *tgbotapi.Message, taskID uint) {
+	chatID := message.Chat.ID
+	fileID, filename, size, ok := messageFile(message)
+	if !ok {
+		return
+	}
+
+	if size > attachmentMaxBytes {
+		c.reply(chatID, "Файл больше 20 МБ, прикрепите его в приложении.")
+		return
+	}
+
+	content, err := c.downloadFile(fileID)
+	if errors.Is(err, errFileTooLarge) {
+		c.reply(chatID, "Файл больше 20 МБ, прикрепите его в приложении.")
+		return
+	}
+	if err != nil {
+		c.logger.Error("download telegram file", zap.Error(err))
+		c.reply(chatID, "Не удалось получить файл, попробуйте снова.")
+		return
+	}
+
+	attachment, err := c.api.UploadAttachment(int64(message.From.ID), taskID, filename, content)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Файл «%s» прикреплен к задаче #%d", attachment.Filename, taskID))
+}
+
+func (c *Commands) downloadFile(fileID string) ([]byte, error) {
+	fileURL, err := c.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := fileClient.Get(fileURL)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("telegram file responded %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, attachmentMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) > attachmentMaxBytes {
+		return nil, errFileTooLarge
+	}
+
+	return content, nil
+}
@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"todo/internal/tg/api"
+	"todo/internal/tg/dto"
+	"todo/internal/tg/utils"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+const statusInProcess = 1
+
+// handleCallback processes button presses, user is authorized by todo api with his telegram id
+func (c *Commands) handleCallback(query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		c.answer(query.ID, "")
+		return
+	}
+
+	kind, action, arg, ok := utils.ParseCallback(query.Data)
+	if !ok {
+		c.answer(query.ID, "Неизвестное действие")
+		return
+	}
+
+	switch kind {
+	case utils.CallbackTask:
+		c.taskAction(query, int64(query.From.ID), action, arg)
+	case utils.CallbackDigest:
+		c.digestPage(query, int64(query.From.ID), action, int(arg))
+	case utils.CallbackNotify:
+		c.toggleNotification(query, int64(query.From.ID), action, arg)
+	}
+}
+
+func (c *Commands) taskAction(query *tgbotapi.CallbackQuery, tgUserID int64, action string, taskID uint) {
+	var (
+		task  *dto.Task
+		state string
+		err   error
+	)
+
+	switch action {
+	case utils.ActionDone:
+		task, err = c.api.SetTaskStatus(tgUserID, taskID, statusDone)
+		state = "✅ Выполнено"
+	case utils.ActionStart:
+		task, err = c.api.SetTaskStatus(tgUserID, taskID, statusInProcess)
+		state = "▶️ В работе"
+	case utils.ActionSnooze1h:
+		err = c.api.SnoozeTask(tgUserID, taskID, 60)
+		state = "⏰ Напомню через час"
+	case utils.ActionSnooze1d:
+		err = c.api.SnoozeTask(tgUserID, taskID, 24*60)
+		state = "⏰ Напомню завтра"
+	case utils.ActionInfo:
+		c.taskInfo(query, tgUserID, taskID)
+		return
+	case utils.ActionMore:
+		c.taskMore(query, tgUserID, taskID)
+		return
+	default:
+		c.answer(query.ID, "Неизвестное действие")
+		return
+	}
+
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	if task == nil {
+		task, err = c.api.GetTask(tgUserID, taskID)
+		if err != nil {
+			c.answer(query.ID, callbackError(err, c.logger))
+			return
+		}
+	}
+
+	description, _ := utils.Description(task.Description, utils.DescriptionPreviewLength)
+	text := fmt.Sprintf("#%d <b>%s</b>\nОписание: %s\n\n%s", task.ID, utils.EscapeHTML(task.Title), description, state)
+	c.edit(query.Message, text, utils.TaskKeyboard(task.ID))
+	c.answer(query.ID, state)
+}
+
+func (c *Commands) taskInfo(query *tgbotapi.CallbackQuery, tgUserID int64, taskID uint) {
+	text, err := c.taskCard(tgUserID, taskID)
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	c.edit(query.Message, text, utils.TaskKeyboard(taskID))
+	c.answer(query.ID, "")
+}
+
+// taskMore sends task from digest as new message, digest stays as is
+func (c *Commands) taskMore(query *tgbotapi.CallbackQuery, tgUserID int64, taskID uint) {
+	text, err := c.taskCard(tgUserID, taskID)
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = utils.TaskKeyboard(taskID)
+
+	if _, err := c.sender.Send(query.Message.Chat.ID, msg); err != nil {
+		c.logger.Error("send task", zap.Int64("chat_id", query.Message.Chat.ID), zap.Error(err))
+	}
+	c.answer(query.ID, "")
+}
+
+// taskCard gives HTML of task with full description
+func (c *Commands) taskCard(tgUserID int64, taskID uint) (string, error) {
+	task, err := c.api.GetTask(tgUserID, taskID)
+	if err != nil {
+		return "", err
+	}
+
+	statuses, err := c.api.GetStatuses(tgUserID)
+	if err != nil {
+		return "", err
+	}
+
+	text := utils.EscapeHTML(formatTask(*task, statuses))
+	if task.Description != "" {
+		description, _ := utils.Description(task.Description, utils.DescriptionMaxLength)
+		text += "\nОписание:\n" + description
+	}
+
+	return text, nil
+}
+
+// digestPage builds page of digest again from current tasks of user, pages aren't kept in memory
+func (c *Commands) digestPage(query *tgbotapi.CallbackQuery, tgUserID int64, status string, page int) {
+	statusID, err := strconv.ParseUint(status, 10, 32)
+	if err != nil {
+		c.answer(query.ID, "Неизвестное действие")
+		return
+	}
+
+	tasks, err := c.api.GetMyTasks(tgUserID, uint(statusID))
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	mess := make([]dto.MessDto, 0, len(tasks))
+	for _, task := range tasks {
+		mess = append(mess, dto.MessDto{
+			TaskId:      task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			StatusId:    task.StatusId,
+		})
+	}
+
+	// tasks changed since digest was sent, e.g. done tasks are archived at night
+	_, pages := utils.FormatTasksMessage(mess)
+	if len(tasks) == 0 || page >= len(pages) {
+		c.answer(query.ID, "Сводка устарела, запросите /tasks")
+		return
+	}
+
+	keyboard := utils.DigestKeyboard(uint(statusID), page, len(pages), pages[page].More)
+	if keyboard == nil {
+		c.edit(query.Message, pages[page].Text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	} else {
+		c.edit(query.Message, pages[page].Text, *keyboard)
+	}
+	c.answer(query.ID, "")
+}
+
+// edit replaces text and buttons of message in place, text is HTML
+func (c *Commands) edit(message *tgbotapi.Message, text string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.ReplyMarkup = &keyboard
+
+	// telegram rejects edit without changes, e.g. double press on the same page
+	if _, err := c.sender.Send(message.Chat.ID, edit); err != nil {
+		c.logger.Debug("edit message", zap.Int64("chat_id", message.Chat.ID), zap.Error(err))
+	}
+}
+
+// answer stops loading indicator on button and shows short notice
+func (c *Commands) answer(queryID string, text string) {
+	if _, err := c.bot.AnswerCallbackQuery(tgbotapi.NewCallback(queryID, text)); err != nil {
+		c.logger.Error("answer callback", zap.Error(err))
+	}
+}
+
+// callbackError makes short text for popup, callback answers are limited by 200 chars
+func callbackError(err error, logger *zap.Logger) string {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		logger.Error("todo api request", zap.Error(err))
+		return "Сервис задач недоступен, попробуйте позже."
+	}
+
+	switch apiErr.Status {
+	case http.StatusUnauthorized:
+		return "Аккаунт не привязан"
+	case http.StatusForbidden:
+		return "Нет доступа к этой задаче"
+	case http.StatusNotFound:
+		return "Задача не найдена"
+	case http.StatusBadRequest:
+		return "Ошибка: " + apiErr.Message
+	default:
+		logger.Error("todo api request", zap.Error(err))
+		return "Не удалось выполнить действие"
+	}
+}
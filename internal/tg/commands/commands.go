@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"todo/internal/tg/api"
+	"todo/internal/tg/dto"
+	"todo/internal/tg/utils"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+const helpText = `Команды:
+/tasks — текущие и выполненные задачи
+/add <название> [#доска] [!приоритет] [срок] — новая задача
+    приоритет: !1..!3 или !low, !medium, !high
+    срок: 2024-12-31, 31.12.2024, 31.12, today, tomorrow
+/new <шаблон> [#доска] — задача по шаблону
+/done <id> — отметить задачу выполненной
+/move <id> <статус> — сменить статус задачи
+/boards — список досок
+/board <название> — задачи доски
+/delete <id> — удалить задачу (с подтверждением)
+/comment <id> <текст> — комментарий к задаче
+/start_timer <id> [заметка] — запустить таймер, запущенный таймер остановится
+/stop_timer — остановить таймер
+/notifications — настройки уведомлений
+/quiet 23:00-08:00 [часовой пояс] — тихие часы, /quiet off — выключить
+/help — эта справка
+
+Файл или фото в ответ на уведомление о задаче прикрепляется к задаче
+
+В группе:
+/linkboard <название> — привязать доску к чату (только владелец доски)
+/unlinkboard — отвязать доску от чата
+/add, /new и /board без доски работают с доской чата`
+
+// how long /delete waits for /confirm
+const confirmTTL = time.Minute
+
+// Commands handles bot commands and calls todo api on behalf of linked user
+type Commands struct {
+	bot    Bot
+	botID  int
+	sender Sender
+	api    TodoAPI
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[int64]pendingDelete // by chat id
+}
+
+// Bot is part of telegram bot api used by commands, messages go through Sender
+type Bot interface {
+	AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error)
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// Sender sends messages within telegram limits
+type Sender interface {
+	Send(chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// TodoAPI is client of todo api, requests are made on behalf of telegram user
+type TodoAPI interface {
+	LinkTelegram(code string, tgUserID int64, chatID int64, tgName string) error
+	ActivateTelegram(tgUserID int64, chatID int64) (bool, error)
+	SendAllTasks(tgUserID int64, chatID int64) error
+	GetBoards(tgUserID int64) ([]dto.Board, error)
+	LinkBoardChat(tgUserID int64, boardID uint, chatID int64) error
+	UnlinkBoardChat(tgUserID int64, boardID uint) error
+	GetTasks(tgUserID int64, boardID uint) ([]dto.Task, error)
+	GetMyTasks(tgUserID int64, statusID uint) ([]dto.Task, error)
+	GetTask(tgUserID int64, id uint) (*dto.Task, error)
+	CreateTask(tgUserID int64, body dto.PostTask) (*dto.Task, error)
+	CreateTaskFromTemplate(tgUserID int64, body dto.PostTaskFromTemplate) (*dto.Task, error)
+	SetTaskStatus(tgUserID int64, id uint, statusID uint) (*dto.Task, error)
+	DeleteTask(tgUserID int64, id uint) error
+	GetStatuses(tgUserID int64) ([]dto.Status, error)
+	SnoozeTask(tgUserID int64, id uint, minutes int) error
+	AddComment(tgUserID int64, id uint, text string) error
+	UploadAttachment(tgUserID int64, taskID uint, filename string, content []byte) (*dto.Attachment, error)
+	GetNotifications(tgUserID int64) (*dto.NotificationSettings, error)
+	UpdateNotifications(tgUserID int64, body dto.NotificationSettings) (*dto.NotificationSettings, error)
+	StartTimer(tgUserID int64, taskID uint, note string) (*dto.TimerStarted, error)
+	StopTimer(tgUserID int64) (*dto.TimeEntry, error)
+}
+
+type pendingDelete struct {
+	taskID    uint
+	tgUserID  int64
+	expiresAt time.Time
+}
+
+// botID is id of bot user, files are attached only in reply to messages of bot
+func New(bot Bot, botID int, sender Sender, api TodoAPI, logger *zap.Logger) *Commands {
+	return &Commands{
+		bot:     bot,
+		botID:   botID,
+		sender:  sender,
+		api:     api,
+		logger:  logger,
+		pending: make(map[int64]pendingDelete),
+	}
+}
+
+// Handle processes one update from telegram
+func (c *Commands) Handle(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		c.handleCallback(update.CallbackQuery)
+		return
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	if !update.Message.IsCommand() {
+		// file sent in reply to task notification is attached to the task, other messages are ignored
+		if taskID, ok := c.replyTaskID(update.Message); ok {
+			c.attach(update.Message, taskID)
+		}
+		return
+	}
+
+	message := update.Message
+	chatID := message.Chat.ID
+	group := message.Chat.IsGroup() || message.Chat.IsSuperGroup()
+	// numeric id doesn't change with username, so accounts are linked by it
+	tgUserID := int64(message.From.ID)
+	args := strings.TrimSpace(message.CommandArguments())
+
+	switch message.Command() {
+	case "start":
+		c.start(chatID, tgUserID, message.From.UserName, args, message.Chat.IsPrivate())
+	case "tasks":
+		err := c.api.SendAllTasks(tgUserID, chatID)
+		if err != nil {
+			c.reply(chatID, "Ошибка при получении списка задач. Попробуйте снова.")
+		}
+	case "add":
+		c.add(chatID, tgUserID, args, group)
+	case "new":
+		c.newFromTemplate(chatID, tgUserID, args)
+	case "done":
+		c.done(chatID, tgUserID, args)
+	case "move":
+		c.move(chatID, tgUserID, args)
+	case "boards":
+		c.boards(chatID, tgUserID)
+	case "board":
+		c.board(chatID, tgUserID, args)
+	case "linkboard":
+		c.linkBoard(chatID, tgUserID, args, group)
+	case "unlinkboard":
+		c.unlinkBoard(chatID, tgUserID)
+	case "notifications":
+		c.notifications(chatID, tgUserID, group)
+	case "quiet":
+		c.quiet(chatID, tgUserID, args)
+	case "comment":
+		c.comment(chatID, tgUserID, args)
+	case "start_timer":
+		c.startTimer(chatID, tgUserID, args)
+	case "stop_timer":
+		c.stopTimer(chatID, tgUserID)
+	case "delete":
+		c.delete(chatID, tgUserID, args)
+	case "confirm":
+		c.confirm(chatID, tgUserID)
+	case "cancel":
+		c.cancel(chatID)
+	case "help":
+		c.reply(chatID, helpText)
+	default:
+		c.reply(chatID, "Неизвестная команда. Список команд: /help")
+	}
+}
+
+func (c *Commands) start(chatID int64, tgUserID int64, tgName string, code string, private bool) {
+	// code comes from deep link t.me/<bot>?start=<code>
+	if code == "" {
+		c.restart(chatID, tgUserID, private)
+		return
+	}
+
+	// notifications go to chat of link, so group chat can't be linked to user
+	if !private {
+		c.reply(chatID, "Аккаунт привязывается только в личном чате. Откройте бота и отправьте /start <код> там.")
+		return
+	}
+
+	err := c.api.LinkTelegram(code, tgUserID, chatID, tgName)
+	if errors.Is(err, api.ErrInvalidCode) {
+		c.reply(chatID, "Код недействителен или устарел. Получите новый код в приложении.")
+		return
+	}
+	if err != nil {
+		c.logger.Error("link telegram", zap.Error(err))
+		c.reply(chatID, "Ошибка при регистрации. Попробуйте снова.")
+		return
+	}
+
+	c.reply(chatID, "Вы зарегистрированы!\n\n"+helpText)
+}
+
+// restart turns notifications on again for linked user who blocked bot or deleted chat
+func (c *Commands) restart(chatID int64, tgUserID int64, private bool) {
+	if private {
+		reactivated, err := c.api.ActivateTelegram(tgUserID, chatID)
+		var apiErr *api.Error
+		switch {
+		case err == nil && reactivated:
+			c.reply(chatID, "С возвращением! Уведомления о задачах снова включены.")
+			return
+		case err == nil:
+			c.reply(chatID, "Аккаунт уже привязан.\n\n"+helpText)
+			return
+		case !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized:
+			c.replyError(chatID, err)
+			return
+		}
+	}
+
+	c.reply(chatID, "Чтобы привязать аккаунт, получите код в приложении (POST /api/user/telegram/link) и отправьте /start <код>")
+}
+
+// reply sends text, long text goes in several messages
+func (c *Commands) reply(chatID int64, text string) {
+	for _, part := range utils.SplitMessage(text) {
+		if _, err := c.sender.Send(chatID, tgbotapi.NewMessage(chatID, part)); err != nil {
+			c.logger.Error("send message", zap.Int64("chat_id", chatID), zap.Error(err))
+			return
+		}
+	}
+}
+
+// replyError explains api error to user
+func (c *Commands) replyError(chatID int64, err error) {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		c.logger.Error("todo api request", zap.Error(err))
+		c.reply(chatID, "Сервис задач недоступен, попробуйте позже.")
+		return
+	}
+
+	switch apiErr.Status {
+	case http.StatusUnauthorized:
+		c.reply(chatID, "Аккаунт не привязан. Получите код в приложении и отправьте /start <код>")
+	case http.StatusForbidden:
+		c.reply(chatID, "Нет доступа к этой задаче или доске.")
+	case http.StatusNotFound:
+		c.reply(chatID, "Задача не найдена.")
+	case http.StatusBadRequest:
+		c.reply(chatID, "Ошибка: "+apiErr.Message)
+	case http.StatusRequestEntityTooLarge:
+		c.reply(chatID, "Файл слишком большой.")
+	default:
+		c.logger.Error("todo api request", zap.Error(err))
+		c.reply(chatID, "Не удалось выполнить команду, попробуйте позже.")
+	}
+}
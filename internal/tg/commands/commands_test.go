@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todo/internal/tg/api"
+	"todo/internal/tg/dto"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+const testBotID = 999
+
+// fakeAPI records calls to todo api, methods which aren't needed by fixtures panic
+type fakeAPI struct {
+	TodoAPI
+
+	boards []dto.Board
+	tasks  []dto.Task
+	calls  []string
+}
+
+func (f *fakeAPI) call(format string, args ...any) {
+	f.calls = append(f.calls, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeAPI) LinkTelegram(code string, tgUserID int64, chatID int64, tgName string) error {
+	f.call("LinkTelegram %s %d %d %s", code, tgUserID, chatID, tgName)
+	return nil
+}
+
+func (f *fakeAPI) ActivateTelegram(tgUserID int64, chatID int64) (bool, error) {
+	f.call("ActivateTelegram %d %d", tgUserID, chatID)
+	return true, nil
+}
+
+func (f *fakeAPI) GetBoards(tgUserID int64) ([]dto.Board, error) {
+	return f.boards, nil
+}
+
+func (f *fakeAPI) CreateTask(tgUserID int64, body dto.PostTask) (*dto.Task, error) {
+	f.call("CreateTask %d board=%s priority=%d %s", tgUserID, body.BoardId, body.Priority, body.Title)
+	return &dto.Task{ID: 42, Title: body.Title}, nil
+}
+
+func (f *fakeAPI) LinkBoardChat(tgUserID int64, boardID uint, chatID int64) error {
+	f.call("LinkBoardChat %d %d %d", tgUserID, boardID, chatID)
+	return nil
+}
+
+func (f *fakeAPI) SetTaskStatus(tgUserID int64, id uint, statusID uint) (*dto.Task, error) {
+	f.call("SetTaskStatus %d %d %d", tgUserID, id, statusID)
+	return &dto.Task{ID: id, Title: "Купить молоко", StatusId: statusID}, nil
+}
+
+func (f *fakeAPI) GetMyTasks(tgUserID int64, statusID uint) ([]dto.Task, error) {
+	f.call("GetMyTasks %d %d", tgUserID, statusID)
+	return f.tasks, nil
+}
+
+func (f *fakeAPI) UploadAttachment(tgUserID int64, taskID uint, filename string, content []byte) (*dto.Attachment, error) {
+	f.call("UploadAttachment %d %d %s %s", tgUserID, taskID, filename, content)
+	return &dto.Attachment{ID: 1, TaskId: taskID, Filename: filename, Size: int64(len(content))}, nil
+}
+
+// fakeBot answers callbacks and gives url of file on test server
+type fakeBot struct {
+	fileURL string
+	answers []string
+}
+
+func (f *fakeBot) AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error) {
+	f.answers = append(f.answers, config.Text)
+	return tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeBot) GetFileDirectURL(fileID string) (string, error) {
+	return f.fileURL + "/" + fileID, nil
+}
+
+// fakeSender keeps texts of sent and edited messages as "chat: text"
+type fakeSender struct {
+	sent []string
+}
+
+func (f *fakeSender) Send(chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		f.sent = append(f.sent, fmt.Sprintf("%d: %s", chatID, m.Text))
+	case tgbotapi.EditMessageTextConfig:
+		f.sent = append(f.sent, fmt.Sprintf("%d edit: %s", chatID, m.Text))
+	default:
+		return tgbotapi.Message{}, fmt.Errorf("unexpected %T", c)
+	}
+
+	return tgbotapi.Message{MessageID: 1}, nil
+}
+
+// loadUpdate reads update recorded from telegram
+func loadUpdate(t *testing.T, name string) tgbotapi.Update {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var update tgbotapi.Update
+	if err := json.Unmarshal(data, &update); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+
+	return update
+}
+
+func TestHandle(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("%PDF"))
+	}))
+	defer files.Close()
+
+	groupChat := int64(-200)
+	boards := []dto.Board{
+		{ID: 1, Name: "Работа", OwnerId: 1},
+		{ID: 2, Name: "Дом", OwnerId: 1, ChatId: &groupChat},
+	}
+
+	var digestTasks []dto.Task
+	for i := 1; i <= 7; i++ {
+		digestTasks = append(digestTasks, dto.Task{ID: uint(i), Title: fmt.Sprintf("Задача %d", i), StatusId: 1})
+	}
+
+	tests := []struct {
+		fixture string
+		calls   []string
+		sent    []string // prefixes of messages
+		answers []string
+	}{
+		{
+			fixture: "private_start_code",
+			calls:   []string{"LinkTelegram abc123 100 100 anna"},
+			sent:    []string{"100: Вы зарегистрированы!"},
+		},
+		{
+			fixture: "private_start",
+			calls:   []string{"ActivateTelegram 100 100"},
+			sent:    []string{"100: С возвращением!"},
+		},
+		{
+			// account is never activated from group chat
+			fixture: "group_start",
+			sent:    []string{"-200: Чтобы привязать аккаунт"},
+		},
+		{
+			// code sent in group isn't used, so it stays valid for private chat
+			fixture: "group_start_code",
+			sent:    []string{"-200: Аккаунт привязывается только в личном чате"},
+		},
+		{
+			fixture: "private_add",
+			calls:   []string{"CreateTask 100 board=2 priority=3 Купить молоко"},
+			sent:    []string{"100: Задача #42 добавлена на доску «Дом»"},
+		},
+		{
+			// in group without #board task goes to the board of the chat
+			fixture: "group_add_linked",
+			calls:   []string{"CreateTask 100 board=2 priority=0 Созвон"},
+			sent:    []string{"-200: Задача #42 добавлена на доску «Дом»"},
+		},
+		{
+			fixture: "group_add_unlinked",
+			sent:    []string{"-300: К этому чату не привязана ваша доска"},
+		},
+		{
+			fixture: "private_linkboard",
+			sent:    []string{"100: Команда работает только в групповом чате."},
+		},
+		{
+			fixture: "group_linkboard",
+			calls:   []string{"LinkBoardChat 100 1 -300"},
+			sent:    []string{"-300: Доска «Работа» привязана к чату"},
+		},
+		{
+			fixture: "group_notifications",
+			sent:    []string{"-200: Настройки уведомлений доступны в личном чате"},
+		},
+		{
+			fixture: "private_unknown",
+			sent:    []string{"100: Неизвестная команда"},
+		},
+		{
+			fixture: "callback_done",
+			calls:   []string{"SetTaskStatus 100 7 2"},
+			sent:    []string{"100 edit: #7 <b>Купить молоко</b>"},
+			answers: []string{"✅ Выполнено"},
+		},
+		{
+			// the second page is built again from tasks of user
+			fixture: "callback_digest",
+			calls:   []string{"GetMyTasks 100 1"},
+			sent:    []string{"100 edit: Ваши задачи:\n\n6. <b>Задача 6</b>"},
+			answers: []string{""},
+		},
+		{
+			fixture: "callback_unknown",
+			answers: []string{"Неизвестное действие"},
+		},
+		{
+			fixture: "reply_document",
+			calls:   []string{"UploadAttachment 100 7 check.pdf %PDF"},
+			sent:    []string{"100: Файл «check.pdf» прикреплен к задаче #7"},
+		},
+		{
+			// files are attached only in reply to messages of bot
+			fixture: "reply_to_user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			todo := &fakeAPI{boards: boards, tasks: digestTasks}
+			bot := &fakeBot{fileURL: files.URL}
+			snd := &fakeSender{}
+
+			c := New(bot, testBotID, snd, todo, zap.NewNop())
+			c.Handle(loadUpdate(t, tt.fixture))
+
+			if strings.Join(todo.calls, "\n") != strings.Join(tt.calls, "\n") {
+				t.Errorf("api calls:\n%v\nwant:\n%v", todo.calls, tt.calls)
+			}
+
+			if len(snd.sent) != len(tt.sent) {
+				t.Fatalf("sent %d messages %q, want %d", len(snd.sent), snd.sent, len(tt.sent))
+			}
+			for i, prefix := range tt.sent {
+				if !strings.HasPrefix(snd.sent[i], prefix) {
+					t.Errorf("message %d = %q, want prefix %q", i, snd.sent[i], prefix)
+				}
+			}
+
+			if strings.Join(bot.answers, "\n") != strings.Join(tt.answers, "\n") || len(bot.answers) != len(tt.answers) {
+				t.Errorf("answers %q, want %q", bot.answers, tt.answers)
+			}
+		})
+	}
+}
+
+// api errors are shown in reply, 401 asks to link account
+func TestHandleAPIError(t *testing.T) {
+	snd := &fakeSender{}
+	c := New(&fakeBot{}, testBotID, snd, &failingAPI{}, zap.NewNop())
+	c.Handle(loadUpdate(t, "private_add"))
+
+	if len(snd.sent) != 1 || !strings.HasPrefix(snd.sent[0], "100: Аккаунт не привязан") {
+		t.Fatalf("sent %q", snd.sent)
+	}
+}
+
+type failingAPI struct {
+	TodoAPI
+}
+
+func (f *failingAPI) GetBoards(tgUserID int64) ([]dto.Board, error) {
+	return nil, &api.Error{Status: http.StatusUnauthorized, Message: "telegram is not linked"}
+}
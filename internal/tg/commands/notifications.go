@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo/internal/tg/dto"
+	"todo/internal/tg/utils"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+// kinds of notifications in the order of menu
+var notificationKinds = []struct {
+	key  string
+	name string
+}{
+	{"created", "Новые задачи"},
+	{"assigned", "Назначения"},
+	{"commented", "Комментарии"},
+	{"due", "Сроки"},
+	{"status_changed", "Смена статуса"},
+	{"digest", "Ежедневная сводка"},
+	{"digest_analytics", "Статистика досок в сводке"},
+}
+
+// kindEnabled returns pointer to flag of notification kind
+func kindEnabled(settings *dto.NotificationSettings, key string) *bool {
+	switch key {
+	case "created":
+		return &settings.Created
+	case "assigned":
+		return &settings.Assigned
+	case "commented":
+		return &settings.Commented
+	case "due":
+		return &settings.Due
+	case "status_changed":
+		return &settings.StatusChanged
+	case "digest":
+		return &settings.Digest
+	case "digest_analytics":
+		return &settings.DigestAnalytics
+	}
+
+	return nil
+}
+
+func (c *Commands) notifications(chatID int64, tgUserID int64, group bool) {
+	if group {
+		c.reply(chatID, "Настройки уведомлений доступны в личном чате с ботом.")
+		return
+	}
+
+	settings, err := c.api.GetNotifications(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatNotifications(settings))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = notificationsKeyboard(settings, boards)
+
+	if _, err := c.sender.Send(chatID, msg); err != nil {
+		c.logger.Error("send notifications menu", zap.Int64("chat_id", chatID), zap.Error(err))
+	}
+}
+
+// toggleNotification switches kind of notifications or mute of board and redraws menu
+func (c *Commands) toggleNotification(query *tgbotapi.CallbackQuery, tgUserID int64, action string, boardID uint) {
+	settings, err := c.api.GetNotifications(tgUserID)
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	if action == "board" {
+		settings.MutedBoards = toggleID(settings.MutedBoards, boardID)
+	} else {
+		enabled := kindEnabled(settings, action)
+		if enabled == nil {
+			c.answer(query.ID, "Неизвестное действие")
+			return
+		}
+		*enabled = !*enabled
+	}
+
+	settings, err = c.api.UpdateNotifications(tgUserID, *settings)
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.answer(query.ID, callbackError(err, c.logger))
+		return
+	}
+
+	c.edit(query.Message, formatNotifications(settings), notificationsKeyboard(settings, boards))
+	c.answer(query.ID, "Сохранено")
+}
+
+// quiet sets quiet hours: /quiet 23:00-08:00 [Europe/Moscow] or /quiet off
+func (c *Commands) quiet(chatID int64, tgUserID int64, args string) {
+	words := strings.Fields(args)
+	if len(words) == 0 {
+		c.reply(chatID, "Использование: /quiet 23:00-08:00 [часовой пояс, например Europe/Moscow] или /quiet off")
+		return
+	}
+
+	settings, err := c.api.GetNotifications(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	if strings.EqualFold(words[0], "off") {
+		settings.QuietFrom, settings.QuietTo = "", ""
+	} else {
+		from, to, ok := strings.Cut(words[0], "-")
+		if !ok || !validClock(from) || !validClock(to) {
+			c.reply(chatID, "Укажите время в формате ЧЧ:ММ-ЧЧ:ММ, например /quiet 23:00-08:00")
+			return
+		}
+		settings.QuietFrom, settings.QuietTo = from, to
+
+		if len(words) > 1 {
+			settings.Timezone = words[1]
+		}
+	}
+
+	settings, err = c.api.UpdateNotifications(tgUserID, *settings)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	if settings.QuietFrom == "" {
+		c.reply(chatID, "Тихие часы выключены")
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Тихие часы: %s–%s (%s). Уведомления за это время придут одной сводкой.", settings.QuietFrom, settings.QuietTo, settings.Timezone))
+}
+
+func formatNotifications(settings *dto.NotificationSettings) string {
+	var sb strings.Builder
+	sb.WriteString("Настройки уведомлений. Нажмите на кнопку, чтобы включить или выключить.\n\n")
+
+	if settings.QuietFrom != "" {
+		fmt.Fprintf(&sb, "Тихие часы: %s–%s (%s)\n", settings.QuietFrom, settings.QuietTo, utils.EscapeHTML(settings.Timezone))
+	} else {
+		sb.WriteString("Тихие часы: выключены\n")
+	}
+	sb.WriteString("Изменить: /quiet 23:00-08:00 [часовой пояс] или /quiet off")
+
+	return sb.String()
+}
+
+func notificationsKeyboard(settings *dto.NotificationSettings, boards []dto.Board) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, kind := range notificationKinds {
+		mark := "❌"
+		if *kindEnabled(settings, kind.key) {
+			mark = "✅"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(mark+" "+kind.name, utils.NotifyCallback(kind.key, 0)),
+		))
+	}
+
+	for _, board := range boards {
+		mark := "🔔"
+		if containsID(settings.MutedBoards, board.ID) {
+			mark = "🔕"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(mark+" Доска «"+board.Name+"»", utils.NotifyCallback("board", board.ID)),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func validClock(value string) bool {
+	_, err := time.Parse("15:04", value)
+	return err == nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toggleID adds id to list or removes it
+func toggleID(ids []uint, id uint) []uint {
+	res := make([]uint, 0, len(ids)+1)
+	for _, v := range ids {
+		if v != id {
+			res = append(res, v)
+		}
+	}
+
+	if len(res) == len(ids) {
+		res = append(res, id)
+	}
+
+	return res
+}
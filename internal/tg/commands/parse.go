@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type addArgs struct {
+	title    string
+	board    string
+	priority int
+	dueAt    *time.Time
+}
+
+var priorities = map[string]int{
+	"1":      1,
+	"2":      2,
+	"3":      3,
+	"low":    1,
+	"medium": 2,
+	"med":    2,
+	"high":   3,
+}
+
+var priorityNames = []string{"", "низкий", "средний", "высокий"}
+
+// parseAdd parses "<title> [#board] [!priority] [due]", markers may stand anywhere
+func parseAdd(args string) (*addArgs, error) {
+	words := strings.Fields(args)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("укажите название задачи")
+	}
+
+	res := &addArgs{}
+	var title []string
+
+	for i, word := range words {
+		switch {
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			res.board = word[1:]
+		case strings.HasPrefix(word, "!") && len(word) > 1:
+			priority, ok := priorities[strings.ToLower(word[1:])]
+			if !ok {
+				return nil, fmt.Errorf("неизвестный приоритет %q, используйте !1..!3 или !low, !medium, !high", word)
+			}
+			res.priority = priority
+		default:
+			// due date is accepted only as the last word, so titles with dates stay intact
+			if i == len(words)-1 && len(title) > 0 {
+				if due, ok := parseDue(word, time.Now()); ok {
+					res.dueAt = &due
+					continue
+				}
+			}
+			title = append(title, word)
+		}
+	}
+
+	if len(title) == 0 {
+		return nil, fmt.Errorf("укажите название задачи")
+	}
+	res.title = strings.Join(title, " ")
+
+	return res, nil
+}
+
+// parseDue accepts dates like 2024-12-31, 2024-12-31T18:00, 31.12.2024, 31.12, today, tomorrow.
+// Date without time means the end of the day
+func parseDue(word string, now time.Time) (time.Time, bool) {
+	endOfDay := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 0, 0, now.Location())
+	}
+
+	switch strings.ToLower(word) {
+	case "today", "сегодня":
+		return endOfDay(now), true
+	case "tomorrow", "завтра":
+		return endOfDay(now.AddDate(0, 0, 1)), true
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02T15:04", word, now.Location()); err == nil {
+		return t, true
+	}
+
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if t, err := time.ParseInLocation(layout, word, now.Location()); err == nil {
+			return endOfDay(t), true
+		}
+	}
+
+	if t, err := time.ParseInLocation("02.01", word, now.Location()); err == nil {
+		due := endOfDay(time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location()))
+		// date that already passed this year means the next year
+		if due.Before(now) {
+			due = due.AddDate(1, 0, 0)
+		}
+		return due, true
+	}
+
+	return time.Time{}, false
+}
+
+// parseID parses task id from command argument
+func parseID(arg string) (uint, error) {
+	arg = strings.TrimPrefix(arg, "#")
+
+	id, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("некорректный id задачи %q", arg)
+	}
+
+	return uint(id), nil
+}
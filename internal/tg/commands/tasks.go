@@ -0,0 +1,404 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/tg/api"
+	"todo/internal/tg/dto"
+)
+
+const statusDone = 2
+
+func (c *Commands) add(chatID int64, tgUserID int64, args string, group bool) {
+	if args == "" {
+		c.reply(chatID, "Использование: /add <название> [#доска] [!приоритет] [срок]\nНапример: /add Купить молоко #дом !high tomorrow")
+		return
+	}
+
+	parsed, err := parseAdd(args)
+	if err != nil {
+		c.reply(chatID, "Ошибка: "+err.Error())
+		return
+	}
+
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	if len(boards) == 0 {
+		c.reply(chatID, "У вас нет досок. Создайте доску в приложении.")
+		return
+	}
+
+	// without #board task goes to the board of the chat or to the first board of user
+	board := boards[0]
+	if chatBoard, ok := findChatBoard(boards, chatID); ok {
+		board = chatBoard
+	} else if group && parsed.board == "" {
+		c.reply(chatID, "К этому чату не привязана ваша доска. Укажите #доску или привяжите доску командой /linkboard")
+		return
+	}
+
+	if parsed.board != "" {
+		found, ok := findBoard(boards, parsed.board)
+		if !ok {
+			c.reply(chatID, fmt.Sprintf("Доска %q не найдена. Список досок: /boards", parsed.board))
+			return
+		}
+		board = found
+	}
+
+	task, err := c.api.CreateTask(tgUserID, dto.PostTask{
+		Title:    parsed.title,
+		BoardId:  strconv.FormatUint(uint64(board.ID), 10),
+		Priority: parsed.priority,
+		DueAt:    parsed.dueAt,
+	})
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Задача #%d добавлена на доску «%s»", task.ID, board.Name))
+}
+
+func (c *Commands) done(chatID int64, tgUserID int64, args string) {
+	if args == "" {
+		c.reply(chatID, "Использование: /done <id>")
+		return
+	}
+
+	id, err := parseID(args)
+	if err != nil {
+		c.reply(chatID, "Ошибка: "+err.Error())
+		return
+	}
+
+	task, err := c.api.SetTaskStatus(tgUserID, id, statusDone)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Задача #%d «%s» выполнена", task.ID, task.Title))
+}
+
+func (c *Commands) move(chatID int64, tgUserID int64, args string) {
+	words := strings.Fields(args)
+	if len(words) < 2 {
+		c.reply(chatID, "Использование: /move <id> <статус>")
+		return
+	}
+
+	id, err := parseID(words[0])
+	if err != nil {
+		c.reply(chatID, "Ошибка: "+err.Error())
+		return
+	}
+
+	statuses, err := c.api.GetStatuses(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	name := strings.Join(words[1:], " ")
+	status, ok := findStatus(statuses, name)
+	if !ok {
+		c.reply(chatID, fmt.Sprintf("Статус %q не найден. Доступные статусы: %s", name, statusNames(statuses)))
+		return
+	}
+
+	task, err := c.api.SetTaskStatus(tgUserID, id, status.ID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Задача #%d «%s» перемещена в «%s»", task.ID, task.Title, status.Type))
+}
+
+func (c *Commands) boards(chatID int64, tgUserID int64) {
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	if len(boards) == 0 {
+		c.reply(chatID, "У вас нет досок.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Ваши доски:\n\n")
+	for _, board := range boards {
+		fmt.Fprintf(&sb, "• %s\n", board.Name)
+	}
+	sb.WriteString("\nЗадачи доски: /board <название>")
+
+	c.reply(chatID, sb.String())
+}
+
+func (c *Commands) board(chatID int64, tgUserID int64, args string) {
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	// without name shows board of the chat
+	var board dto.Board
+	var ok bool
+	if args == "" {
+		board, ok = findChatBoard(boards, chatID)
+		if !ok {
+			c.reply(chatID, "Использование: /board <название>")
+			return
+		}
+	} else {
+		board, ok = findBoard(boards, strings.TrimPrefix(args, "#"))
+	}
+	if !ok {
+		c.reply(chatID, fmt.Sprintf("Доска %q не найдена. Список досок: /boards", args))
+		return
+	}
+
+	tasks, err := c.api.GetTasks(tgUserID, board.ID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	if len(tasks) == 0 {
+		c.reply(chatID, fmt.Sprintf("На доске «%s» нет задач", board.Name))
+		return
+	}
+
+	statuses, err := c.api.GetStatuses(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Доска «%s»:\n\n", board.Name)
+	for _, task := range tasks {
+		sb.WriteString(formatTask(task, statuses))
+		sb.WriteString("\n")
+	}
+
+	c.reply(chatID, sb.String())
+}
+
+func (c *Commands) delete(chatID int64, tgUserID int64, args string) {
+	if args == "" {
+		c.reply(chatID, "Использование: /delete <id>")
+		return
+	}
+
+	id, err := parseID(args)
+	if err != nil {
+		c.reply(chatID, "Ошибка: "+err.Error())
+		return
+	}
+
+	// check task exists and is accessible before asking for confirmation
+	task, err := c.api.GetTask(tgUserID, id)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.mu.Lock()
+	c.pending[chatID] = pendingDelete{
+		taskID:    task.ID,
+		tgUserID:  tgUserID,
+		expiresAt: time.Now().Add(confirmTTL),
+	}
+	c.mu.Unlock()
+
+	c.reply(chatID, fmt.Sprintf("Удалить задачу #%d «%s»?\nОтправьте /confirm для подтверждения или /cancel для отмены.", task.ID, task.Title))
+}
+
+func (c *Commands) confirm(chatID int64, tgUserID int64) {
+	c.mu.Lock()
+	pending, ok := c.pending[chatID]
+	delete(c.pending, chatID)
+	c.mu.Unlock()
+
+	if !ok || pending.tgUserID != tgUserID || time.Now().After(pending.expiresAt) {
+		c.reply(chatID, "Нет действий для подтверждения.")
+		return
+	}
+
+	if err := c.api.DeleteTask(tgUserID, pending.taskID); err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Задача #%d удалена", pending.taskID))
+}
+
+func (c *Commands) cancel(chatID int64) {
+	c.mu.Lock()
+	delete(c.pending, chatID)
+	c.mu.Unlock()
+
+	c.reply(chatID, "Отменено.")
+}
+
+func (c *Commands) linkBoard(chatID int64, tgUserID int64, args string, group bool) {
+	if !group {
+		c.reply(chatID, "Команда работает только в групповом чате.")
+		return
+	}
+
+	if args == "" {
+		c.reply(chatID, "Использование: /linkboard <название доски>")
+		return
+	}
+
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	board, ok := findBoard(boards, strings.TrimPrefix(args, "#"))
+	if !ok {
+		c.reply(chatID, fmt.Sprintf("Доска %q не найдена. Список досок: /boards", args))
+		return
+	}
+
+	if err := c.api.LinkBoardChat(tgUserID, board.ID, chatID); err != nil {
+		c.replyBoardError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Доска «%s» привязана к чату. Сюда будут приходить новые задачи, смены статусов и комментарии.", board.Name))
+}
+
+func (c *Commands) unlinkBoard(chatID int64, tgUserID int64) {
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	board, ok := findChatBoard(boards, chatID)
+	if !ok {
+		c.reply(chatID, "К этому чату не привязана ваша доска.")
+		return
+	}
+
+	if err := c.api.UnlinkBoardChat(tgUserID, board.ID); err != nil {
+		c.replyBoardError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Доска «%s» отвязана от чата", board.Name))
+}
+
+// replyBoardError explains that chat of board is managed by owner
+func (c *Commands) replyBoardError(chatID int64, err error) {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusForbidden {
+		c.reply(chatID, "Привязывать чат к доске может только владелец доски.")
+		return
+	}
+
+	c.replyError(chatID, err)
+}
+
+func (c *Commands) comment(chatID int64, tgUserID int64, args string) {
+	words := strings.SplitN(args, " ", 2)
+	if len(words) < 2 || strings.TrimSpace(words[1]) == "" {
+		c.reply(chatID, "Использование: /comment <id> <текст>")
+		return
+	}
+
+	id, err := parseID(words[0])
+	if err != nil {
+		c.reply(chatID, "Ошибка: "+err.Error())
+		return
+	}
+
+	if err := c.api.AddComment(tgUserID, id, strings.TrimSpace(words[1])); err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Комментарий к задаче #%d добавлен", id))
+}
+
+// findChatBoard finds board linked to chat
+func findChatBoard(boards []dto.Board, chatID int64) (dto.Board, bool) {
+	for _, board := range boards {
+		if board.ChatId != nil && *board.ChatId == chatID {
+			return board, true
+		}
+	}
+
+	return dto.Board{}, false
+}
+
+func findBoard(boards []dto.Board, name string) (dto.Board, bool) {
+	for _, board := range boards {
+		if strings.EqualFold(board.Name, name) {
+			return board, true
+		}
+	}
+
+	return dto.Board{}, false
+}
+
+func findStatus(statuses []dto.Status, name string) (dto.Status, bool) {
+	for _, status := range statuses {
+		if strings.EqualFold(status.Type, name) || strconv.FormatUint(uint64(status.ID), 10) == name {
+			return status, true
+		}
+	}
+
+	return dto.Status{}, false
+}
+
+func statusNames(statuses []dto.Status) string {
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.Type)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func formatTask(task dto.Task, statuses []dto.Status) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#%d %s", task.ID, task.Title)
+
+	for _, status := range statuses {
+		if status.ID == task.StatusId {
+			fmt.Fprintf(&sb, " [%s]", status.Type)
+		}
+	}
+
+	if task.Priority > 0 && task.Priority < len(priorityNames) {
+		fmt.Fprintf(&sb, ", приоритет: %s", priorityNames[task.Priority])
+	}
+
+	if task.DueAt != nil {
+		fmt.Fprintf(&sb, ", срок: %s", task.DueAt.Local().Format("02.01.2006 15:04"))
+	}
+
+	if task.TrackedSeconds >= 60 {
+		fmt.Fprintf(&sb, ", учтено: %s", formatDuration(task.TrackedSeconds))
+	}
+
+	return sb.String()
+}
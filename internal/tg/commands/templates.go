@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"todo/internal/tg/api"
+	"todo/internal/tg/dto"
+)
+
+// newFromTemplate creates task by template of board, templates are added in the app
+func (c *Commands) newFromTemplate(chatID int64, tgUserID int64, args string) {
+	var name []string
+	var boardName string
+	for _, word := range strings.Fields(args) {
+		if strings.HasPrefix(word, "#") && len(word) > 1 {
+			boardName = word[1:]
+			continue
+		}
+		name = append(name, word)
+	}
+
+	if len(name) == 0 {
+		c.reply(chatID, "Использование: /new <шаблон> [#доска]\nНапример: /new Баг #работа")
+		return
+	}
+
+	// without #board template is searched on the board of the chat, then on all boards of user
+	body := dto.PostTaskFromTemplate{Name: strings.Join(name, " ")}
+	boards, err := c.api.GetBoards(tgUserID)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	if chatBoard, ok := findChatBoard(boards, chatID); ok {
+		body.BoardId = chatBoard.ID
+	}
+
+	if boardName != "" {
+		found, ok := findBoard(boards, boardName)
+		if !ok {
+			c.reply(chatID, fmt.Sprintf("Доска %q не найдена. Список досок: /boards", boardName))
+			return
+		}
+		body.BoardId = found.ID
+	}
+
+	task, err := c.api.CreateTaskFromTemplate(tgUserID, body)
+	if err != nil {
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+			c.reply(chatID, fmt.Sprintf("Шаблон %q не найден.", body.Name))
+			return
+		}
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Задача #%d «%s» создана по шаблону", task.ID, task.Title))
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"todo/internal/tg/api"
+)
+
+func (c *Commands) startTimer(chatID int64, tgUserID int64, args string) {
+	if args == "" {
+		c.reply(chatID, "Использование: /start_timer <id> [заметка]")
+		return
+	}
+
+	words := strings.SplitN(args, " ", 2)
+
+	id, err := parseID(words[0])
+	if err != nil {
+		c.reply(chatID, "Ошибка: "+err.Error())
+		return
+	}
+
+	note := ""
+	if len(words) > 1 {
+		note = strings.TrimSpace(words[1])
+	}
+
+	started, err := c.api.StartTimer(tgUserID, id, note)
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	text := fmt.Sprintf("Таймер задачи #%d запущен", started.Timer.TaskId)
+	if started.Stopped != nil {
+		text += fmt.Sprintf("\nТаймер задачи #%d остановлен: %s", started.Stopped.TaskId, formatDuration(started.Stopped.Seconds))
+	}
+
+	c.reply(chatID, text)
+}
+
+func (c *Commands) stopTimer(chatID int64, tgUserID int64) {
+	stopped, err := c.api.StopTimer(tgUserID)
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		c.reply(chatID, "Нет запущенного таймера. Запустить: /start_timer <id>")
+		return
+	}
+	if err != nil {
+		c.replyError(chatID, err)
+		return
+	}
+
+	c.reply(chatID, fmt.Sprintf("Таймер задачи #%d остановлен: %s", stopped.TaskId, formatDuration(stopped.Seconds)))
+}
+
+// formatDuration formats seconds as "1 ч 5 мин", shorter than a minute is "меньше минуты"
+func formatDuration(seconds int64) string {
+	minutes := seconds / 60
+	if minutes == 0 {
+		return "меньше минуты"
+	}
+
+	if minutes < 60 {
+		return fmt.Sprintf("%d мин", minutes)
+	}
+
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d ч", minutes/60)
+	}
+
+	return fmt.Sprintf("%d ч %d мин", minutes/60, minutes%60)
+}
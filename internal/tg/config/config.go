@@ -2,19 +2,33 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap/zapcore"
 )
 
 type Config struct {
-	TelegramToken string
-	ToDoAppURL    string
-	LogLevel      string
-	TgAddress     string
+	TelegramToken  string
+	ToDoAppURL     string
+	LogLevel       string
+	TgAddress      string
+	InternalSecret string
+
+	// polling or webhook
+	Mode          string
+	WebhookURL    string
+	WebhookPath   string
+	WebhookSecret string
 }
 
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
 func GetConfig() (*Config, error) {
 	_ = godotenv.Load()
 
@@ -44,5 +58,33 @@ func GetConfig() (*Config, error) {
 		cfg.LogLevel = zapcore.ErrorLevel.String()
 	}
 
+	// shared with todo service to sign internal requests
+	cfg.InternalSecret = os.Getenv("INTERNAL_SECRET")
+
+	if mode := os.Getenv("TG_MODE"); mode != "" {
+		cfg.Mode = mode
+	} else {
+		cfg.Mode = ModePolling
+	}
+
+	if mode := cfg.Mode; mode != ModePolling && mode != ModeWebhook {
+		return nil, fmt.Errorf("unknown TG_MODE %q, use polling or webhook", mode)
+	}
+
+	// public https url of bot, webhook path is appended to it
+	cfg.WebhookURL = strings.TrimSuffix(os.Getenv("TG_WEBHOOK_URL"), "/")
+
+	if webhookPath := os.Getenv("TG_WEBHOOK_PATH"); webhookPath != "" {
+		cfg.WebhookPath = webhookPath
+	} else {
+		cfg.WebhookPath = "/telegram/webhook"
+	}
+
+	cfg.WebhookSecret = os.Getenv("TG_WEBHOOK_SECRET")
+
+	if cfg.Mode == ModeWebhook && (cfg.WebhookURL == "" || cfg.WebhookSecret == "") {
+		return nil, fmt.Errorf("TG_WEBHOOK_URL and TG_WEBHOOK_SECRET are required in webhook mode")
+	}
+
 	return cfg, nil
 }
@@ -1,6 +1,21 @@
 package dto
 
 type ChatID struct {
-	Username string `json:"tg_name"`
+	TgUserID int64 `json:"tg_user_id"`
+	ChatID   int64 `json:"chat_id"`
+}
+
+type LinkTelegram struct {
+	Code     string `json:"code"`
+	TgUserID int64  `json:"tg_user_id"`
 	ChatID   int64  `json:"chat_id"`
+	TgName   string `json:"tg_name"`
+}
+
+type TgActivate struct {
+	ChatID int64 `json:"chat_id"`
+}
+
+type TgActivated struct {
+	Reactivated bool `json:"reactivated"`
 }
@@ -1,8 +1,23 @@
 package dto
 
 type TaskDtoChatID struct {
+	TaskId      uint   `json:"task_id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	StatusId    uint   `json:"status_id"`
+	Status      string `json:"status"`
 	ChatId      int64  `json:"chat_id"`
 }
+
+type CommentDtoChatID struct {
+	TaskId uint   `json:"task_id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Text   string `json:"text"`
+	ChatId int64  `json:"chat_id"`
+}
+
+type NotifyDtoChatID struct {
+	Text   string `json:"text"`
+	ChatId int64  `json:"chat_id"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 type MessDto struct {
+	TaskId      uint   `json:"task_id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	StatusId    uint   `json:"status_id"`
@@ -0,0 +1,21 @@
+package dto
+
+type NotificationSettings struct {
+	Created       bool   `json:"created"`
+	Assigned      bool   `json:"assigned"`
+	Commented     bool   `json:"commented"`
+	Due           bool   `json:"due"`
+	StatusChanged bool   `json:"status_changed"`
+	Digest        bool   `json:"digest"`
+	QuietFrom     string `json:"quiet_from"`
+	QuietTo       string `json:"quiet_to"`
+	Timezone      string `json:"timezone"`
+	MutedBoards   []uint `json:"muted_boards"`
+
+	// are not edited by bot, but sent back unchanged
+	Channels   map[string][]string `json:"channels"`
+	Email      string              `json:"email"`
+	WebhookURL string              `json:"webhook_url"`
+
+	DigestAnalytics bool `json:"digest_analytics"`
+}
@@ -0,0 +1,83 @@
+package dto
+
+import "time"
+
+// Task, Board and Status are responses of todo api
+
+type Task struct {
+	ID          uint
+	Title       string
+	Description string
+	BoardId     uint
+	StatusId    uint
+	UserId      uint
+	Priority    int
+	DueAt       *time.Time
+
+	TrackedSeconds int64
+}
+
+type Board struct {
+	ID      uint
+	Name    string
+	OwnerId uint
+	ChatId  *int64
+}
+
+type Status struct {
+	ID   uint
+	Type string
+}
+
+type PostTask struct {
+	Title    string     `json:"title"`
+	BoardId  string     `json:"board_id"`
+	Priority int        `json:"priority"`
+	DueAt    *time.Time `json:"due_at"`
+}
+
+type PutTaskStatus struct {
+	StatusId uint `json:"status_id"`
+}
+
+type PostSnooze struct {
+	Minutes int `json:"minutes"`
+}
+
+type PutBoardChat struct {
+	ChatId int64 `json:"chat_id"`
+}
+
+type PostComment struct {
+	Text string `json:"text"`
+}
+
+type PostTimer struct {
+	Note string `json:"note"`
+}
+
+type TimeEntry struct {
+	ID        uint       `json:"id"`
+	TaskId    uint       `json:"task_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Note      string     `json:"note"`
+	Seconds   int64      `json:"seconds"`
+}
+
+type TimerStarted struct {
+	Timer   TimeEntry  `json:"timer"`
+	Stopped *TimeEntry `json:"stopped"`
+}
+
+type PostTaskFromTemplate struct {
+	Name    string `json:"name"`
+	BoardId uint   `json:"board_id"`
+}
+
+type Attachment struct {
+	ID       uint   `json:"id"`
+	TaskId   uint   `json:"task_id"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
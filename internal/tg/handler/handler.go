@@ -2,9 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 	"todo/internal/tg/dto"
+	"todo/internal/tg/sender"
 	"todo/internal/tg/utils"
 
 	"go.uber.org/zap"
@@ -13,37 +18,100 @@ import (
 type TgHandler struct {
 	service TgHandlerer
 	logger  *zap.Logger
+	keys    *utils.KeyCache
 }
 
 type TgHandlerer interface {
-	CreateTask(message string, chatID int64) error
-	Scheduler(message string, chatID int64) error
+	CreateTask(message string, chatID int64, taskID uint) error
+	RemindTask(message string, chatID int64, taskID uint) error
+	TaskStatusChanged(message string, chatID int64, taskID uint) error
+	CommentAdded(message string, chatID int64) error
+	AssignedTask(message string, chatID int64, taskID uint) error
+	DueTask(message string, chatID int64, taskID uint) error
+	Notify(message string, chatID int64) error
+	Scheduler(pages []utils.DigestPage, status uint, chatID int64) error
 }
 
 func New(t TgHandlerer, logger *zap.Logger) TgHandler {
 	return TgHandler{
 		service: t,
 		logger:  logger,
+		keys:    utils.NewKeyCache(24 * time.Hour),
 	}
 }
 
 // Handler для создания задачи
 func (t *TgHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-	var task dto.TaskDtoChatID
-	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+	t.handleTask(w, r, t.service.CreateTask)
+}
+
+// Handler для напоминания об отложенной задаче
+func (t *TgHandler) RemindTask(w http.ResponseWriter, r *http.Request) {
+	t.handleTask(w, r, t.service.RemindTask)
+}
+
+// Handler для смены статуса задачи в чате доски
+func (t *TgHandler) TaskStatusChanged(w http.ResponseWriter, r *http.Request) {
+	t.handleTask(w, r, t.service.TaskStatusChanged)
+}
+
+// Handler для назначения задачи
+func (t *TgHandler) AssignedTask(w http.ResponseWriter, r *http.Request) {
+	t.handleTask(w, r, t.service.AssignedTask)
+}
+
+// Handler для напоминания о сроке задачи
+func (t *TgHandler) DueTask(w http.ResponseWriter, r *http.Request) {
+	t.handleTask(w, r, t.service.DueTask)
+}
+
+// Handler для простого текстового уведомления
+func (t *TgHandler) Notify(w http.ResponseWriter, r *http.Request) {
+	var notify dto.NotifyDtoChatID
+	if err := json.NewDecoder(r.Body).Decode(&notify); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	message := fmt.Sprintf("%s\nОписание: %s\nСтатус: в процессе", task.Title, task.Description)
+	t.deliverParts(w, r.Header.Get("Idempotency-Key"), notify.Text, func(part string) error {
+		return t.service.Notify(part, notify.ChatId)
+	})
+}
 
-	err := t.service.CreateTask(message, task.ChatId)
-	if err != nil {
-		http.Error(w, "No tg user", http.StatusUnauthorized)
+// Handler для комментария к задаче
+func (t *TgHandler) CommentAdded(w http.ResponseWriter, r *http.Request) {
+	var comment dto.CommentDtoChatID
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	message := fmt.Sprintf("💬 %s к задаче #%d %s:\n\n%s", comment.Author, comment.TaskId, comment.Title, comment.Text)
+
+	t.deliverParts(w, r.Header.Get("Idempotency-Key"), message, func(part string) error {
+		return t.service.CommentAdded(part, comment.ChatId)
+	})
+}
+
+func (t *TgHandler) handleTask(w http.ResponseWriter, r *http.Request, send func(message string, chatID int64, taskID uint) error) {
+	var task dto.TaskDtoChatID
+	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	status := task.Status
+	if status == "" {
+		status = "в процессе"
+	}
+
+	// description is markdown, the rest is escaped for HTML, full description is shown by «Подробнее»
+	description, _ := utils.Description(task.Description, utils.DescriptionPreviewLength)
+	message := fmt.Sprintf("#%d <b>%s</b>\nОписание: %s\nСтатус: %s", task.TaskId, utils.EscapeHTML(task.Title), description, utils.EscapeHTML(status))
+
+	t.deliver(w, r.Header.Get("Idempotency-Key"), func() error {
+		return send(message, task.ChatId, task.TaskId)
+	})
 }
 
 // Handler для обработки расписания
@@ -54,17 +122,89 @@ func (t *TgHandler) Scheduler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chatID, message := utils.FormatTasksMessage(mess)
+	chatID, pages := utils.FormatTasksMessage(mess)
 	if chatID == nil {
 		http.Error(w, "chatID is invalid", http.StatusBadRequest)
 		return
 	}
 
-	err := t.service.Scheduler(message, *chatID)
-	if err != nil {
-		http.Error(w, "No tg user", http.StatusUnauthorized)
+	// all tasks of digest have the same status
+	t.deliver(w, r.Header.Get("Idempotency-Key"), func() error {
+		return t.service.Scheduler(pages, mess[0].StatusId, *chatID)
+	})
+}
+
+// deliver sends message once per idempotency key. todo service retries deliveries,
+// so key is reserved before sending: retry which comes meanwhile gets 409 and is repeated later
+func (t *TgHandler) deliver(w http.ResponseWriter, key string, send func() error) {
+	if key != "" {
+		switch t.keys.Reserve(key) {
+		case utils.KeyDelivered:
+			w.WriteHeader(http.StatusCreated)
+			return
+		case utils.KeyPending:
+			http.Error(w, "Delivery is in progress", http.StatusConflict)
+			return
+		}
+	}
+
+	if err := send(); err != nil {
+		if key != "" {
+			t.keys.Release(key)
+		}
+		writeSendError(w, err)
 		return
 	}
 
+	if key != "" {
+		t.keys.Store(key)
+	}
+
 	w.WriteHeader(http.StatusCreated)
 }
+
+// deliverParts sends long text in several messages. Every part is remembered by key:partN,
+// so retry after failed part doesn't send again parts delivered before
+func (t *TgHandler) deliverParts(w http.ResponseWriter, key string, text string, send func(part string) error) {
+	t.deliver(w, key, func() error {
+		for i, part := range utils.SplitMessage(text) {
+			if key == "" {
+				if err := send(part); err != nil {
+					return err
+				}
+				continue
+			}
+
+			partKey := fmt.Sprintf("%s:part%d", key, i+1)
+			if t.keys.Reserve(partKey) == utils.KeyDelivered {
+				continue
+			}
+
+			if err := send(part); err != nil {
+				t.keys.Release(partKey)
+				return err
+			}
+			t.keys.Store(partKey)
+		}
+
+		return nil
+	})
+}
+
+// writeSendError tells todo service when to retry delivery: 410 when chat doesn't accept messages,
+// 429 with Retry-After when telegram limits messages, 502 for other errors
+func writeSendError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sender.ErrBlocked) {
+		http.Error(w, "Chat is blocked or deleted", http.StatusGone)
+		return
+	}
+
+	var retry *sender.RetryError
+	if errors.As(err, &retry) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.After.Seconds()))))
+		http.Error(w, "Telegram rate limit", http.StatusTooManyRequests)
+		return
+	}
+
+	http.Error(w, "Telegram delivery failed: "+err.Error(), http.StatusBadGateway)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo/internal/tg/sender"
+	"todo/internal/tg/utils"
+
+	"go.uber.org/zap"
+)
+
+// fakeService records sent messages, the message number fail is refused by rate limit once
+type fakeService struct {
+	TgHandlerer
+
+	sent []string
+	fail int
+	n    int
+}
+
+func (f *fakeService) Notify(message string, chatID int64) error {
+	f.n++
+	if f.n == f.fail {
+		return &sender.RetryError{After: 2 * time.Second, Err: errors.New("Too Many Requests")}
+	}
+
+	f.sent = append(f.sent, message)
+	return nil
+}
+
+// outbox of todo service retries delivery after failed part, delivered parts are not sent again
+func TestNotifyRetryByParts(t *testing.T) {
+	first := strings.Repeat("а", utils.MessageLimit-10)
+	second := strings.Repeat("б", 100)
+	body := `{"chat_id": 5, "text": "` + first + `\n\n` + second + `"}`
+
+	service := &fakeService{fail: 2}
+	h := New(service, zap.NewNop())
+
+	notify := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", "outbox:1:telegram")
+		rec := httptest.NewRecorder()
+		h.Notify(rec, req)
+		return rec
+	}
+
+	if rec := notify(); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("failed part: status %d, headers %v", rec.Code, rec.Header())
+	}
+	if rec := notify(); rec.Code != http.StatusCreated {
+		t.Fatalf("retry: status %d", rec.Code)
+	}
+	if rec := notify(); rec.Code != http.StatusCreated {
+		t.Fatalf("repeated delivery: status %d", rec.Code)
+	}
+
+	if len(service.sent) != 2 || service.sent[0] != first || service.sent[1] != second {
+		t.Fatalf("sent %d messages", len(service.sent))
+	}
+}
@@ -0,0 +1,152 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+// limits of telegram: about 30 messages per second for bot,
+// one message per second to private chat and 20 messages per minute to group
+const (
+	globalInterval  = time.Second / 30
+	privateInterval = time.Second
+	groupInterval   = 3 * time.Second
+)
+
+// short retry_after is waited here, longer one is returned to caller
+const (
+	maxRetryWait = 30 * time.Second
+	maxAttempts  = 3
+)
+
+// ErrBlocked means bot can't write to chat: user blocked bot or deleted chat, bot was removed from group
+var ErrBlocked = errors.New("chat is not available")
+
+// RetryError means telegram asked to wait before sending to the chat again
+type RetryError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("retry after %s: %v", e.After, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Sender sends messages one by one within rate limits of telegram
+type Sender struct {
+	bot    *tgbotapi.BotAPI
+	logger *zap.Logger
+
+	mu    sync.Mutex
+	next  time.Time           // next free slot of bot
+	chats map[int64]time.Time // next free slot of chat
+}
+
+func New(bot *tgbotapi.BotAPI, logger *zap.Logger) *Sender {
+	return &Sender{
+		bot:    bot,
+		logger: logger,
+		chats:  make(map[int64]time.Time),
+	}
+}
+
+// Send waits for free slot of chat and sends message, on 429 it waits retry_after and tries again
+func (s *Sender) Send(chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	for attempt := 1; ; attempt++ {
+		s.wait(chatID)
+
+		message, err := s.bot.Send(c)
+		if err == nil {
+			return message, nil
+		}
+
+		var tgErr tgbotapi.Error
+		if !errors.As(err, &tgErr) {
+			return message, err
+		}
+		if blocked(tgErr) {
+			return message, fmt.Errorf("%w: %v", ErrBlocked, err)
+		}
+		if tgErr.RetryAfter <= 0 {
+			return message, err
+		}
+
+		after := time.Duration(tgErr.RetryAfter) * time.Second
+		s.delay(chatID, after)
+		if after > maxRetryWait || attempt >= maxAttempts {
+			return message, &RetryError{After: after, Err: err}
+		}
+
+		s.logger.Warn("telegram rate limit", zap.Int64("chat_id", chatID), zap.Duration("retry_after", after))
+	}
+}
+
+// wait reserves the nearest slot free for bot and chat and sleeps till it
+func (s *Sender) wait(chatID int64) {
+	s.mu.Lock()
+
+	now := time.Now()
+	at := now
+	if s.next.After(at) {
+		at = s.next
+	}
+	if next := s.chats[chatID]; next.After(at) {
+		at = next
+	}
+
+	s.next = at.Add(globalInterval)
+	s.chats[chatID] = at.Add(chatInterval(chatID))
+	s.forget(now)
+
+	s.mu.Unlock()
+
+	time.Sleep(time.Until(at))
+}
+
+// delay moves next slot of chat after retry_after
+func (s *Sender) delay(chatID int64, after time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if at := time.Now().Add(after); at.After(s.chats[chatID]) {
+		s.chats[chatID] = at
+	}
+}
+
+// forget chats whose slots have passed, so map keeps only recent chats
+func (s *Sender) forget(now time.Time) {
+	if len(s.chats) < 1000 {
+		return
+	}
+
+	for chatID, next := range s.chats {
+		if next.Before(now) {
+			delete(s.chats, chatID)
+		}
+	}
+}
+
+// bot api gives no code in error, only description like
+// "Forbidden: bot was blocked by the user" or "Bad Request: chat not found"
+func blocked(err tgbotapi.Error) bool {
+	return strings.HasPrefix(err.Message, "Forbidden:") || strings.Contains(err.Message, "chat not found")
+}
+
+// ids of groups are negative
+func chatInterval(chatID int64) time.Duration {
+	if chatID < 0 {
+		return groupInterval
+	}
+
+	return privateInterval
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"fmt"
+	"todo/internal/tg/sender"
+	"todo/internal/tg/utils"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"go.uber.org/zap"
@@ -9,34 +11,101 @@ import (
 
 type TgService struct {
 	logger *zap.Logger
-	bot    *tgbotapi.BotAPI
+	sender *sender.Sender
 }
 
 type TgServiceer interface {
-	CreateTask(message string, chatID int64) error
-	Scheduler(message string, chatID int64) error
+	CreateTask(message string, chatID int64, taskID uint) error
+	RemindTask(message string, chatID int64, taskID uint) error
+	TaskStatusChanged(message string, chatID int64, taskID uint) error
+	CommentAdded(message string, chatID int64) error
+	AssignedTask(message string, chatID int64, taskID uint) error
+	DueTask(message string, chatID int64, taskID uint) error
+	Notify(message string, chatID int64) error
+	Scheduler(pages []utils.DigestPage, status uint, chatID int64) error
 }
 
 // Конструктор для TgService
-func New(logger *zap.Logger, bot *tgbotapi.BotAPI) *TgService {
+func New(logger *zap.Logger, sender *sender.Sender) *TgService {
 	return &TgService{
 		logger: logger,
-		bot:    bot,
+		sender: sender,
 	}
 }
 
 // Создание задачи и отправка сообщения в Telegram
-func (s *TgService) CreateTask(message string, chatID int64) error {
+func (s *TgService) CreateTask(message string, chatID int64, taskID uint) error {
+	return s.sendTask(fmt.Sprintf("Добавлена новая задача:\n\n%s", message), chatID, taskID)
+}
+
+// Напоминание об отложенной задаче
+func (s *TgService) RemindTask(message string, chatID int64, taskID uint) error {
+	return s.sendTask(fmt.Sprintf("Напоминание о задаче:\n\n%s", message), chatID, taskID)
+}
+
+// Смена статуса задачи в чате доски
+func (s *TgService) TaskStatusChanged(message string, chatID int64, taskID uint) error {
+	return s.sendTask(fmt.Sprintf("Статус задачи изменен:\n\n%s", message), chatID, taskID)
+}
+
+// Назначение задачи пользователю
+func (s *TgService) AssignedTask(message string, chatID int64, taskID uint) error {
+	return s.sendTask(fmt.Sprintf("Вам назначена задача:\n\n%s", message), chatID, taskID)
+}
+
+// Напоминание о приближении срока задачи
+func (s *TgService) DueTask(message string, chatID int64, taskID uint) error {
+	return s.sendTask(fmt.Sprintf("⏳ Подходит срок задачи:\n\n%s", message), chatID, taskID)
+}
+
+// Новый комментарий к задаче
+func (s *TgService) CommentAdded(message string, chatID int64) error {
+	return s.Notify(message, chatID)
+}
 
-	s.bot.Send(tgbotapi.NewMessage(chatID, fmt.Sprintf("Добавлена новая задача:\n\n%s", message)))
+// Отправка простого текстового уведомления, например сводки после тихих часов.
+// Текст должен помещаться в одно сообщение, длинный текст делит handler
+func (s *TgService) Notify(message string, chatID int64) error {
+	if _, err := s.sender.Send(chatID, tgbotapi.NewMessage(chatID, message)); err != nil {
+		s.logger.Error("send notification", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
 
 	return nil
 }
 
-// Отправка расписания в Telegram
-func (s *TgService) Scheduler(message string, chatID int64) error {
+// sendTask sends task card with action buttons, text is HTML
+func (s *TgService) sendTask(text string, chatID int64, taskID uint) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = utils.TaskKeyboard(taskID)
+
+	_, err := s.sender.Send(chatID, msg)
+	if err != nil {
+		s.logger.Error("send task message", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
 
-	s.bot.Send(tgbotapi.NewMessage(chatID, message))
+// Отправка расписания в Telegram, первая страница с кнопками листания.
+// Другие страницы бот собирает заново из todo api по нажатию кнопки
+func (s *TgService) Scheduler(pages []utils.DigestPage, status uint, chatID int64) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	msg := tgbotapi.NewMessage(chatID, pages[0].Text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if keyboard := utils.DigestKeyboard(status, 0, len(pages), pages[0].More); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+
+	if _, err := s.sender.Send(chatID, msg); err != nil {
+		s.logger.Error("send digest", zap.Int64("chat_id", chatID), zap.Error(err))
+		return err
+	}
 
 	return nil
 }
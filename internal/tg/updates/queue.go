@@ -0,0 +1,96 @@
+package updates
+
+import (
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+// updates of chat over this are dropped, user sending faster than bot answers won't wait for all replies
+const maxChatQueue = 100
+
+// ChatQueue handles updates of every chat in its own goroutine, so a chat which waits for
+// rate limit of telegram doesn't hold other chats. Updates of one chat are handled in order
+type ChatQueue struct {
+	d      Dispatcher
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	queues map[int64][]tgbotapi.Update // by chat id, chat is handled while it has a queue
+}
+
+func NewChatQueue(d Dispatcher, logger *zap.Logger) *ChatQueue {
+	return &ChatQueue{
+		d:      d,
+		logger: logger,
+		queues: make(map[int64][]tgbotapi.Update),
+	}
+}
+
+// Handle puts update to queue of its chat and returns without waiting for it
+func (q *ChatQueue) Handle(update tgbotapi.Update) {
+	chatID := updateChat(update)
+
+	q.mu.Lock()
+	queue, running := q.queues[chatID]
+	if len(queue) >= maxChatQueue {
+		q.mu.Unlock()
+		q.logger.Warn("chat queue is full, update is dropped", zap.Int64("chat_id", chatID), zap.Int("update_id", update.UpdateID))
+		return
+	}
+	q.queues[chatID] = append(queue, update)
+	q.mu.Unlock()
+
+	if !running {
+		go q.run(chatID)
+	}
+}
+
+// run handles updates of chat until its queue is empty.
+// Panic in handler drops the rest of queue, so the next update of chat starts it again
+func (q *ChatQueue) run(chatID int64) {
+	var update tgbotapi.Update
+	defer func() {
+		if r := recover(); r != nil {
+			q.mu.Lock()
+			dropped := len(q.queues[chatID])
+			delete(q.queues, chatID)
+			q.mu.Unlock()
+
+			q.logger.Error("panic in update handler", zap.Int64("chat_id", chatID), zap.Int("update_id", update.UpdateID),
+				zap.Int("dropped", dropped), zap.Any("panic", r), zap.Stack("stack"))
+		}
+	}()
+
+	for {
+		q.mu.Lock()
+		queue := q.queues[chatID]
+		if len(queue) == 0 {
+			delete(q.queues, chatID)
+			q.mu.Unlock()
+			return
+		}
+		update = queue[0]
+		q.queues[chatID] = queue[1:]
+		q.mu.Unlock()
+
+		q.d.Handle(update)
+	}
+}
+
+// updateChat gives chat where answer to update goes, updates without chat share queue 0
+func updateChat(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.Chat != nil:
+		return update.Message.Chat.ID
+	case update.EditedMessage != nil && update.EditedMessage.Chat != nil:
+		return update.EditedMessage.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.ChannelPost != nil && update.ChannelPost.Chat != nil:
+		return update.ChannelPost.Chat.ID
+	}
+
+	return 0
+}
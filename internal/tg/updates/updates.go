@@ -0,0 +1,76 @@
+package updates
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+// header with secret token which telegram sends with every webhook request
+const HeaderSecretToken = "X-Telegram-Bot-Api-Secret-Token"
+
+// Dispatcher handles one update, the same for polling and webhook mode,
+// so recorded updates can be fed to it directly
+type Dispatcher interface {
+	Handle(update tgbotapi.Update)
+}
+
+// Poll receives updates by long polling until channel is closed. Updates are handled one by one,
+// so d shouldn't block, see ChatQueue
+func Poll(bot *tgbotapi.BotAPI, d Dispatcher, logger *zap.Logger) error {
+	// getUpdates doesn't work while webhook is set
+	if _, err := bot.RemoveWebhook(); err != nil {
+		logger.Warn("remove webhook", zap.Error(err))
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+
+	updates, err := bot.GetUpdatesChan(u)
+	if err != nil {
+		return err
+	}
+
+	for update := range updates {
+		d.Handle(update)
+	}
+
+	return nil
+}
+
+// SetWebhook registers url of webhook with secret token in telegram
+func SetWebhook(bot *tgbotapi.BotAPI, webhookURL string, secret string) error {
+	params := url.Values{}
+	params.Set("url", webhookURL)
+	params.Set("secret_token", secret)
+
+	_, err := bot.MakeRequest("setWebhook", params)
+	return err
+}
+
+// Webhook returns handler for updates sent by telegram
+func Webhook(secret string, d Dispatcher, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(HeaderSecretToken)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			http.Error(w, "invalid secret token", http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			// telegram repeats update on error, broken one will never be decoded
+			logger.Error("decode update", zap.Error(err))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		d.Handle(update)
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,159 @@
+package updates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"go.uber.org/zap"
+)
+
+type recordDispatcher struct {
+	updates []tgbotapi.Update
+}
+
+func (d *recordDispatcher) Handle(update tgbotapi.Update) {
+	d.updates = append(d.updates, update)
+}
+
+func TestWebhook(t *testing.T) {
+	// update recorded from telegram, the same fixtures are used by commands
+	recorded, err := os.ReadFile("../commands/testdata/group_add_linked.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		token    string
+		body     string
+		want     int
+		dispatch bool
+	}{
+		{"recorded update", "s3cret", "s3cret", string(recorded), http.StatusOK, true},
+		{"wrong token", "s3cret", "other", string(recorded), http.StatusUnauthorized, false},
+		{"no secret configured", "", "", string(recorded), http.StatusUnauthorized, false},
+		{"broken update is dropped", "s3cret", "s3cret", "{", http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &recordDispatcher{}
+			req := httptest.NewRequest(http.MethodPost, "/tg/webhook", strings.NewReader(tt.body))
+			req.Header.Set(HeaderSecretToken, tt.token)
+			rec := httptest.NewRecorder()
+
+			Webhook(tt.secret, d, zap.NewNop())(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d", rec.Code, tt.want)
+			}
+			if got := len(d.updates) == 1; got != tt.dispatch {
+				t.Fatalf("dispatched %d updates", len(d.updates))
+			}
+			if tt.dispatch {
+				message := d.updates[0].Message
+				if message == nil || message.Chat.ID != -200 || message.Command() != "add" {
+					t.Fatalf("decoded update %+v", d.updates[0])
+				}
+			}
+		})
+	}
+}
+
+// blockDispatcher holds updates of chat 1 until release is closed
+type blockDispatcher struct {
+	release chan struct{}
+	handled chan tgbotapi.Update
+}
+
+func (d *blockDispatcher) Handle(update tgbotapi.Update) {
+	if update.Message.Chat.ID == 1 {
+		<-d.release
+	}
+	d.handled <- update
+}
+
+func chatUpdate(id int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}}}
+}
+
+func TestChatQueue(t *testing.T) {
+	d := &blockDispatcher{release: make(chan struct{}), handled: make(chan tgbotapi.Update, 10)}
+	q := NewChatQueue(d, zap.NewNop())
+
+	// chat 1 waits like sender waiting for rate limit, chat 2 is answered meanwhile
+	q.Handle(chatUpdate(1, 1))
+	q.Handle(chatUpdate(2, 1))
+	q.Handle(chatUpdate(3, 2))
+
+	select {
+	case update := <-d.handled:
+		if update.UpdateID != 3 {
+			t.Fatalf("handled update %d of blocked chat", update.UpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("update of another chat waits for blocked chat")
+	}
+
+	close(d.release)
+	for _, want := range []int{1, 2} {
+		select {
+		case update := <-d.handled:
+			if update.UpdateID != want {
+				t.Fatalf("handled update %d, want %d", update.UpdateID, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("update %d isn't handled", want)
+		}
+	}
+}
+
+// panicDispatcher panics on update 1
+type panicDispatcher struct {
+	handled chan tgbotapi.Update
+}
+
+func (d *panicDispatcher) Handle(update tgbotapi.Update) {
+	if update.UpdateID == 1 {
+		panic("handler failed")
+	}
+	d.handled <- update
+}
+
+// panic in handler doesn't stop the bot, the next update of chat is handled again
+func TestChatQueuePanic(t *testing.T) {
+	d := &panicDispatcher{handled: make(chan tgbotapi.Update, 10)}
+	q := NewChatQueue(d, zap.NewNop())
+
+	q.Handle(chatUpdate(1, 1))
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		q.mu.Lock()
+		_, running := q.queues[1]
+		q.mu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("queue of chat isn't cleared after panic")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	q.Handle(chatUpdate(2, 1))
+	select {
+	case update := <-d.handled:
+		if update.UpdateID != 2 {
+			t.Fatalf("handled update %d", update.UpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("chat isn't handled after panic")
+	}
+}
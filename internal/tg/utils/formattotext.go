@@ -3,31 +3,73 @@ package utils
 import (
 	"fmt"
 	"todo/internal/tg/dto"
+	"unicode/utf8"
 )
 
-func FormatTasksMessage(tasks []dto.MessDto) (*int64, string) {
+// tasks per page of digest
+const DigestPageSize = 5
+
+// DigestPage is HTML text of page and tasks with cut description
+type DigestPage struct {
+	Text string
+	More []DigestTask
+}
+
+// DigestTask is task by its number in digest
+type DigestTask struct {
+	Number int
+	TaskID uint
+}
+
+// FormatTasksMessage splits tasks list to pages of DigestPageSize tasks which fit in one message
+func FormatTasksMessage(tasks []dto.MessDto) (*int64, []DigestPage) {
 	if len(tasks) == 0 {
-		return nil, "У вас нет задач"
+		return nil, []DigestPage{{Text: "У вас нет задач"}}
 	}
 
 	chatID := tasks[0].ChatId
 
-	var message string
+	var header string
 
 	if tasks[0].StatusId == 1 {
-		message = "Ваши задачи:\n\n"
+		header = "Ваши задачи:\n\n"
 	} else {
-		message = "Ваши завершенные задачи:\n\n"
+		header = "Ваши завершенные задачи:\n\n"
 	}
 
+	var pages []DigestPage
+	page := DigestPage{Text: header}
+	count := 0
+
 	for i, task := range tasks {
-		if task.StatusId == 1 {
-			message += fmt.Sprintf("%d. %s\nОписание: %s\nСтатус: %s\n\n", i+1, task.Title, task.Description, "в процессе")
+		var status string
+		switch task.StatusId {
+		case 1:
+			status = "в процессе"
+		case 2:
+			status = "выполнено"
+		default:
+			continue
 		}
-		if task.StatusId == 2 {
-			message += fmt.Sprintf("%d. %s\nОписание: %s\nСтатус: %s\n\n", i+1, task.Title, task.Description, "выполнено")
+
+		description, truncated := Description(task.Description, DescriptionDigestLength)
+		entry := fmt.Sprintf("%d. <b>%s</b>\nОписание: %s\nСтатус: %s\n\n", i+1, EscapeHTML(task.Title), description, status)
+
+		// page is full by count of tasks or by length of message, tasks are never split
+		if count == DigestPageSize || count > 0 && utf8.RuneCountInString(page.Text+entry) > MessageLimit {
+			pages = append(pages, page)
+			page = DigestPage{Text: header}
+			count = 0
 		}
+
+		if truncated && task.TaskId != 0 {
+			page.More = append(page.More, DigestTask{Number: i + 1, TaskID: task.TaskId})
+		}
+		page.Text += entry
+		count++
 	}
 
-	return &chatID, message
+	pages = append(pages, page)
+
+	return &chatID, pages
 }
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// how long key stays reserved if request hangs and neither stores nor releases it
+const keyReserveTTL = 5 * time.Minute
+
+// KeyState is state of idempotency key of delivery
+type KeyState int
+
+const (
+	KeyNew       KeyState = iota // key is reserved by the caller
+	KeyPending                   // message with the key is being sent by another request
+	KeyDelivered                 // message with the key is already delivered
+)
+
+// KeyCache remembers idempotency keys of delivered messages for ttl
+type KeyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	keys    map[string]time.Time
+	pending map[string]time.Time
+}
+
+func NewKeyCache(ttl time.Duration) *KeyCache {
+	return &KeyCache{
+		ttl:     ttl,
+		keys:    make(map[string]time.Time),
+		pending: make(map[string]time.Time),
+	}
+}
+
+// Reserve checks and reserves key in one step, so retry which comes while message is sent
+// is not sent the second time. Reserved key must be stored or released
+func (c *KeyCache) Reserve(key string) KeyState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if expires, ok := c.keys[key]; ok && now.Before(expires) {
+		return KeyDelivered
+	}
+	if expires, ok := c.pending[key]; ok && now.Before(expires) {
+		return KeyPending
+	}
+
+	c.pending[key] = now.Add(keyReserveTTL)
+	return KeyNew
+}
+
+// Release drops reservation of key after failed delivery, so it can be retried
+func (c *KeyCache) Release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, key)
+}
+
+// Store saves key of delivered message and drops expired ones
+func (c *KeyCache) Store(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, expires := range c.keys {
+		if now.After(expires) {
+			delete(c.keys, k)
+		}
+	}
+	for k, expires := range c.pending {
+		if now.After(expires) {
+			delete(c.pending, k)
+		}
+	}
+
+	delete(c.pending, key)
+	c.keys[key] = now.Add(c.ttl)
+}
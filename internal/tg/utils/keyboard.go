@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// actions of task buttons
+const (
+	ActionDone     = "done"
+	ActionStart    = "start"
+	ActionSnooze1h = "snooze1h"
+	ActionSnooze1d = "snooze1d"
+	ActionInfo     = "info"
+	ActionMore     = "more"
+)
+
+// buttons with full descriptions in one row of digest
+const digestMoreRowSize = 3
+
+// callback data prefixes, telegram limits data by 64 bytes
+const (
+	CallbackTask   = "task"
+	CallbackDigest = "digest"
+	CallbackNotify = "notif"
+)
+
+// TaskCallback builds callback data like "task:done:42"
+func TaskCallback(action string, taskID uint) string {
+	return fmt.Sprintf("%s:%s:%d", CallbackTask, action, taskID)
+}
+
+// NotifyCallback builds callback data like "notif:due" or "notif:board:3" for notification settings
+func NotifyCallback(action string, id uint) string {
+	if id == 0 {
+		return fmt.Sprintf("%s:%s", CallbackNotify, action)
+	}
+
+	return fmt.Sprintf("%s:%s:%d", CallbackNotify, action, id)
+}
+
+// DigestCallback builds callback data like "digest:1:2", status of tasks is kept
+// to build the page again from todo api
+func DigestCallback(status uint, page int) string {
+	return fmt.Sprintf("%s:%d:%d", CallbackDigest, status, page)
+}
+
+// ParseCallback splits callback data to kind, action and numeric argument
+func ParseCallback(data string) (kind string, action string, arg uint, ok bool) {
+	parts := strings.Split(data, ":")
+
+	switch {
+	case len(parts) == 3 && parts[0] == CallbackTask:
+		id, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return "", "", 0, false
+		}
+		return parts[0], parts[1], uint(id), true
+	case len(parts) == 3 && parts[0] == CallbackNotify:
+		id, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return "", "", 0, false
+		}
+		return parts[0], parts[1], uint(id), true
+	case len(parts) == 2 && parts[0] == CallbackNotify:
+		return parts[0], parts[1], 0, true
+	case len(parts) == 3 && parts[0] == CallbackDigest:
+		page, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return "", "", 0, false
+		}
+		return parts[0], parts[1], uint(page), true
+	}
+
+	return "", "", 0, false
+}
+
+// TaskKeyboard is attached to every task notification
+func TaskKeyboard(taskID uint) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Готово", TaskCallback(ActionDone, taskID)),
+			tgbotapi.NewInlineKeyboardButtonData("▶️ В работу", TaskCallback(ActionStart, taskID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ 1 ч", TaskCallback(ActionSnooze1h, taskID)),
+			tgbotapi.NewInlineKeyboardButtonData("⏰ 1 д", TaskCallback(ActionSnooze1d, taskID)),
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ Подробнее", TaskCallback(ActionInfo, taskID)),
+		),
+	)
+}
+
+// DigestKeyboard shows buttons with full descriptions of tasks and prev/next buttons,
+// nil when there is only one page and nothing to show
+func DigestKeyboard(status uint, page int, pages int, more []DigestTask) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, task := range more {
+		if len(row) == digestMoreRowSize {
+			rows = append(rows, row)
+			row = nil
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📄 %d", task.Number), TaskCallback(ActionMore, task.TaskID)))
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if pages > 1 {
+		var nav []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", DigestCallback(status, page-1)))
+		}
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pages), DigestCallback(status, page)))
+		if page < pages-1 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", DigestCallback(status, page+1)))
+		}
+		rows = append(rows, nav)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
@@ -0,0 +1,437 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// lengths of description in runes of markdown source
+const (
+	DescriptionPreviewLength = 300  // in notifications about task
+	DescriptionDigestLength  = 100  // in digest
+	DescriptionMaxLength     = 3500 // full description, message is limited by 4096 characters
+)
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+var (
+	headingLine   = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	bulletLine    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedLine   = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	ruleLine      = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	codeLanguage  = regexp.MustCompile(`^[A-Za-z0-9_+#.-]+$`)
+	linkSchemes   = map[string]bool{"http": true, "https": true, "mailto": true, "tg": true}
+	taskListMarks = map[string]string{"[ ] ": "☐ ", "[x] ": "☑ ", "[X] ": "☑ "}
+)
+
+// EscapeHTML escapes plain text for HTML parse mode of telegram
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// Description renders markdown description, text longer than limit runes is cut and ends with "…"
+func Description(md string, limit int) (string, bool) {
+	md = strings.TrimSpace(strings.ReplaceAll(md, "\r\n", "\n"))
+
+	runes := []rune(md)
+	truncated := limit > 0 && len(runes) > limit
+	if truncated {
+		cut := limit
+		// cut on word boundary if it is not too far back
+		for i := limit; i > limit*4/5; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+		md = strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)
+	}
+
+	html := MarkdownHTML(md)
+	if truncated {
+		html += "…"
+	}
+
+	return html, truncated
+}
+
+// MarkdownHTML renders markdown to the HTML subset of telegram: bold, italic, strikethrough,
+// code, code blocks, links and quotes. Headings become bold lines, list items get bullets.
+// Every tag is closed, so cut markdown still gives valid HTML
+func MarkdownHTML(md string) string {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " \t")
+
+		// fenced code block, not closed block lasts to the end
+		if fence := codeFence(trimmed); fence != "" {
+			language := strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1]))
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimLeft(lines[i], " \t"), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+
+			content := EscapeHTML(strings.Join(code, "\n"))
+			if codeLanguage.MatchString(language) {
+				out = append(out, `<pre><code class="language-`+language+`">`+content+"</code></pre>")
+			} else {
+				out = append(out, "<pre>"+content+"</pre>")
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			var quote []string
+			for ; i < len(lines); i++ {
+				text := strings.TrimLeft(lines[i], " \t")
+				if !strings.HasPrefix(text, ">") {
+					break
+				}
+				text = strings.TrimPrefix(strings.TrimPrefix(text, ">"), " ")
+				quote = append(quote, blockLine(text))
+			}
+			i--
+
+			out = append(out, "<blockquote>"+strings.Join(quote, "\n")+"</blockquote>")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			// several empty lines are one paragraph break
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			continue
+		}
+
+		out = append(out, blockLine(line))
+	}
+
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+// codeFence gives opening fence of code block or empty string
+func codeFence(line string) string {
+	for _, fence := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, fence) {
+			return fence
+		}
+	}
+
+	return ""
+}
+
+// blockLine renders heading, rule, list item or line of paragraph
+func blockLine(line string) string {
+	if ruleLine.MatchString(line) {
+		return "———"
+	}
+
+	if match := headingLine.FindStringSubmatch(line); match != nil {
+		return "<b>" + inlineHTML(match[1]) + "</b>"
+	}
+
+	if match := bulletLine.FindStringSubmatch(line); match != nil {
+		text := match[2]
+		mark := "• "
+		for prefix, checkbox := range taskListMarks {
+			if strings.HasPrefix(text, prefix) {
+				mark, text = checkbox, strings.TrimPrefix(text, prefix)
+				break
+			}
+		}
+		return listIndent(match[1]) + mark + inlineHTML(text)
+	}
+
+	if match := orderedLine.FindStringSubmatch(line); match != nil {
+		return listIndent(match[1]) + match[2] + ". " + inlineHTML(match[3])
+	}
+
+	return inlineHTML(strings.TrimSpace(line))
+}
+
+// nested items are indented by two spaces for every level
+func listIndent(space string) string {
+	level := len(strings.ReplaceAll(space, "\t", "    ")) / 2
+	return strings.Repeat("  ", level)
+}
+
+func inlineHTML(s string) string {
+	var sb strings.Builder
+	renderInline(&sb, []rune(s), false)
+	return sb.String()
+}
+
+// renderInline writes text with emphasis, code spans and links, delimiters without pair stay as text.
+// Links can't be nested, so inside link other links are written as their text
+func renderInline(sb *strings.Builder, r []rune, inLink bool) {
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+
+		switch {
+		case c == '\\' && i+1 < len(r) && (unicode.IsPunct(r[i+1]) || unicode.IsSymbol(r[i+1])):
+			sb.WriteString(EscapeHTML(string(r[i+1])))
+			i++
+
+		case c == '`':
+			n := runLength(r, i, '`')
+			end := findRun(r, i+n, '`', n)
+			if end < 0 {
+				sb.WriteString(string(r[i : i+n]))
+				i += n - 1
+				continue
+			}
+
+			code := string(r[i+n : end])
+			if len(code) > 1 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") {
+				code = code[1 : len(code)-1]
+			}
+			sb.WriteString("<code>" + EscapeHTML(code) + "</code>")
+			i = end + n - 1
+
+		case c == '[':
+			text, link, end, ok := parseLink(r, i)
+			if !ok {
+				sb.WriteString("[")
+				continue
+			}
+
+			if !inLink && safeLink(link) {
+				sb.WriteString(`<a href="` + EscapeHTML(link) + `">`)
+				renderInline(sb, text, true)
+				sb.WriteString("</a>")
+			} else {
+				renderInline(sb, text, inLink)
+			}
+			i = end
+
+		case c == '<':
+			end := indexRune(r, i+1, '>')
+			if end > 0 {
+				link := string(r[i+1 : end])
+				if !strings.ContainsAny(link, " \t<") && safeLink(link) {
+					if inLink {
+						sb.WriteString(EscapeHTML(link))
+					} else {
+						sb.WriteString(`<a href="` + EscapeHTML(link) + `">` + EscapeHTML(link) + "</a>")
+					}
+					i = end
+					continue
+				}
+			}
+			sb.WriteString("&lt;")
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(r, i, c)
+			if end, size, tag := emphasis(r, i, n); end >= 0 {
+				sb.WriteString("<" + tag + ">")
+				renderInline(sb, r[i+size:end], inLink)
+				sb.WriteString("</" + tag + ">")
+				i = end + size - 1
+				continue
+			}
+			sb.WriteString(string(r[i : i+n]))
+			i += n - 1
+
+		default:
+			sb.WriteString(EscapeHTML(string(c)))
+		}
+	}
+}
+
+// emphasis finds closing delimiter for run of n delimiters at i, ** and __ are bold,
+// * and _ are italic, ~~ is strikethrough. Underscores inside words are not emphasis
+func emphasis(r []rune, i int, n int) (end int, size int, tag string) {
+	c := r[i]
+
+	var sizes []int
+	switch {
+	case c == '~' && n == 2:
+		sizes = []int{2}
+	case c != '~' && n >= 2:
+		sizes = []int{2, 1}
+	case c != '~':
+		sizes = []int{1}
+	}
+
+	for _, size := range sizes {
+		open := i + size
+		if open >= len(r) || unicode.IsSpace(r[open]) {
+			continue
+		}
+		if c == '_' && i > 0 && isWordRune(r[i-1]) {
+			continue
+		}
+
+		end := findCloser(r, open, c, size)
+		if end < 0 {
+			continue
+		}
+
+		switch {
+		case c == '~':
+			tag = "s"
+		case size == 2:
+			tag = "b"
+		default:
+			tag = "i"
+		}
+		return end, size, tag
+	}
+
+	return -1, 0, ""
+}
+
+// findCloser looks for delimiter run of size after non-space rune, code spans are skipped
+func findCloser(r []rune, from int, c rune, size int) int {
+	for j := from; j < len(r); j++ {
+		switch r[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			n := runLength(r, j, '`')
+			if end := findRun(r, j+n, '`', n); end >= 0 {
+				j = end + n - 1
+				continue
+			}
+			j += n - 1
+			continue
+		}
+
+		if r[j] != c {
+			continue
+		}
+
+		// closer is the end of run, so "***text***" is bold and italic
+		n := runLength(r, j, c)
+		end := j + n - size
+		if !closerRun(c, n, size) || end == from || unicode.IsSpace(r[j-1]) {
+			j += n - 1
+			continue
+		}
+
+		if c == '_' && end+size < len(r) && isWordRune(r[end+size]) {
+			j += n - 1
+			continue
+		}
+
+		return end
+	}
+
+	return -1
+}
+
+// closerRun checks run of n delimiters can close emphasis of size,
+// bold inside italic like "*a **b** c*" is not taken as closer of italic
+func closerRun(c rune, n int, size int) bool {
+	switch {
+	case c == '~':
+		return n == size
+	case size == 1:
+		return n == 1 || n >= 3
+	}
+
+	return n >= size
+}
+
+// parseLink parses [text](url "title") at i
+func parseLink(r []rune, i int) (text []rune, link string, end int, ok bool) {
+	depth := 0
+	close := -1
+	for j := i; j < len(r) && close < 0; j++ {
+		switch r[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				close = j
+			}
+		}
+	}
+
+	if close < 0 || close+1 >= len(r) || r[close+1] != '(' {
+		return nil, "", 0, false
+	}
+
+	depth = 0
+	for j := close + 1; j < len(r); j++ {
+		switch r[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				target := strings.TrimSpace(string(r[close+2 : j]))
+				if space := strings.IndexAny(target, " \t"); space >= 0 {
+					target = target[:space]
+				}
+				target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+				return r[i+1 : close], target, j, true
+			}
+		}
+	}
+
+	return nil, "", 0, false
+}
+
+// only links which can't run scripts are kept
+func safeLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	return linkSchemes[strings.ToLower(u.Scheme)]
+}
+
+func runLength(r []rune, i int, c rune) int {
+	n := 0
+	for i+n < len(r) && r[i+n] == c {
+		n++
+	}
+
+	return n
+}
+
+// findRun gives start of run of exactly n runes c
+func findRun(r []rune, from int, c rune, n int) int {
+	for j := from; j < len(r); j++ {
+		if r[j] != c {
+			continue
+		}
+
+		m := runLength(r, j, c)
+		if m == n {
+			return j
+		}
+		j += m - 1
+	}
+
+	return -1
+}
+
+func indexRune(r []rune, from int, c rune) int {
+	for j := from; j < len(r); j++ {
+		if r[j] == c {
+			return j
+		}
+	}
+
+	return -1
+}
+
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
@@ -0,0 +1,87 @@
+package utils
+
+import "testing"
+
+func TestMarkdownHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		// escaping
+		{"html", `a < b & c > d "q"`, "a &lt; b &amp; c &gt; d &quot;q&quot;"},
+		{"tag in code", "`<b>`", "<code>&lt;b&gt;</code>"},
+		{"backslash", `\*not\* \<b\>`, "*not* &lt;b&gt;"},
+		{"quote in link", `[x](http://e.com/"onmouseover="x)`, `<a href="http://e.com/&quot;onmouseover=&quot;x">x</a>`},
+		{"autolink", `<https://example.com?a=1&b="2">`,
+			`<a href="https://example.com?a=1&amp;b=&quot;2&quot;">https://example.com?a=1&amp;b=&quot;2&quot;</a>`},
+		{"code block", "```go\nx := <a>\n```", `<pre><code class="language-go">x := &lt;a&gt;</code></pre>`},
+
+		// emphasis and nesting
+		{"bold and italic", "**bold *italic* bold**", "<b>bold <i>italic</i> bold</b>"},
+		{"triple", "***both***", "<b><i>both</i></b>"},
+		{"underscores", "_it_ __b__", "<i>it</i> <b>b</b>"},
+		{"underscores in words", "snake_case_name", "snake_case_name"},
+		{"strike", "~~strike~~ ~single~", "<s>strike</s> ~single~"},
+		{"link in bold", "**a [b](http://x) c**", `<b>a <a href="http://x">b</a> c</b>`},
+		{"bold in link", "[**a** b](http://y)", `<a href="http://y"><b>a</b> b</a>`},
+		{"link in link", "[a [b](http://x) c](http://y)", `<a href="http://y">a b c</a>`},
+		{"autolink in link", "[a <http://x> c](http://y)", `<a href="http://y">a http://x c</a>`},
+		{"link in bold in link", "[**a [b](http://x)**](http://y)", `<a href="http://y"><b>a b</b></a>`},
+		{"code with backticks", "``code ` with``", "<code>code ` with</code>"},
+
+		// unclosed delimiters stay as text
+		{"unclosed bold", "**unclosed", "**unclosed"},
+		{"unclosed italic", "*unclosed", "*unclosed"},
+		{"unclosed code", "`unclosed", "`unclosed"},
+		{"unclosed link", "[text](http://x", "[text](http://x"},
+		{"link without url", "[text]", "[text]"},
+		{"unclosed code block", "```\nnot closed", "<pre>not closed</pre>"},
+
+		// unsafe schemes lose link, text is kept
+		{"javascript", "[b](javascript:alert(1))", "b"},
+		{"javascript in capitals", "[b](JavaScript:alert(1))", "b"},
+		{"data", "[b](data:text/html,x)", "b"},
+		{"javascript autolink", "<javascript:alert(1)>", "&lt;javascript:alert(1)&gt;"},
+		{"allowed schemes", "[x](tg://user?id=1) [m](mailto:a@b.c)", `<a href="tg://user?id=1">x</a> <a href="mailto:a@b.c">m</a>`},
+
+		// blocks
+		{"lists", "* item\n  - nested\n1. one", "• item\n  • nested\n1. one"},
+		{"task list", "- [ ] todo\n- [x] done", "☐ todo\n☑ done"},
+		{"heading", "# Head #", "<b>Head</b>"},
+		{"rule", "---", "———"},
+		{"quote", "> quote\n> **b**", "<blockquote>quote\n<b>b</b></blockquote>"},
+		{"paragraphs", "a\n\n\n\nb", "a\n\nb"},
+	}
+
+	for _, tt := range tests {
+		if got := MarkdownHTML(tt.md); got != tt.want {
+			t.Errorf("%s: MarkdownHTML(%q)\n got %q\nwant %q", tt.name, tt.md, got, tt.want)
+		}
+	}
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		md        string
+		limit     int
+		want      string
+		truncated bool
+	}{
+		{"Купить молоко и хлеб", 0, "Купить молоко и хлеб", false},
+		{"Купить молоко и хлеб", 20, "Купить молоко и хлеб", false},
+		{"  текст\r\nвторая  ", 100, "текст\nвторая", false},
+		// cut on space close to limit
+		{"Купить молоко и хлеб", 14, "Купить молоко…", true},
+		// delimiters cut from their pair stay as text, so no tag is left open
+		{"**Купить молоко** и хлеб", 12, "**Купить мол…", true},
+		{"[молоко](http://x) и хлеб", 12, "[молоко](htt…", true},
+	}
+
+	for _, tt := range tests {
+		got, truncated := Description(tt.md, tt.limit)
+		if got != tt.want || truncated != tt.truncated {
+			t.Errorf("Description(%q, %d) = %q, %v, want %q, %v", tt.md, tt.limit, got, truncated, tt.want, tt.truncated)
+		}
+	}
+}
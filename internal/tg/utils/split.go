@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MessageLimit is the max length of telegram message in characters
+const MessageLimit = 4096
+
+// SplitMessage splits plain text to messages not longer than MessageLimit.
+// Text is cut between paragraphs, so tasks of list are not torn apart, then between lines
+func SplitMessage(text string) []string {
+	if utf8.RuneCountInString(text) <= MessageLimit {
+		return []string{text}
+	}
+
+	var parts []string
+	part := ""
+	flush := func() {
+		if strings.TrimSpace(part) != "" {
+			parts = append(parts, strings.TrimRight(part, "\n"))
+		}
+		part = ""
+	}
+
+	for _, block := range splitBlocks(text) {
+		if utf8.RuneCountInString(part)+utf8.RuneCountInString(block) > MessageLimit {
+			flush()
+		}
+		part += block
+	}
+	flush()
+
+	return parts
+}
+
+// splitBlocks gives paragraphs with their trailing new lines,
+// paragraph longer than limit is split by lines and a too long line by characters
+func splitBlocks(text string) []string {
+	var blocks []string
+	for _, paragraph := range strings.SplitAfter(text, "\n\n") {
+		if utf8.RuneCountInString(paragraph) <= MessageLimit {
+			blocks = append(blocks, paragraph)
+			continue
+		}
+
+		for _, line := range strings.SplitAfter(paragraph, "\n") {
+			runes := []rune(line)
+			for len(runes) > MessageLimit {
+				blocks = append(blocks, string(runes[:MessageLimit]))
+				runes = runes[MessageLimit:]
+			}
+			blocks = append(blocks, string(runes))
+		}
+	}
+
+	return blocks
+}
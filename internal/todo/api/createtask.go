@@ -1,56 +1,76 @@
 package api
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
-	"todo/internal/todo/config"
-	"todo/internal/todo/models"
+	"time"
+	"todo/internal/todo/dto"
 
 	"go.uber.org/zap"
 )
 
-func Create(task models.Task, chatID int64) error {
-	type TaskDtoChatID struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		StatusId    uint   `json:"status_id"`
-		ChatId      int64  `json:"chat_id"`
-	}
+type TaskDtoChatID struct {
+	TaskId      uint   `json:"task_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	StatusId    uint   `json:"status_id"`
+	Status      string `json:"status"`
+	ChatId      int64  `json:"chat_id"`
+}
 
-	client := &http.Client{}
-	createURL := fmt.Sprintf("%s/create-task", config.AppConfig.TelegramAppURL)
+func Create(task dto.TaskEventDto, chatID int64, idempotencyKey string) error {
+	return sendTask("/create-task", task, chatID, idempotencyKey)
+}
 
+// send task card to tg service
+func sendTask(path string, task dto.TaskEventDto, chatID int64, idempotencyKey string) error {
 	dto := TaskDtoChatID{
+		TaskId:      task.TaskId,
 		Title:       task.Title,
 		Description: task.Description,
 		StatusId:    task.StatusId,
+		Status:      task.Status,
 		ChatId:      chatID,
 	}
 
-	jsonStr, err := json.Marshal(dto)
+	return send(path, dto, idempotencyKey)
+}
+
+// tg service waits for rate limits of telegram before answering, up to 3 retries by 30 s,
+// so timeout is above that. It still keeps one slow chat from holding outbox dispatcher forever
+var client = &http.Client{Timeout: 2 * time.Minute}
+
+// send event to tg service, it must answer 201
+func send(path string, payload any, idempotencyKey string) error {
+
+	jsonStr, err := json.Marshal(payload)
 	if err != nil {
 		zap.S().Error("error marshalling DTO", zap.Error(err))
 		return err
 	}
 
-	// create io.Reader from JSON
-	response, err := client.Post(createURL, "application/json", bytes.NewBuffer(jsonStr))
+	req, err := newSignedRequest(path, jsonStr)
 	if err != nil {
-		zap.S().Error("error during user registration", zap.Error(err))
 		return err
 	}
-	defer response.Body.Close()
+	req.Header.Set("Idempotency-Key", idempotencyKey) // tg service drops repeated deliveries
 
-	fmt.Println("Response status:", response.StatusCode)
+	response, err := client.Do(req)
+	if err != nil {
+		zap.S().Error("error sending event to tg service", zap.Error(err))
+		return err
+	}
+	defer response.Body.Close()
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
 		zap.S().Error("error reading response body", zap.Error(err))
 	}
-	fmt.Println(string(body))
 
-	return err
+	if response.StatusCode != http.StatusCreated {
+		return responseError(response, body)
+	}
+
+	return nil
 }
@@ -0,0 +1,49 @@
+package api
+
+import "todo/internal/todo/dto"
+
+type CommentDtoChatID struct {
+	TaskId uint   `json:"task_id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Text   string `json:"text"`
+	ChatId int64  `json:"chat_id"`
+}
+
+// StatusChanged posts new status of task to board chat
+func StatusChanged(task dto.TaskEventDto, chatID int64, idempotencyKey string) error {
+	return sendTask("/task-status", task, chatID, idempotencyKey)
+}
+
+// Comment posts comment on task to board chat
+func Comment(comment dto.CommentEventDto, chatID int64, idempotencyKey string) error {
+	dto := CommentDtoChatID{
+		TaskId: comment.TaskId,
+		Title:  comment.Title,
+		Author: comment.Author,
+		Text:   comment.Text,
+		ChatId: chatID,
+	}
+
+	return send("/task-comment", dto, idempotencyKey)
+}
+
+// Assigned tells user that task is assigned to him
+func Assigned(task dto.TaskEventDto, chatID int64, idempotencyKey string) error {
+	return sendTask("/assign-task", task, chatID, idempotencyKey)
+}
+
+// Due reminds that deadline of task is near
+func Due(task dto.TaskEventDto, chatID int64, idempotencyKey string) error {
+	return sendTask("/due-task", task, chatID, idempotencyKey)
+}
+
+type NotifyDtoChatID struct {
+	Text   string `json:"text"`
+	ChatId int64  `json:"chat_id"`
+}
+
+// Notify sends plain text message, e.g. summary after quiet hours
+func Notify(text string, chatID int64, idempotencyKey string) error {
+	return send("/notify", NotifyDtoChatID{Text: text, ChatId: chatID}, idempotencyKey)
+}
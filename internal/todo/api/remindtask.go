@@ -0,0 +1,8 @@
+package api
+
+import "todo/internal/todo/dto"
+
+// Remind sends snoozed task back to the chat
+func Remind(task dto.TaskEventDto, chatID int64, idempotencyKey string) error {
+	return sendTask("/remind-task", task, chatID, idempotencyKey)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/config"
+	"todo/pkg/signature"
+)
+
+// build POST request to tg service signed with the internal secret
+func newSignedRequest(path string, body []byte) (*http.Request, error) {
+	url := fmt.Sprintf("%s%s", config.AppConfig.TelegramAppURL, path)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	signature.Sign(req, body, config.AppConfig.InternalSecret)
+
+	return req, nil
+}
+
+// Error is response of tg service other than 201,
+// RetryAfter is set when telegram asked to wait before sending to the chat again
+type Error struct {
+	Status     int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("tg service responded %d: %s", e.Status, e.Message)
+}
+
+func responseError(resp *http.Response, body []byte) *Error {
+	apiErr := &Error{Status: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiErr
+}
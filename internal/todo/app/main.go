@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"todo/internal/todo/config"
+	"todo/internal/todo/middleware"
 	"todo/internal/todo/services"
 	"todo/internal/todo/storage"
 	"todo/internal/todo/transport/http/handler"
@@ -46,9 +47,30 @@ func StartServer() {
 		StatusesStorager: &db.StatusesStorage,
 		TasksStorager:    &db.TasksStorage,
 		UserStorager:     &db.UserStorage,
+		OutboxStorager:   &db.OutboxStorage,
+
+		NotificationsStorager: &db.NotificationsStorage,
+		WebhooksStorager:      &db.WebhooksStorage,
+		EventsStorager:        &db.EventsStorage,
+		TimeStorager:          &db.TimeStorage,
+		AnalyticsStorager:     &db.AnalyticsStorage,
+		FieldsStorager:        &db.FieldsStorage,
+		LabelsStorager:        &db.LabelsStorage,
+		TemplatesStorager:     &db.TemplatesStorage,
+		TransfersStorager:     &db.TransfersStorage,
+		BulkStorager:          &db.BulkStorage,
+		ExportStorager:        &db.ExportStorage,
+		ImportStorager:        &db.ImportStorage,
+		CalendarStorager:      &db.CalendarStorage,
+		AttachmentsStorager:   &db.AttachmentsStorage,
 	}, log)
 
 	s.TasksService.StartScheduler()
+	s.OutboxService.StartDispatcher()
+	s.NotificationsService.StartSummaries()
+	s.WebhooksService.StartDispatcher()
+	s.EventsService.Start()
+	s.AttachmentsService.StartSweeper()
 
 	// init handler
 	h := handler.New(handler.TodoService{
@@ -56,8 +78,27 @@ func StartServer() {
 		StatusesService: &s.StatusesService,
 		TasksService:    &s.TasksService,
 		UserService:     &s.UserService,
+		OutboxService:   &s.OutboxService,
+
+		NotificationsService: &s.NotificationsService,
+		WebhooksService:      &s.WebhooksService,
+		EventsService:        s.EventsService,
+		TimeService:          &s.TimeService,
+		AnalyticsService:     &s.AnalyticsService,
+		FieldsService:        &s.FieldsService,
+		LabelsService:        &s.LabelsService,
+		TemplatesService:     &s.TemplatesService,
+		TransfersService:     &s.TransfersService,
+		BulkService:          &s.BulkService,
+		ExportService:        &s.ExportService,
+		ImportService:        &s.ImportService,
+		CalendarService:      &s.CalendarService,
+		AttachmentsService:   &s.AttachmentsService,
 	}, log)
 
+	// nonces of internal requests are shared by all instances through postgres
+	middleware.UseNonceStore(db.NoncesStorage)
+
 	// init router
 	r := router.New(&h)
 
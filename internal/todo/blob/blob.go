@@ -0,0 +1,44 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+	ErrMismatch   = errors.New("blob content doesn't match size or checksum")
+)
+
+// BlobStore keeps content of files by key.
+// Keys are slash separated paths of letters, digits, dots, dashes and underscores
+type BlobStore interface {
+	// Put saves size bytes of r, sha256 is hex checksum of content and is verified by store
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string, sha256 string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes blob, missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey checks key can be used as file path and object name as it is
+func validKey(key string) bool {
+	if key == "" || len(key) > 255 {
+		return false
+	}
+
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,137 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"tasks/1/file.txt", true},
+		{"a-b_c.d/E9", true},
+		{"", false},
+		{"/tasks/file", false},
+		{"tasks/", false},
+		{"tasks//file", false},
+		{"../file", false},
+		{"tasks/./file", false},
+		{"tasks/../../file", false},
+		{`tasks\file`, false},
+		{"tasks/file name", false},
+		{"tasks/файл", false},
+		{"tasks/%2e%2e", false},
+		{strings.Repeat("a", 255), true},
+		{strings.Repeat("a", 256), false},
+	}
+
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Errorf("validKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	content := "Купить молоко"
+	if err := store.Put(ctx, "tasks/1/file.txt", strings.NewReader(content), int64(len(content)), "text/plain", checksum(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := store.Get(ctx, "tasks/1/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if string(got) != content {
+		t.Errorf("content %q", got)
+	}
+
+	// mismatched upload leaves neither the object nor temporary file
+	for _, tt := range []struct {
+		body string
+		size int64
+		sum  string
+	}{
+		{"changed", 7, checksum(content)},
+		{content + "!", int64(len(content)), checksum(content)},
+		{content, int64(len(content)) + 1, checksum(content)},
+	} {
+		err := store.Put(ctx, "tasks/2/file.txt", strings.NewReader(tt.body), tt.size, "text/plain", tt.sum)
+		if !errors.Is(err, ErrMismatch) {
+			t.Errorf("put of %q with size %d: %v", tt.body, tt.size, err)
+		}
+	}
+	if _, err := store.Get(ctx, "tasks/2/file.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get of mismatched: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "blobs", "tasks", "2")); len(entries) != 0 {
+		t.Errorf("files left after mismatch: %v", entries)
+	}
+
+	if err := store.Delete(ctx, "tasks/1/file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "tasks/1/file.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get of deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blobs", "tasks", "1")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("directory of deleted blob: %v", err)
+	}
+	if err := store.Delete(ctx, "tasks/1/file.txt"); err != nil {
+		t.Errorf("delete of missing: %v", err)
+	}
+}
+
+// keys out of directory are refused before touching files
+func TestLocalInvalidKey(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../secret", "tasks/../../secret", "/secret", ""} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain", checksum("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("put %q: %v", key, err)
+		}
+		if _, err := store.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("get %q: %v", key, err)
+		}
+		if err := store.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("delete %q: %v", key, err)
+		}
+	}
+
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Errorf("file out of directory: %q, %v", data, err)
+	}
+}
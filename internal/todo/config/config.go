@@ -2,19 +2,38 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap/zapcore"
 )
 
 type Config struct {
-	ServerAddress  string
-	DBDSN          string
-	LogLevel       string
-	SecretKey      string
-	TelegramToken  string
-	TelegramAppURL string
+	ServerAddress   string
+	DBDSN           string
+	LogLevel        string
+	SecretKey       string
+	TelegramToken   string
+	TelegramAppURL  string
+	TelegramBotName string
+	AdminIDs        []uint
+	InternalSecret  string
+	SMTPHost        string
+	SMTPPort        string
+	SMTPUser        string
+	SMTPPassword    string
+	SMTPFrom        string
+	AppURL          string
+	BlobStore       string
+	BlobDir         string
+	S3Endpoint      string
+	S3Region        string
+	S3Bucket        string
+	S3AccessKey     string
+	S3SecretKey     string
 }
 
 var AppConfig *Config
@@ -60,6 +79,62 @@ func GetConfig() (*Config, error) {
 		cfg.TelegramAppURL = zapcore.ErrorLevel.String()
 	}
 
+	// bot username without @, used for deep links
+	cfg.TelegramBotName = os.Getenv("TELEGRAM_BOT_NAME")
+
+	// public url of todo service for links to calendar feeds
+	cfg.AppURL = strings.TrimRight(os.Getenv("TODO_APP_URL"), "/")
+
+	// shared with tg service to sign internal requests
+	cfg.InternalSecret = os.Getenv("INTERNAL_SECRET")
+
+	// smtp server for email notifications, disabled when host is empty
+	cfg.SMTPHost = os.Getenv("SMTP_HOST")
+	cfg.SMTPUser = os.Getenv("SMTP_USER")
+	cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+
+	if smtpPort := os.Getenv("SMTP_PORT"); smtpPort != "" {
+		cfg.SMTPPort = smtpPort
+	} else {
+		cfg.SMTPPort = "25"
+	}
+
+	if smtpFrom := os.Getenv("SMTP_FROM"); smtpFrom != "" {
+		cfg.SMTPFrom = smtpFrom
+	} else {
+		cfg.SMTPFrom = "todo@localhost"
+	}
+
+	// storage of attachment files: local directory or S3 compatible service
+	if blobStore := os.Getenv("BLOB_STORE"); blobStore != "" {
+		cfg.BlobStore = blobStore
+	} else {
+		cfg.BlobStore = "local"
+	}
+
+	if blobDir := os.Getenv("BLOB_DIR"); blobDir != "" {
+		cfg.BlobDir = blobDir
+	} else {
+		cfg.BlobDir = "data/attachments"
+	}
+
+	cfg.S3Endpoint = os.Getenv("S3_ENDPOINT")
+	cfg.S3Region = os.Getenv("S3_REGION")
+	cfg.S3Bucket = os.Getenv("S3_BUCKET")
+	cfg.S3AccessKey = os.Getenv("S3_ACCESS_KEY")
+	cfg.S3SecretKey = os.Getenv("S3_SECRET_KEY")
+
+	// comma separated ids of users allowed to use admin routes
+	if adminIDs := os.Getenv("ADMIN_IDS"); adminIDs != "" {
+		for _, idStr := range strings.Split(adminIDs, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ADMIN_IDS: %w", err)
+			}
+			cfg.AdminIDs = append(cfg.AdminIDs, uint(id))
+		}
+	}
+
 	flag.Parse()
 
 	AppConfig = cfg
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// AnalyticsFilterDto is query of board analytics as it came from request,
+// from and to are dates YYYY-MM-DD (both inclusive) or RFC 3339 times
+type AnalyticsFilterDto struct {
+	From     string
+	To       string
+	Timezone string
+}
+
+type BoardAnalyticsDto struct {
+	BoardId        uint                `json:"board_id"`
+	From           time.Time           `json:"from"`
+	To             time.Time           `json:"to"`
+	Timezone       string              `json:"timezone"`
+	EstimateUnit   string              `json:"estimate_unit"`
+	LeadTime       DurationStatsDto    `json:"lead_time"`
+	CycleTime      DurationStatsDto    `json:"cycle_time"`
+	Throughput     []ThroughputWeekDto `json:"throughput"`
+	CumulativeFlow CumulativeFlowDto   `json:"cumulative_flow"`
+	Burndown       []BurndownDayDto    `json:"burndown"`
+}
+
+// durations of tasks completed within range, in hours
+type DurationStatsDto struct {
+	Count       int     `json:"count"`
+	AvgHours    float64 `json:"avg_hours"`
+	MedianHours float64 `json:"median_hours"`
+	P85Hours    float64 `json:"p85_hours"`
+}
+
+// week starts on monday, tasks completed outside of range are not counted
+type ThroughputWeekDto struct {
+	WeekStart string  `json:"week_start"`
+	Completed int     `json:"completed"`
+	Estimate  float64 `json:"estimate"`
+}
+
+// Counts of every series are aligned with Days
+type CumulativeFlowDto struct {
+	Days   []string        `json:"days"`
+	Series []FlowSeriesDto `json:"series"`
+}
+
+type FlowSeriesDto struct {
+	StatusId uint   `json:"status_id"`
+	Status   string `json:"status"`
+	Counts   []int  `json:"counts"`
+}
+
+// remaining values are at the end of day, null for days which haven't come yet
+type BurndownDayDto struct {
+	Day               string   `json:"day"`
+	RemainingTasks    *int     `json:"remaining_tasks"`
+	RemainingEstimate *float64 `json:"remaining_estimate"`
+	IdealTasks        float64  `json:"ideal_tasks"`
+	IdealEstimate     float64  `json:"ideal_estimate"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+type AttachmentDto struct {
+	ID          uint      `json:"id"`
+	TaskId      uint      `json:"task_id"`
+	UserId      uint      `json:"user_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Sha256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
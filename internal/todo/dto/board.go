@@ -5,5 +5,10 @@ type GetBoardDto struct {
 }
 
 type PostBoardDto struct {
-	Name string `json:"name"`
+	Name         string `json:"name"`
+	EstimateUnit string `json:"estimate_unit,omitempty"` // points or hours, empty keeps the current one
+}
+
+type PutBoardChatDto struct {
+	ChatId int64 `json:"chat_id"`
 }
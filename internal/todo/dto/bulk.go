@@ -0,0 +1,31 @@
+package dto
+
+// PostBulkDto is a list of operations on tasks. Atomic applies all operations or none,
+// otherwise every operation is applied separately
+type PostBulkDto struct {
+	Atomic     bool               `json:"atomic"`
+	Operations []BulkOperationDto `json:"operations"`
+}
+
+type BulkOperationDto struct {
+	Op       string        `json:"op"` // status, assign, move, delete, add_label or remove_label
+	TaskId   uint          `json:"task_id"`
+	StatusId uint          `json:"status_id"` // for status
+	UserId   uint          `json:"user_id"`   // for assign
+	BoardId  uint          `json:"board_id"`  // for move, with mapping as in move-to-board
+	Statuses map[uint]uint `json:"statuses"`
+	Fields   map[uint]uint `json:"fields"`
+	Labels   map[uint]uint `json:"labels"`
+	LabelId  uint          `json:"label_id"` // for add_label and remove_label
+}
+
+type BulkResultDto struct {
+	Applied int                 `json:"applied"`
+	Results []BulkItemResultDto `json:"results"` // in order of operations
+}
+
+type BulkItemResultDto struct {
+	TaskId uint   `json:"task_id"`
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
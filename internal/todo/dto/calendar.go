@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// CalendarLinkDto is url of calendar feed, anyone with url can read the feed
+type CalendarLinkDto struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarFilterDto filters tasks of feed, empty filters give all boards of user
+type CalendarFilterDto struct {
+	Boards []uint
+	Labels []string // values of select and multi_select fields
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+// ExportDto is json export of boards. Export is written by parts, so tasks of all boards
+// are in one list after boards. Values of custom fields are keyed by field id from fields of board,
+// labels of tasks are ids from labels of board. Export can be imported back with source "todo"
+type ExportDto struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Statuses   []ExportStatusDto `json:"statuses"`
+	Boards     []ExportBoardDto  `json:"boards"`
+	Tasks      []ExportTaskDto   `json:"tasks"`
+}
+
+type ExportStatusDto struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type ExportUserDto struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+type ExportBoardDto struct {
+	ID           uint            `json:"id"`
+	Name         string          `json:"name"`
+	OwnerId      uint            `json:"owner_id"`
+	EstimateUnit string          `json:"estimate_unit"`
+	Members      []ExportUserDto `json:"members"`
+	Fields       []BoardFieldDto `json:"fields"`
+	Labels       []BoardLabelDto `json:"labels"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type ExportTaskDto struct {
+	ID             uint               `json:"id"`
+	BoardId        uint               `json:"board_id"`
+	Title          string             `json:"title"`
+	Description    string             `json:"description"`
+	StatusId       uint               `json:"status_id"`
+	Status         string             `json:"status"`
+	Assignee       *ExportUserDto     `json:"assignee"`
+	Priority       int                `json:"priority"`
+	DueAt          *time.Time         `json:"due_at"`
+	Estimate       *float64           `json:"estimate"`
+	TrackedSeconds int64              `json:"tracked_seconds"`
+	CustomFields   map[string]any     `json:"custom_fields"`
+	Labels         []uint             `json:"labels"`
+	Comments       []ExportCommentDto `json:"comments"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type ExportCommentDto struct {
+	UserId    uint      `json:"user_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
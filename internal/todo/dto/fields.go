@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+type PostBoardFieldDto struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"` // can't be changed
+	Options  []string `json:"options"`
+	Position int      `json:"position"`
+}
+
+type BoardFieldDto struct {
+	ID        uint      `json:"id"`
+	BoardId   uint      `json:"board_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Options   []string  `json:"options"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FieldFilterDto is condition on custom field in task listing
+type FieldFilterDto struct {
+	FieldId  uint
+	Value    string // json of value for exact match or text to search
+	Contains bool   // search text in value case insensitively
+}
@@ -0,0 +1,38 @@
+package dto
+
+// ImportReportDto describes what import creates, dry run gives the same report without changes
+type ImportReportDto struct {
+	Source string                 `json:"source"`
+	DryRun bool                   `json:"dry_run"`
+	Boards []ImportBoardReportDto `json:"boards"`
+}
+
+type ImportBoardReportDto struct {
+	ExternalId string                  `json:"external_id"`
+	Name       string                  `json:"name"`
+	BoardId    uint                    `json:"board_id"` // 0 when new board is not created yet
+	Exists     bool                    `json:"exists"`   // board was imported before, only new tasks are added
+	Statuses   []ImportStatusReportDto `json:"statuses"`
+	Members    []ImportMemberReportDto `json:"members"`
+	Labels     []string                `json:"labels"`
+	Fields     []string                `json:"fields"`
+	Tasks      int                     `json:"tasks"`   // tasks to create
+	Skipped    int                     `json:"skipped"` // tasks imported before
+	Checklists int                     `json:"checklists"`
+	Comments   int                     `json:"comments"`
+	Unmapped   []string                `json:"unmapped"` // what is imported partly or not at all
+}
+
+// ImportStatusReportDto shows status of tasks from list, unmapped lists go to "in process"
+type ImportStatusReportDto struct {
+	List     string `json:"list"`
+	StatusId uint   `json:"status_id"`
+	Status   string `json:"status"`
+	Mapped   bool   `json:"mapped"`
+}
+
+type ImportMemberReportDto struct {
+	Username string `json:"username"`
+	UserId   uint   `json:"user_id"`
+	Mapped   bool   `json:"mapped"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type PostBoardLabelDto struct {
+	Name  string `json:"name"`
+	Color string `json:"color"` // #rrggbb, can be empty
+}
+
+type BoardLabelDto struct {
+	ID        uint      `json:"id"`
+	BoardId   uint      `json:"board_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
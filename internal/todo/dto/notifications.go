@@ -0,0 +1,87 @@
+package dto
+
+import "encoding/json"
+
+// quiet hours are "HH:MM", both empty when they are off
+type NotificationSettingsDto struct {
+	Created       bool   `json:"created"`
+	Assigned      bool   `json:"assigned"`
+	Commented     bool   `json:"commented"`
+	Due           bool   `json:"due"`
+	StatusChanged bool   `json:"status_changed"`
+	Digest        bool   `json:"digest"`
+	QuietFrom     string `json:"quiet_from"`
+	QuietTo       string `json:"quiet_to"`
+	Timezone      string `json:"timezone"`
+	MutedBoards   []uint `json:"muted_boards"`
+
+	// channels by kind: telegram, email, webhook
+	Channels   map[string][]string `json:"channels"`
+	Email      string              `json:"email"`
+	WebhookURL string              `json:"webhook_url"`
+	// is only read, email is confirmed by code from letter
+	EmailConfirmed bool `json:"email_confirmed"`
+	// is only written, empty value keeps the current secret
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+
+	// weekly numbers of boards in daily digest
+	DigestAnalytics bool `json:"digest_analytics"`
+}
+
+type PostEmailConfirmDto struct {
+	Code string `json:"code"`
+}
+
+// EmailConfirmEventDto sends code to email which isn't confirmed yet
+type EmailConfirmEventDto struct {
+	UserId uint   `json:"user_id"`
+	Email  string `json:"email"`
+	Code   string `json:"code"`
+}
+
+type SummaryEventDto struct {
+	UserId uint   `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+// NotificationDto is one personal notification, the same for every channel
+type NotificationDto struct {
+	Event   string          `json:"event"`
+	Kind    string          `json:"kind"`
+	Subject string          `json:"subject"`
+	Text    string          `json:"text"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// DeliveryEventDto delivers notification to user through one channel
+type DeliveryEventDto struct {
+	Channel      string          `json:"channel"`
+	UserId       uint            `json:"user_id"`
+	Notification NotificationDto `json:"notification"`
+}
+
+type DigestTaskDto struct {
+	TaskId      uint   `json:"task_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	StatusId    uint   `json:"status_id"`
+}
+
+type DigestEventDto struct {
+	UserId  uint            `json:"user_id"`
+	Current []DigestTaskDto `json:"current"`
+	Done    []DigestTaskDto `json:"done"`
+	// only when user turned on digest analytics
+	Weekly []DigestBoardStatsDto `json:"weekly,omitempty"`
+}
+
+// DigestBoardStatsDto is numbers of board for the last 7 days
+type DigestBoardStatsDto struct {
+	BoardId       uint    `json:"board_id"`
+	BoardName     string  `json:"board_name"`
+	EstimateUnit  string  `json:"estimate_unit"`
+	Created       int     `json:"created"`
+	Completed     int     `json:"completed"`
+	Estimate      float64 `json:"estimate"`
+	LeadTimeHours float64 `json:"lead_time_hours"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+type TaskEventDto struct {
+	TaskId      uint   `json:"task_id"`
+	UserId      uint   `json:"user_id"`
+	ActorId     uint   `json:"actor_id"` // who made the change, he is not notified
+	BoardId     uint   `json:"board_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	StatusId    uint   `json:"status_id"`
+	Status      string `json:"status"`
+}
+
+type CommentEventDto struct {
+	CommentId uint   `json:"comment_id"`
+	TaskId    uint   `json:"task_id"`
+	BoardId   uint   `json:"board_id"`
+	UserId    uint   `json:"user_id"` // assignee of task
+	AuthorId  uint   `json:"author_id"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+}
+
+// BulkEventDto is a coalesced notification about changes of bulk request
+// for assignee (UserId) or for board chat (BoardId)
+type BulkEventDto struct {
+	UserId  uint            `json:"user_id"`
+	BoardId uint            `json:"board_id"`
+	ActorId uint            `json:"actor_id"`
+	Changes []BulkChangeDto `json:"changes"`
+}
+
+type BulkChangeDto struct {
+	Kind    string `json:"kind"` // status_changed or assigned
+	TaskId  uint   `json:"task_id"`
+	BoardId uint   `json:"board_id"`
+	UserId  uint   `json:"user_id"` // assignee of task
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+}
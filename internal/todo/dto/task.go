@@ -1,13 +1,41 @@
 package dto
 
+import "time"
+
 type GetTaskDto struct {
 	Title string `json:"title"`
 }
 
 type PostTaskDto struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	BoardId     string `json:"board_id"`
-	StatusId    uint   `json:"status_id"`
-	UserId      string `json:"user_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	BoardId     string     `json:"board_id"`
+	StatusId    uint       `json:"status_id"`
+	UserId      string     `json:"user_id"`
+	Priority    int        `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	Estimate    *float64   `json:"estimate"`
+
+	// values of board fields by field id, null removes value. Without fields on update the current values are kept
+	Fields map[string]any `json:"fields"`
+
+	// ids of board labels. Without labels on update the current labels are kept
+	Labels []uint `json:"labels"`
+}
+
+type PutTaskStatusDto struct {
+	StatusId uint `json:"status_id"`
+}
+
+type PostSnoozeDto struct {
+	Minutes int `json:"minutes"`
+}
+
+type TaskFilterDto struct {
+	BoardId uint
+	Fields  []FieldFilterDto
+}
+
+type PostCommentDto struct {
+	Text string `json:"text"`
 }
@@ -0,0 +1,71 @@
+package dto
+
+import "time"
+
+// PostBoardTemplateDto saves board as template
+type PostBoardTemplateDto struct {
+	BoardId uint   `json:"board_id"`
+	Name    string `json:"name"`
+}
+
+type BoardTemplateDto struct {
+	ID           uint                `json:"id"`
+	Name         string              `json:"name"`
+	EstimateUnit string              `json:"estimate_unit"`
+	Statuses     []string            `json:"statuses"`
+	Fields       []PostBoardFieldDto `json:"fields"`
+	Labels       []PostBoardLabelDto `json:"labels"`
+	Tasks        []TemplateTaskDto   `json:"tasks"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+type TemplateTaskDto struct {
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Status       string         `json:"status"`
+	Priority     int            `json:"priority"`
+	Estimate     *float64       `json:"estimate"`
+	DueInMinutes *int           `json:"due_in_minutes"`
+	Fields       map[string]any `json:"fields"` // by field name
+	Labels       []string       `json:"labels"` // by label name
+}
+
+// PostBoardFromTemplateDto creates board from template, empty name is taken from template
+type PostBoardFromTemplateDto struct {
+	Name string `json:"name"`
+}
+
+type PostTaskTemplateDto struct {
+	Name         string         `json:"name"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Priority     int            `json:"priority"`
+	Estimate     *float64       `json:"estimate"`
+	DueInMinutes *int           `json:"due_in_minutes"`
+	Fields       map[string]any `json:"fields"` // by field id
+	Labels       []uint         `json:"labels"` // ids of board labels
+}
+
+type TaskTemplateDto struct {
+	ID           uint           `json:"id"`
+	BoardId      uint           `json:"board_id"`
+	AuthorId     uint           `json:"author_id"`
+	Name         string         `json:"name"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Priority     int            `json:"priority"`
+	Estimate     *float64       `json:"estimate"`
+	DueInMinutes *int           `json:"due_in_minutes"`
+	Fields       map[string]any `json:"fields"`
+	Labels       []uint         `json:"labels"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// PostTaskFromTemplateDto creates task by template id or by its name on boards of user,
+// board_id is needed when several boards have template with this name
+type PostTaskFromTemplateDto struct {
+	TemplateId uint   `json:"template_id"`
+	Name       string `json:"name"`
+	BoardId    uint   `json:"board_id"`
+	Title      string `json:"title"` // replaces title of template
+}
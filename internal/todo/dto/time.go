@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+type PostTimerDto struct {
+	Note string `json:"note"`
+}
+
+type PostTimeEntryDto struct {
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Note      string    `json:"note"`
+}
+
+// seconds of running timer are counted up to now
+type TimeEntryDto struct {
+	ID        uint       `json:"id"`
+	TaskId    uint       `json:"task_id"`
+	UserId    uint       `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Note      string     `json:"note"`
+	Seconds   int64      `json:"seconds"`
+	Running   bool       `json:"running"`
+}
+
+// TimerStartedDto is response of timer start, Stopped is the timer it replaced
+type TimerStartedDto struct {
+	Timer   TimeEntryDto  `json:"timer"`
+	Stopped *TimeEntryDto `json:"stopped"`
+}
+
+// TimeReportFilterDto is query of time report as it came from request,
+// from and to are dates YYYY-MM-DD (both inclusive) or RFC 3339 times
+type TimeReportFilterDto struct {
+	From     string
+	To       string
+	GroupBy  string // comma separated: user, board, day
+	Timezone string
+	BoardId  uint
+}
+
+type TimeReportDto struct {
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	Timezone     string             `json:"timezone"`
+	GroupBy      []string           `json:"group_by"`
+	Rows         []TimeReportRowDto `json:"rows"`
+	TotalSeconds int64              `json:"total_seconds"`
+}
+
+// fields which are not grouped by are omitted
+type TimeReportRowDto struct {
+	UserId    uint   `json:"user_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	BoardId   uint   `json:"board_id,omitempty"`
+	BoardName string `json:"board_name,omitempty"`
+	Day       string `json:"day,omitempty"`
+	Seconds   int64  `json:"seconds"`
+}
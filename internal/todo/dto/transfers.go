@@ -0,0 +1,20 @@
+package dto
+
+// PostTaskTransferDto moves or copies task to board. Statuses, fields and labels map ids of source
+// to ids of target, field or label mapped to 0 is dropped. Fields without mapping go to the field
+// with the same name and type if there is one, labels — to the label with the same name
+type PostTaskTransferDto struct {
+	BoardId  uint          `json:"board_id"`
+	Statuses map[uint]uint `json:"statuses"`
+	Fields   map[uint]uint `json:"fields"`
+	Labels   map[uint]uint `json:"labels"`
+	Comments bool          `json:"comments"` // only for copy
+}
+
+// PostBoardCloneDto clones board with its fields and labels, the rest is optional
+type PostBoardCloneDto struct {
+	Name     string `json:"name"`
+	Tasks    bool   `json:"tasks"`
+	Members  bool   `json:"members"`
+	Comments bool   `json:"comments"` // needs tasks
+}
@@ -1,9 +1,50 @@
 package dto
 
+import "time"
+
 type GetUserDto struct {
 	Username string `json:"username"`
 }
 
+type UserDto struct {
+	ID       uint         `json:"id"`
+	Username string       `json:"username"`
+	Telegram *TelegramDto `json:"telegram"` // null when telegram is not linked
+}
+
+// TelegramDto is state of telegram link, it is inactive when user blocked bot or deleted chat
+type TelegramDto struct {
+	TgName     string     `json:"tg_name"`
+	Active     bool       `json:"active"`
+	InactiveAt *time.Time `json:"inactive_at,omitempty"`
+}
+
+type TgActivateDto struct {
+	ChatID int64 `json:"chat_id"`
+}
+
+type TgActivatedDto struct {
+	Reactivated bool `json:"reactivated"`
+}
+
+type PostTgLinkDto struct {
+	Code     string `json:"code"`
+	TgUserID int64  `json:"tg_user_id"`
+	ChatID   int64  `json:"chat_id"`
+	TgName   string `json:"tg_name"`
+}
+
+type TgUserDto struct {
+	TgUserID int64 `json:"tg_user_id"`
+	ChatID   int64 `json:"chat_id"`
+}
+
+type TgLinkCodeDto struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type PostUserDto struct {
 	Username     string `json:"username"`
 	TgName       string `json:"tg_name"`
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PostWebhookDto struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"` // generated when empty
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// secret is shown only once, when webhook is created
+type WebhookDto struct {
+	ID        uint      `json:"id"`
+	BoardId   uint      `json:"board_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryDto struct {
+	ID             uint            `json:"id"`
+	WebhookId      uint            `json:"webhook_id"`
+	Guid           string          `json:"guid"`
+	RedeliveryOf   *uint           `json:"redelivery_of"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status"`
+	ResponseBody   string          `json:"response_body"`
+	DurationMs     *int            `json:"duration_ms"`
+	LastError      string          `json:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// BoardEventDto is body of webhook request
+type BoardEventDto struct {
+	Event      string    `json:"event"`
+	BoardId    uint      `json:"board_id"`
+	ActorId    uint      `json:"actor_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+type BoardTaskDto struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	BoardId     uint       `json:"board_id"`
+	StatusId    uint       `json:"status_id"`
+	UserId      uint       `json:"user_id"`
+	Priority    int        `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	Estimate    *float64   `json:"estimate"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	CustomFields map[string]any `json:"custom_fields"`
+	Labels       []uint         `json:"labels"`
+}
+
+type TaskPlaceDto struct {
+	BoardId  uint `json:"board_id"`
+	StatusId uint `json:"status_id"`
+}
+
+// from and to are set only for task.moved
+type TaskEventDataDto struct {
+	Task BoardTaskDto  `json:"task"`
+	From *TaskPlaceDto `json:"from,omitempty"`
+	To   *TaskPlaceDto `json:"to,omitempty"`
+}
+
+type MemberEventDataDto struct {
+	UserId uint `json:"user_id"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "encoding/json"
+
+// message from client, id is returned in ack
+type WsCommandDto struct {
+	Id      string          `json:"id"`
+	Type    string          `json:"type"` // task.create, task.move or ping
+	Payload json.RawMessage `json:"payload"`
+}
+
+// board_id is optional, task stays on its board without it
+type WsMoveTaskDto struct {
+	TaskId   uint `json:"task_id"`
+	StatusId uint `json:"status_id"`
+	BoardId  uint `json:"board_id"`
+}
+
+type WsAckDto struct {
+	Type  string        `json:"type"` // ack
+	Id    string        `json:"id"`
+	Ok    bool          `json:"ok"`
+	Error string        `json:"error,omitempty"`
+	Task  *BoardTaskDto `json:"task,omitempty"`
+}
+
+// Data is the same as body of board webhook
+type WsEventDto struct {
+	Type  string          `json:"type"` // event
+	Id    uint            `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type WsPresenceDto struct {
+	Type  string        `json:"type"` // presence
+	Users []WsViewerDto `json:"users"`
+}
+
+type WsViewerDto struct {
+	UserId   uint   `json:"user_id"`
+	Username string `json:"username"`
+}
@@ -0,0 +1,28 @@
+// Package importer parses exports of Trello, Todoist and json exports of this service into boards for import
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"todo/internal/todo/models"
+)
+
+// Parse reads export of source. Todoist CSV has no project name, so name is used for its board
+func Parse(source string, data []byte, name string) ([]models.ImportBoard, error) {
+	switch source {
+	case models.ImportTrello:
+		return parseTrello(data)
+	case models.ImportTodoist:
+		return parseTodoist(data, name)
+	case models.ImportNative:
+		return parseNative(data)
+	}
+
+	return nil, fmt.Errorf("%w: unknown source %q", models.ErrInvalidInput, source)
+}
+
+func invalid(source string, err error) error {
+	return fmt.Errorf("%w: invalid %s export: %v", models.ErrInvalidInput, source, err)
+}
+
+var errNoID = errors.New("board id is missing")
@@ -0,0 +1,124 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+)
+
+// parseNative reads json export of this service, every exported board becomes a board of import.
+// Statuses are lists, ids of export are external ids
+func parseNative(data []byte) ([]models.ImportBoard, error) {
+	var src dto.ExportDto
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, invalid(models.ImportNative, err)
+	}
+
+	if src.Version != models.ExportVersion {
+		return nil, invalid(models.ImportNative, fmt.Errorf("version %d is not supported", src.Version))
+	}
+
+	var lists []models.ImportList
+	for _, status := range src.Statuses {
+		lists = append(lists, models.ImportList{
+			ExternalId: externalID(status.ID),
+			Name:       status.Name,
+			Closed:     status.ID == models.StatusArchived,
+		})
+	}
+
+	boards := make([]models.ImportBoard, 0, len(src.Boards))
+	index := make(map[uint]int, len(src.Boards))
+	users := make(map[uint]map[string]bool, len(src.Boards)) // user fields by board
+	for _, board := range src.Boards {
+		item := models.ImportBoard{
+			ExternalId:   externalID(board.ID),
+			Name:         board.Name,
+			EstimateUnit: board.EstimateUnit,
+			Lists:        lists,
+		}
+
+		for _, member := range board.Members {
+			item.Members = append(item.Members, models.ImportMember{ExternalId: externalID(member.ID), Usernames: []string{member.Username}})
+		}
+
+		users[board.ID] = make(map[string]bool)
+		for _, field := range board.Fields {
+			item.Fields = append(item.Fields, models.ImportField{
+				ExternalId: externalID(field.ID),
+				Name:       field.Name,
+				Type:       field.Type,
+				Options:    field.Options,
+			})
+			if field.Type == models.FieldUser {
+				users[board.ID][externalID(field.ID)] = true
+			}
+		}
+
+		for _, label := range board.Labels {
+			item.BoardLabels = append(item.BoardLabels, models.ImportLabel{ExternalId: externalID(label.ID), Name: label.Name, Color: label.Color})
+		}
+
+		index[board.ID] = len(boards)
+		boards = append(boards, item)
+	}
+
+	for _, task := range src.Tasks {
+		i, ok := index[task.BoardId]
+		if !ok {
+			return nil, invalid(models.ImportNative, fmt.Errorf("task %d has unknown board %d", task.ID, task.BoardId))
+		}
+
+		item := models.ImportTask{
+			ExternalId:  externalID(task.ID),
+			Title:       task.Title,
+			Description: task.Description,
+			ListId:      externalID(task.StatusId),
+			Priority:    task.Priority,
+			DueAt:       task.DueAt,
+			Estimate:    task.Estimate,
+		}
+
+		if task.Assignee != nil {
+			item.Members = []string{externalID(task.Assignee.ID)}
+		}
+
+		// users in values are ids of members, they are mapped like assignee
+		if len(task.CustomFields) > 0 {
+			item.Fields = make(map[string]any, len(task.CustomFields))
+			for key, value := range task.CustomFields {
+				if user, ok := value.(float64); ok && users[task.BoardId][key] {
+					value = strconv.FormatFloat(user, 'f', -1, 64)
+				}
+				item.Fields[key] = value
+			}
+		}
+
+		for _, label := range task.Labels {
+			item.BoardLabels = append(item.BoardLabels, externalID(label))
+		}
+
+		for _, comment := range task.Comments {
+			var authorID string
+			if comment.UserId != 0 {
+				authorID = externalID(comment.UserId)
+			}
+			item.Comments = append(item.Comments, models.ImportComment{
+				AuthorId:  authorID,
+				Author:    comment.Author,
+				Text:      comment.Text,
+				CreatedAt: comment.CreatedAt,
+			})
+		}
+
+		boards[i].Tasks = append(boards[i].Tasks, item)
+	}
+
+	return boards, nil
+}
+
+func externalID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
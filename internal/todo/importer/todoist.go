@@ -0,0 +1,392 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/models"
+)
+
+// subtasks of Todoist become items of this checklist of their top task
+const todoistSubtasks = "Подзадачи"
+
+// todoistID is string in new backups and number in old ones
+type todoistID string
+
+func (id *todoistID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = todoistID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = todoistID(n.String())
+
+	return nil
+}
+
+// todoistBackup is json of Sync API with projects, sections, items and notes
+type todoistBackup struct {
+	Projects []struct {
+		ID        todoistID `json:"id"`
+		Name      string    `json:"name"`
+		IsDeleted bool      `json:"is_deleted"`
+	} `json:"projects"`
+	Sections []struct {
+		ID        todoistID `json:"id"`
+		ProjectId todoistID `json:"project_id"`
+		Name      string    `json:"name"`
+		Archived  bool      `json:"is_archived"`
+	} `json:"sections"`
+	Items []struct {
+		ID             todoistID `json:"id"`
+		ProjectId      todoistID `json:"project_id"`
+		SectionId      todoistID `json:"section_id"`
+		ParentId       todoistID `json:"parent_id"`
+		Content        string    `json:"content"`
+		Description    string    `json:"description"`
+		Priority       int       `json:"priority"`
+		Labels         []string  `json:"labels"`
+		Checked        bool      `json:"checked"`
+		IsDeleted      bool      `json:"is_deleted"`
+		ResponsibleUid todoistID `json:"responsible_uid"`
+		Due            *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+	} `json:"items"`
+	Notes []struct {
+		ItemId    todoistID `json:"item_id"`
+		PostedUid todoistID `json:"posted_uid"`
+		Content   string    `json:"content"`
+		PostedAt  time.Time `json:"posted_at"`
+		IsDeleted bool      `json:"is_deleted"`
+	} `json:"notes"`
+	Collaborators []struct {
+		ID       todoistID `json:"id"`
+		Email    string    `json:"email"`
+		FullName string    `json:"full_name"`
+	} `json:"collaborators"`
+}
+
+// json backup is a document, everything else is read as csv of one project
+func parseTodoist(data []byte, name string) ([]models.ImportBoard, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseTodoistJSON(data)
+	}
+
+	board, err := parseTodoistCSV(data, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return []models.ImportBoard{*board}, nil
+}
+
+func parseTodoistJSON(data []byte) ([]models.ImportBoard, error) {
+	var src todoistBackup
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, invalid(models.ImportTodoist, err)
+	}
+
+	// collaborators are matched by login of email or by full name
+	var members []models.ImportMember
+	names := make(map[todoistID]string, len(src.Collaborators))
+	for _, user := range src.Collaborators {
+		names[user.ID] = user.FullName
+		var usernames []string
+		if login, _, ok := strings.Cut(user.Email, "@"); ok && login != "" {
+			usernames = append(usernames, login)
+		}
+		if user.FullName != "" {
+			usernames = append(usernames, user.FullName)
+		}
+		members = append(members, models.ImportMember{ExternalId: string(user.ID), Usernames: usernames})
+	}
+
+	boards := make([]models.ImportBoard, 0, len(src.Projects))
+	index := make(map[todoistID]int, len(src.Projects))
+	for _, project := range src.Projects {
+		if project.IsDeleted {
+			continue
+		}
+		index[project.ID] = len(boards)
+		boards = append(boards, models.ImportBoard{ExternalId: string(project.ID), Name: project.Name, Members: members})
+	}
+
+	for _, section := range src.Sections {
+		if i, ok := index[section.ProjectId]; ok {
+			boards[i].Lists = append(boards[i].Lists, models.ImportList{ExternalId: string(section.ID), Name: section.Name, Closed: section.Archived})
+		}
+	}
+
+	comments := make(map[todoistID][]models.ImportComment)
+	for _, note := range src.Notes {
+		if note.IsDeleted {
+			continue
+		}
+		comments[note.ItemId] = append(comments[note.ItemId], models.ImportComment{
+			AuthorId:  string(note.PostedUid),
+			Author:    names[note.PostedUid],
+			Text:      note.Content,
+			CreatedAt: note.PostedAt,
+		})
+	}
+
+	// subtasks go to checklist of their top task, parents come before children in backup
+	parents := make(map[todoistID]todoistID, len(src.Items))
+	tasks := make(map[todoistID]*models.ImportTask)
+	projects := make(map[todoistID]todoistID)
+	var order []todoistID
+	for _, item := range src.Items {
+		if item.IsDeleted {
+			continue
+		}
+		if _, ok := index[item.ProjectId]; !ok {
+			continue
+		}
+
+		if item.ParentId != "" {
+			parents[item.ID] = item.ParentId
+			continue
+		}
+
+		task := &models.ImportTask{
+			ExternalId:  string(item.ID),
+			Title:       item.Content,
+			Description: item.Description,
+			ListId:      string(item.SectionId),
+			Done:        item.Checked,
+			Priority:    todoistPriority(5 - item.Priority), // api has 4 for p1
+			Labels:      item.Labels,
+			Comments:    comments[item.ID],
+		}
+		if item.ResponsibleUid != "" {
+			task.Members = []string{string(item.ResponsibleUid)}
+		}
+		if item.Due != nil {
+			task.DueAt = todoistDate(item.Due.Date)
+		}
+
+		tasks[item.ID] = task
+		projects[item.ID] = item.ProjectId
+		order = append(order, item.ID)
+	}
+
+	for _, item := range src.Items {
+		if item.IsDeleted || item.ParentId == "" {
+			continue
+		}
+
+		root := item.ParentId
+		for parents[root] != "" {
+			root = parents[root]
+		}
+
+		if task, ok := tasks[root]; ok {
+			addSubtask(task, item.Content, item.Checked)
+		}
+	}
+
+	labels := make([]map[string]bool, len(boards))
+	for _, id := range order {
+		task := tasks[id]
+		i := index[projects[id]]
+		boards[i].Tasks = append(boards[i].Tasks, *task)
+
+		if labels[i] == nil {
+			labels[i] = make(map[string]bool)
+		}
+		for _, label := range task.Labels {
+			if !labels[i][label] {
+				labels[i][label] = true
+				boards[i].Labels = append(boards[i].Labels, label)
+			}
+		}
+	}
+
+	return boards, nil
+}
+
+func addSubtask(task *models.ImportTask, text string, done bool) {
+	if len(task.Checklists) == 0 {
+		task.Checklists = append(task.Checklists, models.ImportChecklist{Name: todoistSubtasks})
+	}
+
+	items := &task.Checklists[0].Items
+	*items = append(*items, models.ImportCheckItem{Text: text, Done: done})
+}
+
+// user in csv is written as "Name (id)"
+var todoistUser = regexp.MustCompile(`^(.*?)\s*\((\d+)\)$`)
+
+// labels are kept in content of csv task as @label
+var todoistLabel = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// parseTodoistCSV reads project exported as template: sections, tasks with indent and notes
+func parseTodoistCSV(data []byte, name string) (*models.ImportBoard, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name of board is required for Todoist csv", models.ErrInvalidInput)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, invalid(models.ImportTodoist, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToUpper(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["TYPE"]; !ok {
+		return nil, invalid(models.ImportTodoist, errors.New("column TYPE is missing"))
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return nil, invalid(models.ImportTodoist, errors.New("column CONTENT is missing"))
+	}
+
+	board := &models.ImportBoard{ExternalId: "csv:" + name, Name: name}
+	members := make(map[string]bool)
+	labels := make(map[string]bool)
+	seen := make(map[string]int) // csv has no ids, so tasks are keyed by section and content
+
+	var section string
+	var task *models.ImportTask
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalid(models.ImportTodoist, err)
+		}
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		content := get("CONTENT")
+		switch strings.ToLower(get("TYPE")) {
+		case "section":
+			section = content
+			board.Lists = append(board.Lists, models.ImportList{ExternalId: content, Name: content})
+		case "task":
+			if indent, _ := strconv.Atoi(get("INDENT")); indent > 1 && task != nil {
+				addSubtask(task, content, false)
+				continue
+			}
+
+			title := content
+			var taskLabels []string
+			for _, match := range todoistLabel.FindAllStringSubmatch(content, -1) {
+				taskLabels = append(taskLabels, match[1])
+				if !labels[match[1]] {
+					labels[match[1]] = true
+					board.Labels = append(board.Labels, match[1])
+				}
+			}
+			title = strings.TrimSpace(todoistLabel.ReplaceAllString(title, ""))
+
+			key := section + "\x00" + content
+			seen[key]++
+			sum := sha1.Sum([]byte(key))
+			id := fmt.Sprintf("%s:%s-%d", board.ExternalId, hex.EncodeToString(sum[:10]), seen[key])
+
+			priority, _ := strconv.Atoi(get("PRIORITY"))
+			board.Tasks = append(board.Tasks, models.ImportTask{
+				ExternalId:  id,
+				Title:       title,
+				Description: get("DESCRIPTION"),
+				ListId:      section,
+				Priority:    todoistPriority(priority), // csv has 1 for p1
+				DueAt:       todoistDate(get("DATE")),
+				Labels:      taskLabels,
+			})
+			task = &board.Tasks[len(board.Tasks)-1]
+
+			if member, ok := todoistMember(get("RESPONSIBLE"), board, members); ok {
+				task.Members = []string{member}
+			}
+		case "note":
+			if task == nil {
+				continue
+			}
+
+			comment := models.ImportComment{Text: content}
+			if author := get("AUTHOR"); author != "" {
+				comment.Author = author
+				if member, ok := todoistMember(author, board, members); ok {
+					comment.AuthorId = member
+					comment.Author = todoistUser.FindStringSubmatch(author)[1]
+				}
+			}
+			task.Comments = append(task.Comments, comment)
+		}
+	}
+
+	return board, nil
+}
+
+// todoistMember adds user of csv to members of board and returns its id
+func todoistMember(value string, board *models.ImportBoard, members map[string]bool) (string, bool) {
+	match := todoistUser.FindStringSubmatch(value)
+	if match == nil {
+		return "", false
+	}
+
+	if !members[match[2]] {
+		members[match[2]] = true
+		board.Members = append(board.Members, models.ImportMember{ExternalId: match[2], Usernames: []string{match[1]}})
+	}
+
+	return match[2], true
+}
+
+// p1 is the highest priority of Todoist, p4 is no priority
+func todoistPriority(p int) int {
+	switch p {
+	case 1:
+		return models.PriorityHigh
+	case 2:
+		return models.PriorityMedium
+	case 3:
+		return models.PriorityLow
+	}
+
+	return models.PriorityNone
+}
+
+// only exact dates are imported, recurring dates in words are skipped
+func todoistDate(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
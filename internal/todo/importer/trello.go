@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+	"todo/internal/todo/models"
+)
+
+// trelloBoard is a part of board json from "Menu → Print and export → Export as JSON"
+type trelloBoard struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Labels []trelloLabel `json:"labels"`
+	Lists  []struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+	} `json:"lists"`
+	Cards []struct {
+		ID        string     `json:"id"`
+		Name      string     `json:"name"`
+		Desc      string     `json:"desc"`
+		IdList    string     `json:"idList"`
+		Closed    bool       `json:"closed"`
+		Pos       float64    `json:"pos"`
+		Due       *time.Time `json:"due"`
+		IdMembers []string   `json:"idMembers"`
+		IdLabels  []string   `json:"idLabels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IdCard     string  `json:"idCard"`
+		Name       string  `json:"name"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+	Actions []struct {
+		Type string    `json:"type"`
+		Date time.Time `json:"date"`
+		Data struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+		MemberCreator struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"memberCreator"`
+	} `json:"actions"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// labels without name are shown by color in Trello
+func (l trelloLabel) title() string {
+	if name := strings.TrimSpace(l.Name); name != "" {
+		return name
+	}
+
+	return l.Color
+}
+
+func parseTrello(data []byte) ([]models.ImportBoard, error) {
+	var src trelloBoard
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, invalid(models.ImportTrello, err)
+	}
+
+	if src.ID == "" {
+		return nil, invalid(models.ImportTrello, errNoID)
+	}
+
+	board := models.ImportBoard{ExternalId: src.ID, Name: src.Name}
+
+	for _, member := range src.Members {
+		board.Members = append(board.Members, models.ImportMember{ExternalId: member.ID, Usernames: []string{member.Username}})
+	}
+
+	labels := make(map[string]string, len(src.Labels))
+	for _, label := range src.Labels {
+		if title := label.title(); title != "" {
+			labels[label.ID] = title
+			board.Labels = append(board.Labels, title)
+		}
+	}
+
+	// tasks are created in order of lists and cards on board
+	sort.SliceStable(src.Lists, func(i, j int) bool { return src.Lists[i].Pos < src.Lists[j].Pos })
+	order := make(map[string]int, len(src.Lists))
+	for i, list := range src.Lists {
+		order[list.ID] = i
+		board.Lists = append(board.Lists, models.ImportList{ExternalId: list.ID, Name: list.Name, Closed: list.Closed})
+	}
+
+	sort.SliceStable(src.Cards, func(i, j int) bool {
+		a, b := src.Cards[i], src.Cards[j]
+		if order[a.IdList] != order[b.IdList] {
+			return order[a.IdList] < order[b.IdList]
+		}
+		return a.Pos < b.Pos
+	})
+
+	sort.SliceStable(src.Checklists, func(i, j int) bool { return src.Checklists[i].Pos < src.Checklists[j].Pos })
+	checklists := make(map[string][]models.ImportChecklist)
+	for _, checklist := range src.Checklists {
+		sort.SliceStable(checklist.CheckItems, func(i, j int) bool { return checklist.CheckItems[i].Pos < checklist.CheckItems[j].Pos })
+
+		list := models.ImportChecklist{Name: checklist.Name}
+		for _, item := range checklist.CheckItems {
+			list.Items = append(list.Items, models.ImportCheckItem{Text: item.Name, Done: item.State == "complete"})
+		}
+		checklists[checklist.IdCard] = append(checklists[checklist.IdCard], list)
+	}
+
+	// actions of export are the newest first
+	comments := make(map[string][]models.ImportComment)
+	for i := len(src.Actions) - 1; i >= 0; i-- {
+		action := src.Actions[i]
+		if action.Type != "commentCard" {
+			continue
+		}
+
+		comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], models.ImportComment{
+			AuthorId:  action.MemberCreator.ID,
+			Author:    action.MemberCreator.Username,
+			Text:      action.Data.Text,
+			CreatedAt: action.Date,
+		})
+	}
+
+	for _, card := range src.Cards {
+		task := models.ImportTask{
+			ExternalId:  card.ID,
+			Title:       card.Name,
+			Description: card.Desc,
+			ListId:      card.IdList,
+			Closed:      card.Closed,
+			DueAt:       card.Due,
+			Members:     card.IdMembers,
+			Checklists:  checklists[card.ID],
+			Comments:    comments[card.ID],
+		}
+
+		for _, id := range card.IdLabels {
+			if title, ok := labels[id]; ok {
+				task.Labels = append(task.Labels, title)
+			}
+		}
+
+		board.Tasks = append(board.Tasks, task)
+	}
+
+	return []models.ImportBoard{board}, nil
+}
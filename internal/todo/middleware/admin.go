@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"todo/internal/todo/config"
+)
+
+// middleware for admin routes, must be used after JWT
+func Admin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("user_id").(uint)
+		if !ok || !slices.Contains(config.AppConfig.AdminIDs, userID) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
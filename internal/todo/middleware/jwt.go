@@ -20,6 +20,16 @@ type Claims struct {
 
 // middleware for Access token check
 func JWT(next http.Handler) http.Handler {
+	return jwtMiddleware(next, false)
+}
+
+// JWTQuery is JWT which also takes token from ?access_token=,
+// browsers can't set headers when they open WebSocket
+func JWTQuery(next http.Handler) http.Handler {
+	return jwtMiddleware(next, true)
+}
+
+func jwtMiddleware(next http.Handler, allowQuery bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// logger init
 		zapLog, err := logger.New(config.AppConfig.LogLevel)
@@ -31,6 +41,9 @@ func JWT(next http.Handler) http.Handler {
 
 		// extract token from header Authorization
 		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" && allowQuery && r.URL.Query().Get("access_token") != "" {
+			authHeader = "Bearer " + r.URL.Query().Get("access_token")
+		}
 		if authHeader == "" {
 			http.Error(w, "token is missing", http.StatusUnauthorized)
 			return
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"todo/internal/todo/config"
+	"todo/pkg/signature"
+)
+
+var (
+	verifyOnce sync.Once
+	verify     func(http.Handler) http.Handler
+
+	// set at start, so nonces are shared by all instances of todo
+	nonces signature.NonceStore
+)
+
+// UseNonceStore makes signed routes remember nonces in store, memory of the process is used without it
+func UseNonceStore(store signature.NonceStore) {
+	nonces = store
+}
+
+// middleware for internal routes called by tg service, checks HMAC signature
+func Signed(next http.Handler) http.Handler {
+	// config is loaded at start, so verifier with its replay cache is created on first use
+	verifyOnce.Do(func() {
+		if nonces == nil {
+			verify = signature.Verify(config.AppConfig.InternalSecret, signature.DefaultWindow)
+			return
+		}
+		verify = signature.VerifyWith(config.AppConfig.InternalSecret, signature.DefaultWindow, nonces)
+	})
+
+	return verify(next)
+}
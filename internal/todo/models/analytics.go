@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TaskFlow is task of board with its status history, analytics is computed from it
+type TaskFlow struct {
+	ID            uint
+	StatusId      uint
+	Estimate      *float64
+	CreatedAt     time.Time
+	WorkStartedAt *time.Time     // start of the first time entry, nil when time wasn't tracked
+	History       []StatusChange // oldest first
+}
+
+// BoardWeekStats is numbers of board for daily digest
+type BoardWeekStats struct {
+	BoardId       uint
+	BoardName     string
+	EstimateUnit  string
+	Created       int
+	Completed     int
+	Estimate      float64 // of completed tasks
+	LeadTimeHours float64 // average of completed tasks
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Attachment is file of task, content is kept in blob store by StorageKey
+type Attachment struct {
+	ID          uint
+	TaskId      uint
+	UserId      uint
+	Filename    string
+	ContentType string
+	Size        int64
+	Sha256      string // hex
+	StorageKey  string
+	CreatedAt   time.Time
+}
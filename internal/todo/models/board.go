@@ -3,8 +3,11 @@ package models
 import "time"
 
 type Board struct {
-	ID        uint
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uint
+	Name         string
+	OwnerId      uint
+	ChatId       *int64 // telegram group linked to board
+	EstimateUnit string // unit of task estimates: points or hours
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
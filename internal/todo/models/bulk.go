@@ -0,0 +1,30 @@
+package models
+
+// bulk operations on tasks
+const (
+	BulkStatus      = "status"
+	BulkAssign      = "assign"
+	BulkMove        = "move"
+	BulkDelete      = "delete"
+	BulkAddLabel    = "add_label"
+	BulkRemoveLabel = "remove_label"
+)
+
+var BulkOps = []string{
+	BulkStatus,
+	BulkAssign,
+	BulkMove,
+	BulkDelete,
+	BulkAddLabel,
+	BulkRemoveLabel,
+}
+
+// BulkOperation is a checked operation of bulk request
+type BulkOperation struct {
+	Op       string
+	TaskId   uint
+	StatusId uint          // for status
+	UserId   uint          // for assign
+	Transfer *TaskTransfer // for move
+	LabelId  uint          // for add_label and remove_label
+}
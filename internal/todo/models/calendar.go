@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// CalendarToken is a secret of calendar feed url of user
+type CalendarToken struct {
+	UserId    uint
+	Token     string
+	CreatedAt time.Time
+}
+
+// CalendarTask is task with due date for calendar feed
+type CalendarTask struct {
+	Task
+	BoardName string
+	Status    string
+	Assignee  string // username
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Comment struct {
+	ID        uint
+	TaskId    uint
+	UserId    uint
+	Text      string
+	CreatedAt time.Time
+}
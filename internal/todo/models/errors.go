@@ -0,0 +1,12 @@
+package models
+
+import "errors"
+
+var (
+	ErrLinkCodeInvalid = errors.New("link code is invalid or expired")
+	ErrForbidden       = errors.New("access denied")
+	ErrNotFound        = errors.New("not found")
+	ErrTgNotLinked     = errors.New("telegram account is not linked")
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrTooLarge        = errors.New("too large")
+)
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// BoardEvent is an entry of board events log, payload is the same as in webhook request
+type BoardEvent struct {
+	ID        uint
+	BoardId   uint
+	Event     string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// Viewer is a user with open connection to board
+type Viewer struct {
+	UserId   uint
+	Username string
+}
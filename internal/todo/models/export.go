@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// version of json export, it is raised on incompatible changes
+const ExportVersion = 1
+
+// Export is everything exported from boards, tasks are streamed by Each
+// in order of boards, by status and id
+type Export struct {
+	Statuses []Status
+	Boards   []ExportBoard
+	Each     func(fn func(task ExportTask) error) error
+}
+
+type ExportBoard struct {
+	Board
+	Members []ExportUser
+	Fields  []BoardField
+	Labels  []BoardLabel
+}
+
+type ExportUser struct {
+	ID       uint
+	Username string
+}
+
+type ExportTask struct {
+	Task
+	Assignee string // username
+	Comments []ExportComment
+}
+
+type ExportComment struct {
+	UserId    uint
+	Author    string
+	Text      string
+	CreatedAt time.Time
+}
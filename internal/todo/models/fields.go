@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// types of custom fields
+const (
+	FieldText        = "text"
+	FieldNumber      = "number"
+	FieldDate        = "date" // YYYY-MM-DD
+	FieldSelect      = "select"
+	FieldMultiSelect = "multi_select"
+	FieldUser        = "user" // id of board member
+	FieldURL         = "url"
+)
+
+var FieldTypes = []string{FieldText, FieldNumber, FieldDate, FieldSelect, FieldMultiSelect, FieldUser, FieldURL}
+
+// BoardField is custom field of tasks on board, values are kept in task by field id
+type BoardField struct {
+	ID        uint
+	BoardId   uint
+	Name      string
+	Type      string
+	Options   []string // for select and multi_select
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,120 @@
+package models
+
+import "time"
+
+// sources of import
+const (
+	ImportTrello  = "trello"
+	ImportTodoist = "todoist"
+	ImportNative  = "todo" // json export of this service
+)
+
+var ImportSources = []string{ImportTrello, ImportTodoist, ImportNative}
+
+// kinds of imported items
+const (
+	ImportedBoard = "board"
+	ImportedTask  = "task"
+)
+
+// imported labels are kept in multi_select field of board with this name
+const ImportLabelsField = "Метки"
+
+// ImportBoard is board parsed from export of another service, ids are ids of that service
+type ImportBoard struct {
+	ExternalId   string
+	Name         string
+	EstimateUnit string // unit of a new board, points when empty
+	Lists        []ImportList
+	Members      []ImportMember
+	Labels       []string // go to options of labels field
+	Fields       []ImportField
+	BoardLabels  []ImportLabel // go to labels of board
+	Tasks        []ImportTask
+}
+
+// ImportList is a column of board, lists of Trello or sections of Todoist
+type ImportList struct {
+	ExternalId string
+	Name       string
+	Closed     bool
+}
+
+// ImportField is custom field of board, only our own export has them
+type ImportField struct {
+	ExternalId string
+	Name       string
+	Type       string
+	Options    []string
+}
+
+type ImportLabel struct {
+	ExternalId string
+	Name       string
+	Color      string
+}
+
+type ImportMember struct {
+	ExternalId string
+	Usernames  []string // candidates to match users, the first is shown in report
+}
+
+type ImportTask struct {
+	ExternalId  string
+	Title       string
+	Description string
+	ListId      string
+	Closed      bool // archived card
+	Done        bool // completed task
+	Priority    int
+	DueAt       *time.Time
+	Estimate    *float64
+	Members     []string // external ids of members, the first matched becomes assignee
+	Labels      []string
+	Fields      map[string]any // values by external ids of fields, users are external ids of members
+	BoardLabels []string       // external ids of labels of board
+	Checklists  []ImportChecklist
+	Comments    []ImportComment
+}
+
+type ImportChecklist struct {
+	Name  string
+	Items []ImportCheckItem
+}
+
+type ImportCheckItem struct {
+	Text string
+	Done bool
+}
+
+type ImportComment struct {
+	AuthorId  string // external id of member
+	Author    string // name to show when member isn't matched
+	Text      string
+	CreatedAt time.Time
+}
+
+// ImportPlan is board prepared for import, statuses and users are mapped to ours
+type ImportPlan struct {
+	Source       string
+	ExternalId   string
+	BoardId      uint // board of previous import, 0 creates a new board
+	Name         string
+	EstimateUnit string
+	Members      []uint
+	LabelsId     uint         // labels field of board, 0 creates it when there are labels
+	Labels       []string     // all options of labels field
+	Fields       []BoardField // fields to create, values go to fields with the same name
+	BoardLabels  []BoardLabel // labels to create, tasks get labels with the same name
+	Tasks        []ImportTaskPlan
+}
+
+// ImportTaskPlan is task with values of fields and labels by their names, ids of new ones are known only on import
+type ImportTaskPlan struct {
+	ExternalId  string
+	Task        Task
+	Labels      []string
+	FieldValues map[string]any // by lower name of field
+	LabelNames  []string
+	Comments    []Comment
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// BoardLabel is label of tasks on board, tasks keep ids of their labels
+type BoardLabel struct {
+	ID        uint
+	BoardId   uint
+	Name      string
+	Color     string // #rrggbb or empty
+	CreatedAt time.Time
+}
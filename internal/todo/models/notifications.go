@@ -0,0 +1,56 @@
+package models
+
+// kinds of personal notifications which user can turn off
+const (
+	NotifyCreated       = "created"
+	NotifyAssigned      = "assigned"
+	NotifyCommented     = "commented"
+	NotifyDue           = "due"
+	NotifyStatusChanged = "status_changed"
+	NotifyDigest        = "digest"
+)
+
+// channels of personal notifications
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+)
+
+type NotificationSettings struct {
+	UserId        uint
+	Created       bool
+	Assigned      bool
+	Commented     bool
+	Due           bool
+	StatusChanged bool
+	Digest        bool
+	QuietFrom     *int // minutes from midnight
+	QuietTo       *int
+	Timezone      string
+	MutedBoards   []uint
+
+	// channels by kind of notification, telegram when kind is missing
+	Channels      map[string][]string
+	Email         string
+	WebhookURL    string
+	WebhookSecret string
+	// letters go only to confirmed email
+	EmailConfirmed bool
+
+	DigestAnalytics bool // weekly numbers of boards in daily digest
+}
+
+// DefaultNotificationSettings are used until user changes them
+func DefaultNotificationSettings(userID uint) NotificationSettings {
+	return NotificationSettings{
+		UserId:        userID,
+		Created:       true,
+		Assigned:      true,
+		Commented:     true,
+		Due:           true,
+		StatusChanged: true,
+		Digest:        true,
+		Timezone:      "UTC",
+	}
+}
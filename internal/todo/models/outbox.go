@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// outbox message statuses
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxSkipped = "skipped"
+	OutboxDead    = "dead"
+	OutboxHeld    = "held" // personal notification is held till the end of quiet hours
+)
+
+// outbox event types
+const (
+	EventTaskCreated       = "task.created"
+	EventTaskReminder      = "task.reminder"
+	EventTaskStatusChanged = "task.status_changed"
+	EventCommentCreated    = "comment.created"
+	EventTaskAssigned      = "task.assigned"
+	EventTaskDue           = "task.due"
+	EventSummary           = "notification.summary"
+	EventDigest            = "digest"
+	EventDeliver           = "notification.deliver"
+	EventTasksBulk         = "tasks.bulk" // changes of bulk request, one message per recipient
+	EventEmailConfirm      = "email.confirm"
+)
+
+type OutboxMessage struct {
+	ID             uint
+	EventType      string
+	IdempotencyKey string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
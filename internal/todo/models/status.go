@@ -1,6 +1,32 @@
 package models
 
+import "time"
+
+// statuses created by migrations
+const (
+	StatusInProcess = 1
+	StatusDone      = 2
+	StatusArchived  = 3
+)
+
+// task is completed in these statuses
+var DoneStatuses = []uint{StatusDone, StatusArchived}
+
+// units of task estimates
+const (
+	EstimatePoints = "points"
+	EstimateHours  = "hours"
+)
+
 type Status struct {
 	ID   uint
 	Type string
 }
+
+// StatusChange is an entry of task status history, FromStatusId is nil when task was created
+type StatusChange struct {
+	TaskId       uint
+	FromStatusId *uint
+	ToStatusId   uint
+	ChangedAt    time.Time
+}
@@ -2,6 +2,14 @@ package models
 
 import "time"
 
+// task priorities
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
 type Task struct {
 	ID          uint
 	Title       string
@@ -9,6 +17,14 @@ type Task struct {
 	BoardId     uint
 	StatusId    uint
 	UserId      uint
+	Priority    int
+	DueAt       *time.Time
+	Estimate    *float64 // in estimate unit of board
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	TrackedSeconds int64 // total of time entries, running timers are counted up to now
+
+	CustomFields map[string]any // values of board fields by field id
+	Labels       []uint         // ids of board labels
 }
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// BoardTemplate is a snapshot of board to create new boards from it
+type BoardTemplate struct {
+	ID           uint
+	OwnerId      uint
+	Name         string
+	EstimateUnit string
+	Statuses     []string // names of statuses used by tasks
+	Fields       []TemplateField
+	Labels       []TemplateLabel
+	Tasks        []TemplateTask
+	CreatedAt    time.Time
+}
+
+type TemplateField struct {
+	Name     string
+	Type     string
+	Options  []string
+	Position int
+}
+
+type TemplateLabel struct {
+	Name  string
+	Color string
+}
+
+// TemplateTask is task of board template, values of fields and labels are kept by name
+type TemplateTask struct {
+	Title        string
+	Description  string
+	Status       string
+	Priority     int
+	Estimate     *float64
+	DueInMinutes *int // due date relative to creation of task
+	Fields       map[string]any
+	Labels       []string
+}
+
+// TaskTemplate is task of board which can be created again and again
+type TaskTemplate struct {
+	ID           uint
+	BoardId      uint
+	AuthorId     uint
+	Name         string
+	Title        string
+	Description  string
+	Priority     int
+	Estimate     *float64
+	DueInMinutes *int
+	CustomFields map[string]any // by field id, as in task
+	Labels       []uint         // ids of board labels
+	CreatedAt    time.Time
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// groupings of time report
+const (
+	TimeGroupUser  = "user"
+	TimeGroupBoard = "board"
+	TimeGroupDay   = "day"
+)
+
+var TimeGroups = []string{TimeGroupUser, TimeGroupBoard, TimeGroupDay}
+
+// TimeEntry is time spent by user on task, EndedAt is nil while timer is running
+type TimeEntry struct {
+	ID        uint
+	TaskId    uint
+	UserId    uint
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Note      string
+	CreatedAt time.Time
+}
+
+// TimeReportRow is tracked time of one group, fields which are not grouped by are zero
+type TimeReportRow struct {
+	UserId    uint
+	Username  string
+	BoardId   uint
+	BoardName string
+	Day       string // YYYY-MM-DD in timezone of report
+	Seconds   int64
+}
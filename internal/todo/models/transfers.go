@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// kinds of transfers
+const (
+	TransferMove  = "move"  // task moved to another board
+	TransferCopy  = "copy"  // task copied, also tasks of cloned board
+	TransferClone = "clone" // board cloned
+)
+
+// Transfer is an entry of history of moves and copies, zero ids are unknown or deleted
+type Transfer struct {
+	ID           uint
+	Kind         string
+	TaskId       uint
+	SourceTaskId uint
+	FromBoardId  uint
+	ToBoardId    uint
+	ActorId      uint
+	CreatedAt    time.Time
+}
+
+// TaskTransfer is a task prepared for another board
+type TaskTransfer struct {
+	BoardId      uint
+	StatusId     uint
+	UserId       uint
+	CustomFields map[string]any
+	Labels       []uint
+	Comments     bool // copy comments, moved task keeps them anyway
+}
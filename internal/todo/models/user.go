@@ -8,4 +8,7 @@ type User struct {
 	TgName       string
 	PasswordHash []byte
 	CreatedAt    time.Time
+	TgUserID     *int64     // nil when telegram is not linked
+	TgActive     bool       // false after user blocked bot or deleted chat
+	TgInactiveAt *time.Time // when telegram link became inactive
 }
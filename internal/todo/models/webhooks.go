@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// board events sent to webhooks and subscribers of board
+const (
+	BoardEventTaskCreated   = "task.created"
+	BoardEventTaskUpdated   = "task.updated"
+	BoardEventTaskMoved     = "task.moved" // board or status of task changed
+	BoardEventTaskDeleted   = "task.deleted"
+	BoardEventMemberAdded   = "member.added"
+	BoardEventMemberRemoved = "member.removed"
+	BoardEventBoardUpdated  = "board.updated"
+)
+
+var BoardEvents = []string{
+	BoardEventTaskCreated,
+	BoardEventTaskUpdated,
+	BoardEventTaskMoved,
+	BoardEventTaskDeleted,
+	BoardEventMemberAdded,
+	BoardEventMemberRemoved,
+	BoardEventBoardUpdated,
+}
+
+type Webhook struct {
+	ID        uint
+	BoardId   uint
+	URL       string
+	Secret    string
+	Events    []string // empty means all events
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is one request to webhook, statuses are the same as in outbox
+type WebhookDelivery struct {
+	ID             uint
+	WebhookId      uint
+	Guid           string
+	RedeliveryOf   *uint
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	ResponseStatus *int
+	ResponseBody   string
+	DurationMs     *int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"todo/internal/todo/dto"
+)
+
+// WeeklyText is a section of daily digest with numbers of boards for the last week
+func WeeklyText(weekly []dto.DigestBoardStatsDto) string {
+	var sb strings.Builder
+
+	sb.WriteString("За неделю:\n")
+	for _, board := range weekly {
+		fmt.Fprintf(&sb, "• %s: создано %d, выполнено %d", board.BoardName, board.Created, board.Completed)
+		if board.Estimate > 0 {
+			fmt.Fprintf(&sb, " (%g %s)", board.Estimate, board.EstimateUnit)
+		}
+		if board.Completed > 0 {
+			fmt.Fprintf(&sb, ", среднее время выполнения %.1f ч", board.LeadTimeHours)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+)
+
+// the whole SMTP session of one letter, slow server fails delivery instead of holding outbox
+const emailTimeout = 30 * time.Second
+
+// Email sends notifications by SMTP
+type Email struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+// NewEmail creates SMTP notifier, auth is used only when user is set
+func NewEmail(host string, port string, user string, password string, from string) *Email {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return &Email{
+		addr:    net.JoinHostPort(host, port),
+		host:    host,
+		auth:    auth,
+		from:    from,
+		timeout: emailTimeout,
+	}
+}
+
+func (e *Email) Notify(to Recipient, n dto.NotificationDto, idempotencyKey string) error {
+	if to.Email == "" {
+		return ErrNoAddress
+	}
+
+	return e.send(to.Email, e.message(to.Email, n, idempotencyKey))
+}
+
+// send does the same as smtp.SendMail, but dial and the whole session have deadline
+func (e *Email) send(to string, msg []byte) error {
+	dialer := net.Dialer{Timeout: e.timeout}
+	conn, err := dialer.Dial("tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(e.timeout)); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+
+	if e.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp server doesn't support AUTH")
+		}
+		if err := c.Auth(e.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message builds plain text utf-8 letter
+func (e *Email) message(to string, n dto.NotificationDto, idempotencyKey string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	// mail clients can drop repeated letters by Message-ID
+	fmt.Fprintf(&buf, "Message-ID: <%s@todo>\r\n", strings.NewReplacer(":", ".", " ", "").Replace(idempotencyKey))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(n.Text, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
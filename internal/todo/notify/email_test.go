@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"todo/internal/todo/dto"
+)
+
+// smtpServer is local stand-in of mail server, it accepts one letter per connection
+type smtpServer struct {
+	ln      net.Listener
+	letters chan smtpLetter
+}
+
+type smtpLetter struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpServer{ln: ln, letters: make(chan smtpLetter, 1)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+
+	return s
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *smtpServer) session(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	var letter smtpLetter
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			letter.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			letter.to = append(letter.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			letter.data = data.String()
+			s.letters <- letter
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestEmailNotify(t *testing.T) {
+	srv := newSMTPServer(t)
+	host, port, _ := net.SplitHostPort(srv.ln.Addr().String())
+
+	e := NewEmail(host, port, "", "", "todo@example.com")
+	n := dto.NotificationDto{Subject: "Вам назначена задача", Text: "#1 Купить молоко\nдо пятницы"}
+
+	if err := e.Notify(Recipient{Email: "anna@example.com"}, n, "outbox:1:email"); err != nil {
+		t.Fatal(err)
+	}
+
+	letter := <-srv.letters
+	if letter.from != "todo@example.com" || len(letter.to) != 1 || letter.to[0] != "anna@example.com" {
+		t.Errorf("envelope %+v", letter)
+	}
+
+	headers, body, ok := strings.Cut(letter.data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("letter without body: %q", letter.data)
+	}
+
+	for _, want := range []string{
+		"To: anna@example.com",
+		"Subject: =?utf-8?q?",
+		"Message-ID: <outbox.1.email@todo>",
+		"Content-Type: text/plain; charset=utf-8",
+	} {
+		if !strings.Contains(headers, want) {
+			t.Errorf("headers miss %q:\n%s", want, headers)
+		}
+	}
+
+	if body != "#1 Купить молоко\r\nдо пятницы\r\n" {
+		t.Errorf("body %q", body)
+	}
+
+	if err := e.Notify(Recipient{}, n, "outbox:2:email"); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("without email: %v", err)
+	}
+}
+
+// server which accepts connection and never answers fails delivery by timeout
+func TestEmailTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	e := NewEmail(host, port, "", "", "todo@example.com")
+	e.timeout = 200 * time.Millisecond
+
+	start := time.Now()
+	err = e.Notify(Recipient{Email: "anna@example.com"}, dto.NotificationDto{Subject: "s", Text: "t"}, "outbox:1:email")
+	if err == nil {
+		t.Fatal("letter is sent to silent server")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("notify took %v", elapsed)
+	}
+}
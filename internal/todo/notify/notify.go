@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"errors"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+)
+
+// ErrNoAddress means user has no address for the channel, notification is skipped
+var ErrNoAddress = errors.New("recipient has no address for channel")
+
+// ErrBlocked means telegram chat doesn't accept messages: bot is blocked, chat is deleted or bot left the group
+var ErrBlocked = errors.New("telegram chat is blocked")
+
+// Recipient holds addresses of user in all channels
+type Recipient struct {
+	UserId        uint
+	ChatId        *int64
+	Email         string
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// Has checks recipient has address for channel
+func (r Recipient) Has(channel string) bool {
+	switch channel {
+	case models.ChannelTelegram:
+		return r.ChatId != nil
+	case models.ChannelEmail:
+		return r.Email != ""
+	case models.ChannelWebhook:
+		return r.WebhookURL != ""
+	}
+
+	return false
+}
+
+// Notifier delivers notification through one channel.
+// Idempotency key is the same for retries of one delivery
+type Notifier interface {
+	Notify(to Recipient, n dto.NotificationDto, idempotencyKey string) error
+}
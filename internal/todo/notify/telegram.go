@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"todo/internal/todo/api"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+)
+
+// Telegram sends notifications through tg service, task events get cards with buttons
+type Telegram struct{}
+
+func NewTelegram() *Telegram {
+	return &Telegram{}
+}
+
+func (t *Telegram) Notify(to Recipient, n dto.NotificationDto, idempotencyKey string) error {
+	err := t.send(to, n, idempotencyKey)
+
+	// tg service answers 410 when telegram refused the chat
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusGone {
+		return fmt.Errorf("%w: %v", ErrBlocked, err)
+	}
+
+	return err
+}
+
+func (t *Telegram) send(to Recipient, n dto.NotificationDto, idempotencyKey string) error {
+	if to.ChatId == nil {
+		return ErrNoAddress
+	}
+	chatID := *to.ChatId
+
+	switch n.Event {
+	case models.EventTaskCreated, models.EventTaskReminder, models.EventTaskAssigned, models.EventTaskDue, models.EventTaskStatusChanged:
+		var event dto.TaskEventDto
+		if err := json.Unmarshal(n.Payload, &event); err != nil {
+			return err
+		}
+
+		switch n.Event {
+		case models.EventTaskCreated:
+			return api.Create(event, chatID, idempotencyKey)
+		case models.EventTaskReminder:
+			return api.Remind(event, chatID, idempotencyKey)
+		case models.EventTaskAssigned:
+			return api.Assigned(event, chatID, idempotencyKey)
+		case models.EventTaskDue:
+			return api.Due(event, chatID, idempotencyKey)
+		default:
+			return api.StatusChanged(event, chatID, idempotencyKey)
+		}
+	case models.EventCommentCreated:
+		var event dto.CommentEventDto
+		if err := json.Unmarshal(n.Payload, &event); err != nil {
+			return err
+		}
+
+		return api.Comment(event, chatID, idempotencyKey)
+	case models.EventDigest:
+		var event dto.DigestEventDto
+		if err := json.Unmarshal(n.Payload, &event); err != nil {
+			return err
+		}
+
+		if err := api.SendDailyReports(digestTasks(event.Current), chatID, 1, idempotencyKey+":current"); err != nil {
+			return err
+		}
+
+		if err := api.SendDailyReports(digestTasks(event.Done), chatID, 2, idempotencyKey+":done"); err != nil {
+			return err
+		}
+
+		if len(event.Weekly) == 0 {
+			return nil
+		}
+
+		return api.Notify(WeeklyText(event.Weekly), chatID, idempotencyKey+":weekly")
+	}
+
+	return api.Notify(n.Text, chatID, idempotencyKey)
+}
+
+func digestTasks(tasks []dto.DigestTaskDto) []models.Task {
+	res := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		res = append(res, models.Task{
+			ID:          task.TaskId,
+			Title:       task.Title,
+			Description: task.Description,
+			StatusId:    task.StatusId,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/pkg/safehttp"
+	"todo/pkg/signature"
+)
+
+// Webhook posts notifications as JSON signed with secret of user
+type Webhook struct {
+	client *http.Client
+}
+
+type webhookBody struct {
+	UserId uint `json:"user_id"`
+	dto.NotificationDto
+}
+
+func NewWebhook() *Webhook {
+	// url is given by user, so internal addresses are refused when connecting
+	return &Webhook{client: safehttp.NewClient(10 * time.Second)}
+}
+
+func (h *Webhook) Notify(to Recipient, n dto.NotificationDto, idempotencyKey string) error {
+	if to.WebhookURL == "" {
+		return ErrNoAddress
+	}
+
+	body, err := json.Marshal(webhookBody{UserId: to.UserId, NotificationDto: n})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, to.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	// receiver checks X-Signature the same way as internal routes do
+	signature.Sign(req, body, to.WebhookSecret)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook responded %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
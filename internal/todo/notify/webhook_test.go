@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo/internal/todo/dto"
+	"todo/pkg/safehttp"
+	"todo/pkg/signature"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var (
+		got    webhookBody
+		header http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		header = r.Header.Clone()
+
+		// receiver checks signature the same way as internal routes do
+		want := signature.Compute("secret", r.Header.Get(signature.HeaderTimestamp), r.Header.Get(signature.HeaderNonce), r.Method, r.URL.RequestURI(), body)
+		if r.Header.Get(signature.HeaderSignature) != want {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+
+		json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// test server listens on loopback, which the default client refuses
+	h := &Webhook{client: srv.Client()}
+	to := Recipient{UserId: 7, WebhookURL: srv.URL + "/hook?x=1", WebhookSecret: "secret"}
+	n := dto.NotificationDto{Kind: "assigned", Subject: "Вам назначена задача", Text: "#1 Купить молоко"}
+
+	if err := h.Notify(to, n, "outbox:1:webhook"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.UserId != 7 || got.Subject != n.Subject || got.Text != n.Text {
+		t.Errorf("body %+v", got)
+	}
+	if header.Get("Idempotency-Key") != "outbox:1:webhook" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers %v", header)
+	}
+
+	// wrong secret is answered by receiver with 401, body of answer is in error
+	to.WebhookSecret = "other"
+	err := h.Notify(to, n, "outbox:2:webhook")
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "bad signature") {
+		t.Errorf("rejected delivery: %v", err)
+	}
+
+	if err := h.Notify(Recipient{UserId: 7}, n, "outbox:3:webhook"); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("without url: %v", err)
+	}
+}
+
+func TestWebhookRefusesInternalAddress(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	err := NewWebhook().Notify(Recipient{WebhookURL: srv.URL, WebhookSecret: "secret"}, dto.NotificationDto{}, "key")
+	if !errors.Is(err, safehttp.ErrForbiddenAddress) || called {
+		t.Fatalf("webhook to loopback: %v, called %v", err, called)
+	}
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const analyticsDefaultDays = 30
+
+// AnalyticsService computes board analytics from status history of its tasks.
+// Task is completed when it gets done or archived status, lead time counts from creation of task,
+// cycle time from the first tracked time on it
+type AnalyticsService struct {
+	storage AnalyticsStorager
+}
+
+type AnalyticsStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetStatuses() ([]models.Status, error)
+	GetTaskFlows(boardID uint) ([]models.TaskFlow, error)
+}
+
+func NewAnalyticsService(stor AnalyticsStorager, logger *zap.Logger) *AnalyticsService {
+	return &AnalyticsService{
+		storage: stor,
+	}
+}
+
+// analytics of tasks which are on board now, by default for the last 30 days
+func (t *AnalyticsService) GetBoardAnalytics(boardID uint, filter dto.AnalyticsFilterDto, userID uint) (*dto.BoardAnalyticsDto, error) {
+	from, to, timezone, err := parseDateRange(filter.From, filter.To, filter.Timezone, analyticsDefaultDays)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	statuses, err := t.storage.GetStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := t.storage.GetTaskFlows(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	days := analyticsDays(from, to)
+
+	var lead, cycle []float64
+	for i := range tasks {
+		completed := completedAt(&tasks[i])
+		if completed == nil || completed.Before(from) || !completed.Before(to) {
+			continue
+		}
+
+		lead = append(lead, completed.Sub(tasks[i].CreatedAt).Hours())
+		if started := tasks[i].WorkStartedAt; started != nil && started.Before(*completed) {
+			cycle = append(cycle, completed.Sub(*started).Hours())
+		}
+	}
+
+	return &dto.BoardAnalyticsDto{
+		BoardId:        boardID,
+		From:           from,
+		To:             to,
+		Timezone:       timezone,
+		EstimateUnit:   board.EstimateUnit,
+		LeadTime:       durationStats(lead),
+		CycleTime:      durationStats(cycle),
+		Throughput:     throughput(tasks, from, to),
+		CumulativeFlow: cumulativeFlow(tasks, statuses, days, now),
+		Burndown:       burndown(tasks, from, to, days, now),
+	}, nil
+}
+
+// analyticsDay is a day of range, End is limited by the end of range
+type analyticsDay struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// days of range in its timezone
+func analyticsDays(from time.Time, to time.Time) []analyticsDay {
+	var days []analyticsDay
+
+	start := from
+	for start.Before(to) {
+		end := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+		if end.After(to) {
+			end = to
+		}
+
+		days = append(days, analyticsDay{Name: start.Format(time.DateOnly), Start: start, End: end})
+		start = end
+	}
+
+	return days
+}
+
+func isDone(statusID uint) bool {
+	return slices.Contains(models.DoneStatuses, statusID)
+}
+
+// completedAt returns when task got done the last time, nil when it isn't done now
+func completedAt(task *models.TaskFlow) *time.Time {
+	if !isDone(task.StatusId) {
+		return nil
+	}
+
+	var completed *time.Time
+	for i := range task.History {
+		change := task.History[i]
+		// move from done to archived doesn't complete task again
+		if isDone(change.ToStatusId) && (change.FromStatusId == nil || !isDone(*change.FromStatusId)) {
+			completed = &task.History[i].ChangedAt
+		}
+	}
+
+	return completed
+}
+
+// statusAt returns status of task at given time, false when task wasn't created yet
+func statusAt(task *models.TaskFlow, at time.Time) (uint, bool) {
+	if task.CreatedAt.After(at) {
+		return 0, false
+	}
+
+	if len(task.History) == 0 {
+		return task.StatusId, true
+	}
+
+	// history can start a bit later than creation
+	first := task.History[0]
+	if first.ChangedAt.After(at) {
+		if first.FromStatusId != nil {
+			return *first.FromStatusId, true
+		}
+		return first.ToStatusId, true
+	}
+
+	status := first.ToStatusId
+	for _, change := range task.History[1:] {
+		if change.ChangedAt.After(at) {
+			break
+		}
+		status = change.ToStatusId
+	}
+
+	return status, true
+}
+
+func durationStats(hours []float64) dto.DurationStatsDto {
+	if len(hours) == 0 {
+		return dto.DurationStatsDto{}
+	}
+
+	sort.Float64s(hours)
+
+	var sum float64
+	for _, h := range hours {
+		sum += h
+	}
+
+	n := len(hours)
+	median := hours[n/2]
+	if n%2 == 0 {
+		median = (hours[n/2-1] + hours[n/2]) / 2
+	}
+
+	// nearest rank percentile
+	p85 := hours[int(math.Ceil(0.85*float64(n)))-1]
+
+	return dto.DurationStatsDto{
+		Count:       n,
+		AvgHours:    round2(sum / float64(n)),
+		MedianHours: round2(median),
+		P85Hours:    round2(p85),
+	}
+}
+
+// tasks completed by weeks starting on monday
+func throughput(tasks []models.TaskFlow, from time.Time, to time.Time) []dto.ThroughputWeekDto {
+	monday := time.Date(from.Year(), from.Month(), from.Day()-(int(from.Weekday())+6)%7, 0, 0, 0, 0, from.Location())
+
+	var weeks []dto.ThroughputWeekDto
+	for start := monday; start.Before(to); start = start.AddDate(0, 0, 7) {
+		end := start.AddDate(0, 0, 7)
+		week := dto.ThroughputWeekDto{WeekStart: start.Format(time.DateOnly)}
+
+		for i := range tasks {
+			completed := completedAt(&tasks[i])
+			if completed == nil || completed.Before(from) || !completed.Before(to) || completed.Before(start) || !completed.Before(end) {
+				continue
+			}
+
+			week.Completed++
+			if tasks[i].Estimate != nil {
+				week.Estimate += *tasks[i].Estimate
+			}
+		}
+
+		week.Estimate = round2(week.Estimate)
+		weeks = append(weeks, week)
+	}
+
+	return weeks
+}
+
+// number of tasks in every status at the end of every day which has come, statuses without tasks are skipped
+func cumulativeFlow(tasks []models.TaskFlow, statuses []models.Status, days []analyticsDay, now time.Time) dto.CumulativeFlowDto {
+	flow := dto.CumulativeFlowDto{Days: []string{}, Series: []dto.FlowSeriesDto{}}
+	counts := make(map[uint][]int)
+
+	for _, day := range days {
+		if day.Start.After(now) {
+			break
+		}
+
+		at := day.End
+		if at.After(now) {
+			at = now
+		}
+
+		n := len(flow.Days)
+		flow.Days = append(flow.Days, day.Name)
+		for i := range tasks {
+			status, ok := statusAt(&tasks[i], at)
+			if !ok || status == 0 {
+				continue
+			}
+
+			if counts[status] == nil {
+				counts[status] = make([]int, len(days))
+			}
+			counts[status][n]++
+		}
+	}
+
+	for _, status := range statuses {
+		if c, ok := counts[status.ID]; ok {
+			flow.Series = append(flow.Series, dto.FlowSeriesDto{StatusId: status.ID, Status: status.Type, Counts: c[:len(flow.Days)]})
+		}
+	}
+
+	return flow
+}
+
+// open tasks at the end of every day and ideal line from open tasks at the start of range to zero at its end
+func burndown(tasks []models.TaskFlow, from time.Time, to time.Time, days []analyticsDay, now time.Time) []dto.BurndownDayDto {
+	startTasks, startEstimate := remaining(tasks, from)
+	total := to.Sub(from).Seconds()
+
+	res := make([]dto.BurndownDayDto, 0, len(days))
+	for _, day := range days {
+		left := to.Sub(day.End).Seconds() / total
+		point := dto.BurndownDayDto{
+			Day:           day.Name,
+			IdealTasks:    round2(float64(startTasks) * left),
+			IdealEstimate: round2(startEstimate * left),
+		}
+
+		if !day.Start.After(now) {
+			at := day.End
+			if at.After(now) {
+				at = now
+			}
+
+			tasksLeft, estimateLeft := remaining(tasks, at)
+			estimateLeft = round2(estimateLeft)
+			point.RemainingTasks = &tasksLeft
+			point.RemainingEstimate = &estimateLeft
+		}
+
+		res = append(res, point)
+	}
+
+	return res
+}
+
+// remaining returns number and estimate of tasks which exist and aren't done at given time
+func remaining(tasks []models.TaskFlow, at time.Time) (int, float64) {
+	var count int
+	var estimate float64
+
+	for i := range tasks {
+		status, ok := statusAt(&tasks[i], at)
+		if !ok || isDone(status) {
+			continue
+		}
+
+		count++
+		if tasks[i].Estimate != nil {
+			estimate += *tasks[i].Estimate
+		}
+	}
+
+	return count, estimate
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"todo/internal/todo/blob"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	attachmentMaxBytes        = 20 << 20 // telegram bot api gives files up to 20 MB too
+	attachmentsMaxCount       = 100      // per task
+	attachmentNameMaxLength   = 255
+	attachmentKeyBytes        = 16
+	attachmentSniffBytes      = 512 // http.DetectContentType reads no more
+	deletedBlobsSweepPeriod   = time.Minute
+	deletedBlobsSweepBatch    = 100
+	attachmentDefaultFilename = "file"
+)
+
+// types detected by content. Html, svg and other types browsers can run are refused
+var attachmentTypes = map[string]bool{
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"image/bmp":          true,
+	"application/pdf":    true,
+	"text/plain":         true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+	"video/mp4":          true,
+	"video/webm":         true,
+}
+
+// zip containers and plain text get more exact type by extension
+var attachmentExtTypes = map[string]map[string]string{
+	"application/zip": {
+		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		".odt":  "application/vnd.oasis.opendocument.text",
+		".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	},
+	"text/plain": {
+		".csv":  "text/csv; charset=utf-8",
+		".md":   "text/markdown; charset=utf-8",
+		".json": "application/json",
+	},
+}
+
+// AttachmentsService keeps files of tasks in blob store, rows of attachments are in db
+type AttachmentsService struct {
+	storage AttachmentsStorager
+	blobs   blob.BlobStore
+	logger  *zap.Logger
+}
+
+type AttachmentsStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	CountAttachments(taskID uint) (int, error)
+	AddAttachment(attachment models.Attachment) (*models.Attachment, error)
+	GetAttachments(taskID uint) ([]models.Attachment, error)
+	GetAttachment(id uint) (*models.Attachment, error)
+	DeleteAttachment(id uint) error
+	GetDeletedBlobs(limit int) ([]string, error)
+	RemoveDeletedBlobs(keys []string) error
+}
+
+func NewAttachmentsService(stor AttachmentsStorager, blobs blob.BlobStore, logger *zap.Logger) *AttachmentsService {
+	return &AttachmentsService{
+		storage: stor,
+		blobs:   blobs,
+		logger:  logger,
+	}
+}
+
+// get attachments of task, oldest first
+func (t *AttachmentsService) GetAttachments(taskID uint, userID uint) ([]dto.AttachmentDto, error) {
+	if _, err := t.getMemberTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := t.storage.GetAttachments(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.AttachmentDto, 0, len(attachments))
+	for _, attachment := range attachments {
+		res = append(res, attachmentToDto(attachment))
+	}
+
+	return res, nil
+}
+
+// Upload saves file to blob store and adds it to task.
+// Checksum is optional hex sha256 of content computed by client, upload with other content is refused
+func (t *AttachmentsService) Upload(taskID uint, filename string, content io.Reader, checksum string, userID uint) (*dto.AttachmentDto, error) {
+	if _, err := t.getMemberTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum != "" {
+		if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+			return nil, fmt.Errorf("%w: checksum must be hex sha256", models.ErrInvalidInput)
+		}
+	}
+
+	count, err := t.storage.CountAttachments(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= attachmentsMaxCount {
+		return nil, fmt.Errorf("%w: task has %d attachments already", models.ErrInvalidInput, attachmentsMaxCount)
+	}
+
+	// blob store needs size and checksum before upload, so file is kept on disk first
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(content, attachmentMaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: read file: %v", models.ErrInvalidInput, err)
+	}
+	if size > attachmentMaxBytes {
+		return nil, fmt.Errorf("%w: file is larger than %d MB", models.ErrTooLarge, attachmentMaxBytes>>20)
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("%w: file is empty", models.ErrInvalidInput)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != "" && checksum != sum {
+		return nil, fmt.Errorf("%w: checksum doesn't match content", models.ErrInvalidInput)
+	}
+
+	filename = attachmentFilename(filename)
+
+	head := make([]byte, attachmentSniffBytes)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	contentType, ok := attachmentType(head[:n], filename)
+	if !ok {
+		return nil, fmt.Errorf("%w: type of file is not allowed", models.ErrInvalidInput)
+	}
+
+	key, err := attachmentKey(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if err := t.blobs.Put(ctx, key, tmp, size, contentType, sum); err != nil {
+		return nil, err
+	}
+
+	attachment, err := t.storage.AddAttachment(models.Attachment{
+		TaskId:      taskID,
+		UserId:      userID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		Sha256:      sum,
+		StorageKey:  key,
+	})
+	if err != nil || attachment == nil {
+		if delErr := t.blobs.Delete(ctx, key); delErr != nil {
+			t.logger.Error("delete blob of failed attachment", zap.String("key", key), zap.Error(delErr))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, models.ErrNotFound // task was deleted during upload
+	}
+
+	res := attachmentToDto(*attachment)
+	return &res, nil
+}
+
+// Open gives attachment of task and its content, caller closes content
+func (t *AttachmentsService) Open(taskID uint, id uint, userID uint) (*dto.AttachmentDto, io.ReadCloser, error) {
+	_, attachment, err := t.getAttachment(taskID, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := t.blobs.Get(context.Background(), attachment.StorageKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		t.logger.Error("file of attachment is missing", zap.Uint("id", id), zap.String("key", attachment.StorageKey))
+		return nil, nil, fmt.Errorf("%w: file of attachment is missing", models.ErrNotFound)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := attachmentToDto(*attachment)
+	return &res, content, nil
+}
+
+// attachment can be deleted by who uploaded it and by owner of board
+func (t *AttachmentsService) DeleteAttachment(taskID uint, id uint, userID uint) error {
+	task, attachment, err := t.getAttachment(taskID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if attachment.UserId != userID {
+		board, err := t.storage.GetBoard(task.BoardId)
+		if err != nil {
+			return err
+		}
+
+		if board == nil || board.OwnerId != userID {
+			return models.ErrForbidden
+		}
+	}
+
+	if err := t.storage.DeleteAttachment(id); err != nil {
+		return err
+	}
+
+	// file is removed right away, sweeper retries on failure
+	if err := t.blobs.Delete(context.Background(), attachment.StorageKey); err != nil {
+		t.logger.Error("delete blob", zap.String("key", attachment.StorageKey), zap.Error(err))
+		return nil
+	}
+
+	if err := t.storage.RemoveDeletedBlobs([]string{attachment.StorageKey}); err != nil {
+		t.logger.Error("remove deleted blob", zap.String("key", attachment.StorageKey), zap.Error(err))
+	}
+
+	return nil
+}
+
+// StartSweeper removes files of attachments deleted with tasks and boards
+func (t *AttachmentsService) StartSweeper() {
+	go func() {
+		for range time.Tick(deletedBlobsSweepPeriod) {
+			t.SweepDeletedBlobs()
+		}
+	}()
+}
+
+// remove one batch of files of deleted attachments
+func (t *AttachmentsService) SweepDeletedBlobs() {
+	keys, err := t.storage.GetDeletedBlobs(deletedBlobsSweepBatch)
+	if err != nil {
+		t.logger.Error("get deleted blobs", zap.Error(err))
+		return
+	}
+
+	removed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if err := t.blobs.Delete(context.Background(), key); err != nil {
+			t.logger.Error("delete blob", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		removed = append(removed, key)
+	}
+
+	if len(removed) == 0 {
+		return
+	}
+
+	if err := t.storage.RemoveDeletedBlobs(removed); err != nil {
+		t.logger.Error("remove deleted blobs", zap.Error(err))
+	}
+}
+
+// attachment of task from board of user
+func (t *AttachmentsService) getAttachment(taskID uint, id uint, userID uint) (*models.Task, *models.Attachment, error) {
+	task, err := t.getMemberTask(taskID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := t.storage.GetAttachment(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attachment == nil || attachment.TaskId != taskID {
+		return nil, nil, models.ErrNotFound
+	}
+
+	return task, attachment, nil
+}
+
+func (t *AttachmentsService) getMemberTask(taskID uint, userID uint) (*models.Task, error) {
+	task, err := t.storage.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(task.BoardId, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	return task, nil
+}
+
+// attachmentType detects type by content, extension only refines allowed type
+func attachmentType(head []byte, filename string) (string, bool) {
+	detected := http.DetectContentType(head)
+	mediaType, _, err := mime.ParseMediaType(detected)
+	if err != nil || !attachmentTypes[mediaType] {
+		return "", false
+	}
+
+	ext := strings.ToLower(path.Ext(filename))
+	if byExt, ok := attachmentExtTypes[mediaType][ext]; ok {
+		return byExt, true
+	}
+
+	return detected, true
+}
+
+// attachmentFilename keeps base name of file without control characters
+func attachmentFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	filename = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+
+	if filename == "" || filename == "." || filename == "/" {
+		return attachmentDefaultFilename
+	}
+
+	if runes := []rune(filename); len(runes) > attachmentNameMaxLength {
+		ext := []rune(path.Ext(filename))
+		if len(ext) > 16 {
+			ext = nil
+		}
+		filename = string(runes[:attachmentNameMaxLength-len(ext)]) + string(ext)
+	}
+
+	return filename
+}
+
+// random key, name of file is kept in db only
+func attachmentKey(taskID uint) (string, error) {
+	buf := make([]byte, attachmentKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(buf)), nil
+}
+
+func attachmentToDto(attachment models.Attachment) dto.AttachmentDto {
+	return dto.AttachmentDto{
+		ID:          attachment.ID,
+		TaskId:      attachment.TaskId,
+		UserId:      attachment.UserId,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Sha256:      attachment.Sha256,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
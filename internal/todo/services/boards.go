@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"strconv"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
@@ -13,12 +14,15 @@ type BoardsService struct {
 }
 
 type BoardsStorager interface {
-	SetBoard(body dto.PostBoardDto) (*models.Board, error)
-	GetAllBoards() ([]models.Board, error)
+	SetBoard(body dto.PostBoardDto, userID uint) (*models.Board, error)
+	GetAllBoards(userID uint) ([]models.Board, error)
 	GetBoard(id uint) (*models.Board, error)
-	UpdateBoard(body dto.PostBoardDto, id uint) (*models.Board, error)
+	UpdateBoard(body dto.PostBoardDto, id uint, actorID uint) (*models.Board, error)
 	DeleteBoard(id uint) error
-	User2Board(body dto.PostUser2BoardDto) error
+	User2Board(body dto.PostUser2BoardDto, actorID uint) error
+	RemoveUser(boardID uint, userID uint, actorID uint) error
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	SetBoardChat(boardID uint, chatID *int64) error
 }
 
 func NewBoardsService(stor BoardsStorager, logger *zap.Logger) *BoardsService {
@@ -27,8 +31,12 @@ func NewBoardsService(stor BoardsStorager, logger *zap.Logger) *BoardsService {
 	}
 }
 
-func (t *BoardsService) SetBoard(body dto.PostBoardDto) (*models.Board, error) {
-	boardRet, err := t.storage.SetBoard(body)
+func (t *BoardsService) SetBoard(body dto.PostBoardDto, userID uint) (*models.Board, error) {
+	if err := validateEstimateUnit(body.EstimateUnit); err != nil {
+		return nil, err
+	}
+
+	boardRet, err := t.storage.SetBoard(body, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +44,8 @@ func (t *BoardsService) SetBoard(body dto.PostBoardDto) (*models.Board, error) {
 	return boardRet, nil
 }
 
-func (t *BoardsService) GetAllBoards() ([]models.Board, error) {
-	boards, err := t.storage.GetAllBoards()
+func (t *BoardsService) GetAllBoards(userID uint) ([]models.Board, error) {
+	boards, err := t.storage.GetAllBoards(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +53,11 @@ func (t *BoardsService) GetAllBoards() ([]models.Board, error) {
 	return boards, nil
 }
 
-func (t *BoardsService) GetBoard(id uint) (*models.Board, error) {
+func (t *BoardsService) GetBoard(id uint, userID uint) (*models.Board, error) {
+	if err := t.checkMember(id, userID); err != nil {
+		return nil, err
+	}
+
 	board, err := t.storage.GetBoard(id)
 	if err != nil {
 		return nil, err
@@ -54,8 +66,16 @@ func (t *BoardsService) GetBoard(id uint) (*models.Board, error) {
 	return board, nil
 }
 
-func (t *BoardsService) UpdateBoard(body dto.PostBoardDto, id uint) error {
-	_, err := t.storage.UpdateBoard(body, id)
+func (t *BoardsService) UpdateBoard(body dto.PostBoardDto, id uint, userID uint) error {
+	if err := t.checkMember(id, userID); err != nil {
+		return err
+	}
+
+	if err := validateEstimateUnit(body.EstimateUnit); err != nil {
+		return err
+	}
+
+	_, err := t.storage.UpdateBoard(body, id, userID)
 	if err != nil {
 		return err
 	}
@@ -63,12 +83,16 @@ func (t *BoardsService) UpdateBoard(body dto.PostBoardDto, id uint) error {
 	return nil
 }
 
-func (t *BoardsService) DeleteBoard(id string) error {
+func (t *BoardsService) DeleteBoard(id string, userID uint) error {
 	Uintid, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return err
 	}
 
+	if err := t.checkMember(uint(Uintid), userID); err != nil {
+		return err
+	}
+
 	err = t.storage.DeleteBoard(uint(Uintid))
 	if err != nil {
 		return err
@@ -77,11 +101,100 @@ func (t *BoardsService) DeleteBoard(id string) error {
 	return nil
 }
 
-func (t *BoardsService) User2Board(body dto.PostUser2BoardDto) error {
-	err := t.storage.User2Board(body)
+func (t *BoardsService) User2Board(body dto.PostUser2BoardDto, userID uint) error {
+	boardID, err := strconv.ParseUint(body.BoardId, 10, 32)
+	if err != nil {
+		return err
+	}
+
+	if err := t.checkMember(uint(boardID), userID); err != nil {
+		return err
+	}
+
+	err = t.storage.User2Board(body, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// owner removes members, other members can only leave board themselves
+func (t *BoardsService) RemoveUser(id uint, memberID uint, userID uint) error {
+	board, err := t.storage.GetBoard(id)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return models.ErrNotFound
+	}
+
+	if board.OwnerId == memberID {
+		return fmt.Errorf("%w: owner can't be removed from board", models.ErrInvalidInput)
+	}
+
+	if memberID != userID && board.OwnerId != userID {
+		return models.ErrForbidden
+	}
+
+	return t.storage.RemoveUser(id, memberID, userID)
+}
+
+// link telegram group to board, board events are posted there
+func (t *BoardsService) LinkChat(id uint, chatID int64, userID uint) error {
+	if err := t.checkOwner(id, userID); err != nil {
+		return err
+	}
+
+	return t.storage.SetBoardChat(id, &chatID)
+}
+
+func (t *BoardsService) UnlinkChat(id uint, userID uint) error {
+	if err := t.checkOwner(id, userID); err != nil {
+		return err
+	}
+
+	return t.storage.SetBoardChat(id, nil)
+}
+
+// only owner can manage chat of board
+func (t *BoardsService) checkOwner(boardID uint, userID uint) error {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return models.ErrNotFound
+	}
+
+	if board.OwnerId != userID {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+// only members of board can see and change it
+func (t *BoardsService) checkMember(boardID uint, userID uint) error {
+	ok, err := t.storage.IsBoardMember(boardID, userID)
 	if err != nil {
 		return err
 	}
 
+	if !ok {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+// empty unit is allowed, storage keeps the current one or uses points
+func validateEstimateUnit(unit string) error {
+	if unit != "" && unit != models.EstimatePoints && unit != models.EstimateHours {
+		return fmt.Errorf("%w: estimate_unit must be %s or %s", models.ErrInvalidInput, models.EstimatePoints, models.EstimateHours)
+	}
+
 	return nil
 }
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+// operations in one bulk request
+const bulkMaxOperations = 200
+
+// BulkService applies many operations on tasks in one request
+type BulkService struct {
+	storage   BulkStorager
+	transfers *TransfersService // maps tasks moved to another board
+}
+
+type BulkStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetStatuses() ([]models.Status, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	ApplyBulk(ops []models.BulkOperation, atomic bool, actorID uint) ([]error, error)
+}
+
+func NewBulkService(stor BulkStorager, transfers TransfersStorager, logger *zap.Logger) *BulkService {
+	return &BulkService{
+		storage:   stor,
+		transfers: NewTransfersService(transfers, logger),
+	}
+}
+
+// Apply checks every operation with permissions of user and applies them.
+// Atomic request with any invalid operation is not applied at all
+func (t *BulkService) Apply(body dto.PostBulkDto, userID uint) (*dto.BulkResultDto, error) {
+	if len(body.Operations) == 0 {
+		return nil, fmt.Errorf("%w: operations are required", models.ErrInvalidInput)
+	}
+
+	if len(body.Operations) > bulkMaxOperations {
+		return nil, fmt.Errorf("%w: up to %d operations", models.ErrInvalidInput, bulkMaxOperations)
+	}
+
+	statuses, err := t.storage.GetStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.BulkResultDto{Results: make([]dto.BulkItemResultDto, len(body.Operations))}
+	var ops []models.BulkOperation
+	var indexes []int // of checked operations in request
+	failed := false
+	for i, item := range body.Operations {
+		res.Results[i].TaskId = item.TaskId
+
+		op, err := t.checkOperation(item, statuses, userID)
+		if err != nil {
+			res.Results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+
+		ops = append(ops, *op)
+		indexes = append(indexes, i)
+	}
+
+	if len(ops) == 0 || (body.Atomic && failed) {
+		return res, nil
+	}
+
+	errs, err := t.storage.ApplyBulk(ops, body.Atomic, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, opErr := range errs {
+		if opErr != nil {
+			res.Results[indexes[i]].Error = opErr.Error()
+			failed = true
+		}
+	}
+
+	// atomic request is rolled back on error
+	if body.Atomic && failed {
+		return res, nil
+	}
+
+	for i, opErr := range errs {
+		if opErr == nil {
+			res.Results[indexes[i]].Ok = true
+			res.Applied++
+		}
+	}
+
+	return res, nil
+}
+
+// checkOperation checks user can change task and prepares operation
+func (t *BulkService) checkOperation(item dto.BulkOperationDto, statuses []models.Status, userID uint) (*models.BulkOperation, error) {
+	if !slices.Contains(models.BulkOps, item.Op) {
+		return nil, fmt.Errorf("%w: unknown operation %q", models.ErrInvalidInput, item.Op)
+	}
+
+	task, err := t.transfers.getMemberTask(item.TaskId, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &models.BulkOperation{Op: item.Op, TaskId: task.ID}
+	switch item.Op {
+	case models.BulkStatus:
+		if !slices.ContainsFunc(statuses, func(status models.Status) bool { return status.ID == item.StatusId }) {
+			return nil, fmt.Errorf("%w: unknown status %d", models.ErrInvalidInput, item.StatusId)
+		}
+		op.StatusId = item.StatusId
+	case models.BulkAssign:
+		if item.UserId == 0 {
+			return nil, fmt.Errorf("%w: user_id is required", models.ErrInvalidInput)
+		}
+
+		ok, err := t.storage.IsBoardMember(task.BoardId, item.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("%w: user %d is not a member of board", models.ErrInvalidInput, item.UserId)
+		}
+		op.UserId = item.UserId
+	case models.BulkMove:
+		if item.BoardId == task.BoardId {
+			return nil, fmt.Errorf("%w: task is already on board %d", models.ErrInvalidInput, item.BoardId)
+		}
+
+		body := dto.PostTaskTransferDto{BoardId: item.BoardId, Statuses: item.Statuses, Fields: item.Fields, Labels: item.Labels}
+		if op.Transfer, err = t.transfers.transfer(task, body, userID); err != nil {
+			return nil, err
+		}
+	case models.BulkAddLabel, models.BulkRemoveLabel:
+		labels, err := t.storage.GetBoardLabels(task.BoardId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.ContainsFunc(labels, func(label models.BoardLabel) bool { return label.ID == item.LabelId }) {
+			return nil, fmt.Errorf("%w: board has no label %d", models.ErrInvalidInput, item.LabelId)
+		}
+		op.LabelId = item.LabelId
+	}
+
+	return op, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"todo/internal/todo/config"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	calendarTokenBytes = 32 // calendar_tokens.token is varchar(64)
+	calendarMaxBoards  = 50
+	calendarMaxLabels  = 20
+)
+
+// CalendarService gives tasks with due dates by secret url for calendar apps
+type CalendarService struct {
+	storage CalendarStorager
+}
+
+type CalendarStorager interface {
+	GetCalendarToken(userID uint) (*models.CalendarToken, error)
+	SetCalendarToken(userID uint, token string) (*models.CalendarToken, error)
+	DeleteCalendarToken(userID uint) error
+	GetCalendarUser(token string) (uint, error)
+	GetCalendarTasks(userID uint, filter dto.CalendarFilterDto) ([]models.CalendarTask, error)
+}
+
+func NewCalendarService(stor CalendarStorager, logger *zap.Logger) *CalendarService {
+	return &CalendarService{
+		storage: stor,
+	}
+}
+
+// get url of feed, token is created on the first request
+func (t *CalendarService) GetLink(userID uint) (*dto.CalendarLinkDto, error) {
+	token, err := t.storage.GetCalendarToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil {
+		return t.RegenerateToken(userID)
+	}
+
+	return calendarLink(token), nil
+}
+
+// new token revokes url with the old one
+func (t *CalendarService) RegenerateToken(userID uint) (*dto.CalendarLinkDto, error) {
+	buf := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	token, err := t.storage.SetCalendarToken(userID, hex.EncodeToString(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	return calendarLink(token), nil
+}
+
+// disable feed of user
+func (t *CalendarService) DeleteToken(userID uint) error {
+	return t.storage.DeleteCalendarToken(userID)
+}
+
+// Feed gives tasks of owner of token, unknown token is not found
+func (t *CalendarService) Feed(token string, filter dto.CalendarFilterDto) ([]models.CalendarTask, error) {
+	if len(token) != calendarTokenBytes*2 {
+		return nil, models.ErrNotFound
+	}
+
+	if len(filter.Boards) > calendarMaxBoards {
+		return nil, fmt.Errorf("%w: up to %d boards", models.ErrInvalidInput, calendarMaxBoards)
+	}
+
+	if len(filter.Labels) > calendarMaxLabels {
+		return nil, fmt.Errorf("%w: up to %d labels", models.ErrInvalidInput, calendarMaxLabels)
+	}
+
+	userID, err := t.storage.GetCalendarUser(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	return t.storage.GetCalendarTasks(userID, filter)
+}
+
+// url is relative when TODO_APP_URL is not set
+func calendarLink(token *models.CalendarToken) *dto.CalendarLinkDto {
+	var base string
+	if config.AppConfig != nil {
+		base = config.AppConfig.AppURL
+	}
+
+	return &dto.CalendarLinkDto{
+		URL:       fmt.Sprintf("%s/calendar/%s.ics", base, token.Token),
+		CreatedAt: token.CreatedAt,
+	}
+}
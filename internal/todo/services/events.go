@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	eventsRetention     = 24 * time.Hour // board events are kept for resume
+	eventsPrunePeriod   = 10 * time.Minute
+	eventsReplayLimit   = 1000
+	eventsBuffer        = 64
+	eventsListenBackoff = 5 * time.Second
+
+	presenceTouchPeriod = 30 * time.Second
+	presenceTTL         = 3 * presenceTouchPeriod // connection is gone if it wasn't touched
+)
+
+// EventsService fans out board events to subscribers of this instance.
+// Events of all instances come through postgres LISTEN/NOTIFY
+type EventsService struct {
+	storage EventsStorager
+	logger  *zap.Logger
+
+	mu   sync.Mutex
+	subs map[uint]map[*Subscription]struct{} // by board
+}
+
+type EventsStorager interface {
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetEvent(id uint) (*models.BoardEvent, error)
+	GetEvents(boardID uint, afterID uint, limit int) ([]models.BoardEvent, error)
+	GetOldestEventID() (uint, error)
+	PruneEvents(before time.Time) error
+	Listen(ctx context.Context, onEvent func(boardID uint, id uint), onPresence func(boardID uint)) error
+	JoinBoard(connID string, boardID uint, userID uint) error
+	LeaveBoard(connID string) error
+	TouchPresence(connIDs []string) error
+	PrunePresence(before time.Time) error
+	GetViewers(boardID uint, since time.Time) ([]models.Viewer, error)
+}
+
+// Subscription receives events of one board. Events is closed when subscriber
+// can't keep up or listener lost connection, client should resume from the last event.
+// Presence gets viewers of board after Join, only the latest list is kept
+type Subscription struct {
+	BoardId  uint
+	UserId   uint
+	Events   chan models.BoardEvent
+	Presence chan []models.Viewer
+
+	connID string // set by Join
+}
+
+func NewEventsService(stor EventsStorager, logger *zap.Logger) *EventsService {
+	return &EventsService{
+		storage: stor,
+		logger:  logger,
+		subs:    make(map[uint]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe to events of board, only for its members
+func (t *EventsService) Subscribe(boardID uint, userID uint) (*Subscription, error) {
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	sub := &Subscription{
+		BoardId:  boardID,
+		UserId:   userID,
+		Events:   make(chan models.BoardEvent, eventsBuffer),
+		Presence: make(chan []models.Viewer, 1),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.subs[boardID] == nil {
+		t.subs[boardID] = make(map[*Subscription]struct{})
+	}
+	t.subs[boardID][sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Unsubscribe is safe to call after subscription was dropped
+func (t *EventsService) Unsubscribe(sub *Subscription) {
+	t.mu.Lock()
+	t.drop(sub)
+	connID := sub.connID
+	t.mu.Unlock()
+
+	if connID != "" {
+		if err := t.storage.LeaveBoard(connID); err != nil {
+			t.logger.Error("leave board", zap.Uint("board_id", sub.BoardId), zap.Error(err))
+		}
+	}
+}
+
+// Join shows subscriber to other viewers of board and starts presence updates
+func (t *EventsService) Join(sub *Subscription) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	connID := hex.EncodeToString(id)
+
+	t.mu.Lock()
+	sub.connID = connID
+	t.mu.Unlock()
+
+	return t.storage.JoinBoard(connID, sub.BoardId, sub.UserId)
+}
+
+// Replay returns events of board after lastID. Reset is true when some of them
+// could be already pruned, then client should load the board again
+func (t *EventsService) Replay(boardID uint, lastID uint) ([]models.BoardEvent, bool, error) {
+	oldest, err := t.storage.GetOldestEventID()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// ids are shared by all boards, so reset may be sent when nothing is lost, but never vice versa
+	if oldest == 0 || lastID+1 < oldest {
+		return nil, true, nil
+	}
+
+	events, err := t.storage.GetEvents(boardID, lastID, eventsReplayLimit+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(events) > eventsReplayLimit {
+		return nil, true, nil
+	}
+
+	return events, false, nil
+}
+
+// listen to events of all instances and prune the log in background
+func (t *EventsService) Start() {
+	go func() {
+		for {
+			err := t.storage.Listen(context.Background(), t.publish, t.publishPresence)
+			t.logger.Error("listen board events", zap.Error(err))
+
+			// events could be missed, subscribers will resume from the log
+			t.dropAll()
+			time.Sleep(eventsListenBackoff)
+		}
+	}()
+
+	go func() {
+		for range time.Tick(presenceTouchPeriod) {
+			t.touchPresence()
+		}
+	}()
+
+	go func() {
+		for range time.Tick(eventsPrunePeriod) {
+			if err := t.storage.PruneEvents(time.Now().Add(-eventsRetention)); err != nil {
+				t.logger.Error("prune board events", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// publish sends event to subscribers of its board
+func (t *EventsService) publish(boardID uint, id uint) {
+	t.mu.Lock()
+	empty := len(t.subs[boardID]) == 0
+	t.mu.Unlock()
+
+	if empty {
+		return
+	}
+
+	event, err := t.storage.GetEvent(id)
+	if err != nil {
+		t.logger.Error("get board event", zap.Uint("id", id), zap.Error(err))
+		return
+	}
+	if event == nil {
+		return
+	}
+
+	removed := removedMember(event)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for sub := range t.subs[boardID] {
+		select {
+		case sub.Events <- *event:
+		default:
+			// slow subscriber resumes by Last-Event-ID after reconnect
+			t.drop(sub)
+			continue
+		}
+
+		// removed member gets the event and loses access
+		if removed != 0 && sub.UserId == removed {
+			t.drop(sub)
+		}
+	}
+}
+
+// removedMember returns id of user removed from board by event, 0 for other events
+func removedMember(event *models.BoardEvent) uint {
+	if event.Event != models.BoardEventMemberRemoved {
+		return 0
+	}
+
+	var payload struct {
+		Data dto.MemberEventDataDto `json:"data"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return 0
+	}
+
+	return payload.Data.UserId
+}
+
+// publishPresence sends viewers of board to its joined subscribers
+func (t *EventsService) publishPresence(boardID uint) {
+	t.mu.Lock()
+	joined := false
+	for sub := range t.subs[boardID] {
+		joined = joined || sub.connID != ""
+	}
+	t.mu.Unlock()
+
+	if !joined {
+		return
+	}
+
+	viewers, err := t.storage.GetViewers(boardID, time.Now().Add(-presenceTTL))
+	if err != nil {
+		t.logger.Error("get viewers of board", zap.Uint("board_id", boardID), zap.Error(err))
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for sub := range t.subs[boardID] {
+		if sub.connID == "" {
+			continue
+		}
+
+		// replace list which wasn't read yet
+		select {
+		case <-sub.Presence:
+		default:
+		}
+		sub.Presence <- viewers
+	}
+}
+
+// touchPresence keeps connections of this instance alive and removes ones of stopped instances
+func (t *EventsService) touchPresence() {
+	var connIDs []string
+
+	t.mu.Lock()
+	for _, subs := range t.subs {
+		for sub := range subs {
+			if sub.connID != "" {
+				connIDs = append(connIDs, sub.connID)
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	if len(connIDs) > 0 {
+		if err := t.storage.TouchPresence(connIDs); err != nil {
+			t.logger.Error("touch board presence", zap.Error(err))
+		}
+	}
+
+	if err := t.storage.PrunePresence(time.Now().Add(-presenceTTL)); err != nil {
+		t.logger.Error("prune board presence", zap.Error(err))
+	}
+}
+
+func (t *EventsService) dropAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, subs := range t.subs {
+		for sub := range subs {
+			t.drop(sub)
+		}
+	}
+}
+
+// drop must be called with mu held
+func (t *EventsService) drop(sub *Subscription) {
+	subs, ok := t.subs[sub.BoardId]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.Events)
+	if len(subs) == 0 {
+		delete(t.subs, sub.BoardId)
+	}
+}
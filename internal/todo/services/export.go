@@ -0,0 +1,83 @@
+package services
+
+import (
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+// ExportService gives boards of user with their tasks for export
+type ExportService struct {
+	storage ExportStorager
+}
+
+type ExportStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetStatuses() ([]models.Status, error)
+	GetExportBoards(userID uint, boardID uint) ([]models.ExportBoard, error)
+	EachExportTask(boardIDs []uint, fn func(task models.ExportTask) error) error
+}
+
+func NewExportService(stor ExportStorager, logger *zap.Logger) *ExportService {
+	return &ExportService{
+		storage: stor,
+	}
+}
+
+// export one board for its member
+func (t *ExportService) ExportBoard(id uint, userID uint) (*models.Export, error) {
+	board, err := t.storage.GetBoard(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	return t.export(userID, id)
+}
+
+// export all boards of user
+func (t *ExportService) ExportAll(userID uint) (*models.Export, error) {
+	return t.export(userID, 0)
+}
+
+// everything but tasks is loaded here, so errors come before the response is started
+func (t *ExportService) export(userID uint, boardID uint) (*models.Export, error) {
+	statuses, err := t.storage.GetStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	boards, err := t.storage.GetExportBoards(userID, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(boards))
+	for _, board := range boards {
+		ids = append(ids, board.ID)
+	}
+
+	return &models.Export{
+		Statuses: statuses,
+		Boards:   boards,
+		Each: func(fn func(task models.ExportTask) error) error {
+			if len(ids) == 0 {
+				return nil
+			}
+			return t.storage.EachExportTask(ids, fn)
+		},
+	}, nil
+}
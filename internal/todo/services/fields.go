@@ -0,0 +1,423 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	fieldsMaxCount       = 50
+	fieldNameMaxLength   = 64
+	fieldOptionsMaxCount = 100
+	fieldOptionMaxLength = 64
+	fieldTextMaxLength   = 1000
+	fieldURLMaxLength    = 2048
+	fieldNumberMax       = 1e15 // numbers stay exact in json
+)
+
+// FieldsService manages custom fields of boards, values are validated by their type when task is saved
+type FieldsService struct {
+	storage FieldsStorager
+}
+
+type FieldsStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetFields(boardID uint) ([]models.BoardField, error)
+	GetField(id uint) (*models.BoardField, error)
+	CreateField(field models.BoardField) (*models.BoardField, error)
+	UpdateField(field models.BoardField) (*models.BoardField, error)
+	DeleteField(field *models.BoardField) error
+}
+
+func NewFieldsService(stor FieldsStorager, logger *zap.Logger) *FieldsService {
+	return &FieldsService{
+		storage: stor,
+	}
+}
+
+// fields of board are visible to all its members
+func (t *FieldsService) GetFields(boardID uint, userID uint) ([]dto.BoardFieldDto, error) {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	fields, err := t.storage.GetFields(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.BoardFieldDto, 0, len(fields))
+	for i := range fields {
+		res = append(res, *fieldToDto(&fields[i]))
+	}
+
+	return res, nil
+}
+
+func (t *FieldsService) CreateField(boardID uint, body dto.PostBoardFieldDto, userID uint) (*dto.BoardFieldDto, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	fields, err := t.storage.GetFields(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) >= fieldsMaxCount {
+		return nil, fmt.Errorf("%w: board can have up to %d fields", models.ErrInvalidInput, fieldsMaxCount)
+	}
+
+	if !slices.Contains(models.FieldTypes, body.Type) {
+		return nil, fmt.Errorf("%w: type must be one of %s", models.ErrInvalidInput, strings.Join(models.FieldTypes, ", "))
+	}
+
+	field, err := fieldFromDto(body, body.Type, fields, 0)
+	if err != nil {
+		return nil, err
+	}
+	field.BoardId = boardID
+
+	created, err := t.storage.CreateField(*field)
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldToDto(created), nil
+}
+
+// replace name, options and position of field, values with removed options are removed from tasks
+func (t *FieldsService) UpdateField(boardID uint, id uint, body dto.PostBoardFieldDto, userID uint) (*dto.BoardFieldDto, error) {
+	current, err := t.getOwnField(boardID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// values of tasks are kept in format of type
+	if body.Type != "" && body.Type != current.Type {
+		return nil, fmt.Errorf("%w: type of field can't be changed", models.ErrInvalidInput)
+	}
+
+	fields, err := t.storage.GetFields(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	field, err := fieldFromDto(body, current.Type, fields, id)
+	if err != nil {
+		return nil, err
+	}
+	field.ID = id
+
+	updated, err := t.storage.UpdateField(*field)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, models.ErrNotFound
+	}
+
+	return fieldToDto(updated), nil
+}
+
+// delete field with its values in tasks
+func (t *FieldsService) DeleteField(boardID uint, id uint, userID uint) error {
+	field, err := t.getOwnField(boardID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return t.storage.DeleteField(field)
+}
+
+// get field of board managed by user
+func (t *FieldsService) getOwnField(boardID uint, id uint, userID uint) (*models.BoardField, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	field, err := t.storage.GetField(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if field == nil || field.BoardId != boardID {
+		return nil, models.ErrNotFound
+	}
+
+	return field, nil
+}
+
+// only owner can change fields of board
+func (t *FieldsService) checkOwner(boardID uint, userID uint) error {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return models.ErrNotFound
+	}
+
+	if board.OwnerId != userID {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+// fieldFromDto validates field of given type, name must be unique on board except field with id
+func fieldFromDto(body dto.PostBoardFieldDto, fieldType string, fields []models.BoardField, id uint) (*models.BoardField, error) {
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len([]rune(name)) > fieldNameMaxLength {
+		return nil, fmt.Errorf("%w: name must be from 1 to %d characters", models.ErrInvalidInput, fieldNameMaxLength)
+	}
+
+	for _, field := range fields {
+		if field.ID != id && strings.EqualFold(field.Name, name) {
+			return nil, fmt.Errorf("%w: board already has field %q", models.ErrInvalidInput, field.Name)
+		}
+	}
+
+	options := []string{}
+	if fieldType == models.FieldSelect || fieldType == models.FieldMultiSelect {
+		for _, option := range body.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || len([]rune(option)) > fieldOptionMaxLength {
+				return nil, fmt.Errorf("%w: option must be from 1 to %d characters", models.ErrInvalidInput, fieldOptionMaxLength)
+			}
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+
+		if len(options) == 0 || len(options) > fieldOptionsMaxCount {
+			return nil, fmt.Errorf("%w: %s field needs from 1 to %d options", models.ErrInvalidInput, fieldType, fieldOptionsMaxCount)
+		}
+	} else if len(body.Options) > 0 {
+		return nil, fmt.Errorf("%w: options are only for select and multi_select fields", models.ErrInvalidInput)
+	}
+
+	return &models.BoardField{
+		Name:     name,
+		Type:     fieldType,
+		Options:  options,
+		Position: body.Position,
+	}, nil
+}
+
+func fieldToDto(field *models.BoardField) *dto.BoardFieldDto {
+	options := field.Options
+	if options == nil {
+		options = []string{}
+	}
+
+	return &dto.BoardFieldDto{
+		ID:        field.ID,
+		BoardId:   field.BoardId,
+		Name:      field.Name,
+		Type:      field.Type,
+		Options:   options,
+		Position:  field.Position,
+		CreatedAt: field.CreatedAt,
+		UpdatedAt: field.UpdatedAt,
+	}
+}
+
+// fieldValues validates values of custom fields of board by their types, keys are field ids.
+// Nulls and empty values are dropped
+func fieldValues(fields []models.BoardField, values map[string]any) (map[string]any, error) {
+	res := make(map[string]any, len(values))
+	for key, value := range values {
+		i := slices.IndexFunc(fields, func(field models.BoardField) bool {
+			return strconv.FormatUint(uint64(field.ID), 10) == key
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%w: board has no field %q", models.ErrInvalidInput, key)
+		}
+
+		normalized, err := fieldValue(&fields[i], value)
+		if err != nil {
+			return nil, err
+		}
+
+		if normalized != nil {
+			res[key] = normalized
+		}
+	}
+
+	return res, nil
+}
+
+// fieldValue returns value of field in stored format, nil when value is empty.
+// User is checked to be a member of board separately
+func fieldValue(field *models.BoardField, value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	invalid := func(expected string) error {
+		return fmt.Errorf("%w: field %q expects %s", models.ErrInvalidInput, field.Name, expected)
+	}
+
+	switch field.Type {
+	case models.FieldText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("string")
+		}
+
+		text = strings.TrimSpace(text)
+		if len([]rune(text)) > fieldTextMaxLength {
+			return nil, invalid(fmt.Sprintf("up to %d characters", fieldTextMaxLength))
+		}
+		if text == "" {
+			return nil, nil
+		}
+
+		return text, nil
+	case models.FieldNumber:
+		number, ok := value.(float64)
+		if !ok || math.Abs(number) > fieldNumberMax {
+			return nil, invalid("number")
+		}
+
+		return number, nil
+	case models.FieldDate:
+		date, ok := value.(string)
+		if !ok {
+			return nil, invalid("date YYYY-MM-DD")
+		}
+		if date == "" {
+			return nil, nil
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, invalid("date YYYY-MM-DD")
+		}
+
+		return date, nil
+	case models.FieldSelect:
+		option, ok := value.(string)
+		if !ok {
+			return nil, invalid("one of options")
+		}
+		if option == "" {
+			return nil, nil
+		}
+		if !slices.Contains(field.Options, option) {
+			return nil, invalid("one of options " + strings.Join(field.Options, ", "))
+		}
+
+		return option, nil
+	case models.FieldMultiSelect:
+		list, ok := value.([]any)
+		if !ok {
+			return nil, invalid("list of options")
+		}
+
+		options := []string{}
+		for _, item := range list {
+			option, ok := item.(string)
+			if !ok || !slices.Contains(field.Options, option) {
+				return nil, invalid("list of options " + strings.Join(field.Options, ", "))
+			}
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 0 {
+			return nil, nil
+		}
+
+		return options, nil
+	case models.FieldUser:
+		id, ok := value.(float64)
+		if !ok || id <= 0 || id != math.Trunc(id) || id > math.MaxUint32 {
+			return nil, invalid("user id")
+		}
+
+		return uint(id), nil
+	case models.FieldURL:
+		link, ok := value.(string)
+		if !ok {
+			return nil, invalid("http or https url")
+		}
+
+		link = strings.TrimSpace(link)
+		if link == "" {
+			return nil, nil
+		}
+
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(link) > fieldURLMaxLength {
+			return nil, invalid("http or https url")
+		}
+
+		return u.String(), nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown type of field %q", models.ErrInvalidInput, field.Name)
+}
+
+// fieldFilter parses value from query of task listing. Text and url are searched by substring,
+// multi_select matches tasks with given option, other types match exactly
+func fieldFilter(field *models.BoardField, value string) (dto.FieldFilterDto, error) {
+	filter := dto.FieldFilterDto{FieldId: field.ID}
+
+	var parsed any = value
+	switch field.Type {
+	case models.FieldText, models.FieldURL:
+		filter.Value = value
+		filter.Contains = true
+		return filter, nil
+	case models.FieldNumber, models.FieldUser:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return filter, fmt.Errorf("%w: field %q expects number", models.ErrInvalidInput, field.Name)
+		}
+		parsed = number
+	case models.FieldMultiSelect:
+		parsed = []any{value}
+	}
+
+	normalized, err := fieldValue(field, parsed)
+	if err != nil {
+		return filter, err
+	}
+	if normalized == nil {
+		return filter, fmt.Errorf("%w: empty value of field %q", models.ErrInvalidInput, field.Name)
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return filter, err
+	}
+	filter.Value = string(data)
+
+	return filter, nil
+}
@@ -0,0 +1,524 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/importer"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+// tasks.title is varchar(100)
+const taskTitleMaxLength = 100
+
+// ImportService imports boards from exports of Trello, Todoist and json exports of this service. Items are remembered by their
+// ids in source, so repeated import of the same export adds only new tasks
+type ImportService struct {
+	storage ImportStorager
+}
+
+type ImportStorager interface {
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetStatuses() ([]models.Status, error)
+	GetUsersByUsernames(usernames []string) (map[string]uint, error)
+	GetImported(userID uint, source string, kind string, externalIDs []string) (map[string]uint, error)
+	Import(plan models.ImportPlan, userID uint) (uint, error)
+}
+
+func NewImportService(stor ImportStorager, logger *zap.Logger) *ImportService {
+	return &ImportService{
+		storage: stor,
+	}
+}
+
+// Import parses export and imports its boards, dry run only reports what would be created.
+// name is a board name for Todoist csv which has no project name
+func (t *ImportService) Import(source string, data []byte, name string, dryRun bool, userID uint) (*dto.ImportReportDto, error) {
+	if !slices.Contains(models.ImportSources, source) {
+		return nil, fmt.Errorf("%w: source must be one of %s", models.ErrInvalidInput, strings.Join(models.ImportSources, ", "))
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: export file is empty", models.ErrInvalidInput)
+	}
+
+	boards, err := importer.Parse(source, data, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(boards) == 0 {
+		return nil, fmt.Errorf("%w: export has no boards", models.ErrInvalidInput)
+	}
+
+	statuses, err := t.storage.GetStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	var usernames []string
+	for _, board := range boards {
+		for _, member := range board.Members {
+			usernames = append(usernames, member.Usernames...)
+		}
+	}
+
+	users, err := t.storage.GetUsersByUsernames(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.ImportReportDto{Source: source, DryRun: dryRun, Boards: make([]dto.ImportBoardReportDto, 0, len(boards))}
+	for _, board := range boards {
+		plan, report, err := t.plan(source, board, statuses, users, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// every board is imported in its own transaction, failed import can be repeated
+		if !dryRun {
+			if report.BoardId, err = t.storage.Import(*plan, userID); err != nil {
+				return nil, err
+			}
+		}
+
+		res.Boards = append(res.Boards, *report)
+	}
+
+	return res, nil
+}
+
+// plan maps lists to statuses and members to users by username, tasks imported before are skipped
+func (t *ImportService) plan(source string, board models.ImportBoard, statuses []models.Status, users map[string]uint,
+	userID uint) (*models.ImportPlan, *dto.ImportBoardReportDto, error) {
+	plan := &models.ImportPlan{
+		Source:       source,
+		ExternalId:   board.ExternalId,
+		Name:         importName(board.Name, source),
+		EstimateUnit: models.EstimatePoints,
+	}
+	if board.EstimateUnit != "" && validateEstimateUnit(board.EstimateUnit) == nil {
+		plan.EstimateUnit = board.EstimateUnit
+	}
+
+	report := &dto.ImportBoardReportDto{
+		ExternalId: board.ExternalId,
+		Name:       plan.Name,
+		Statuses:   []dto.ImportStatusReportDto{},
+		Members:    []dto.ImportMemberReportDto{},
+		Labels:     []string{},
+		Fields:     []string{},
+		Unmapped:   []string{},
+	}
+
+	imported, err := t.storage.GetImported(userID, source, models.ImportedBoard, []string{board.ExternalId})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		fields []models.BoardField
+		labels []models.BoardLabel
+	)
+	if boardID, ok := imported[board.ExternalId]; ok {
+		ok, err := t.storage.IsBoardMember(boardID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q was imported to board %d, user is not its member", models.ErrForbidden, board.Name, boardID)
+		}
+
+		if fields, err = t.storage.GetBoardFields(boardID); err != nil {
+			return nil, nil, err
+		}
+
+		if labels, err = t.storage.GetBoardLabels(boardID); err != nil {
+			return nil, nil, err
+		}
+
+		plan.BoardId = boardID
+		report.BoardId = boardID
+		report.Exists = true
+	}
+
+	// lists go to statuses with the same name, closed lists are archived
+	lists := make(map[string]uint, len(board.Lists))
+	for _, list := range board.Lists {
+		item := dto.ImportStatusReportDto{List: list.Name, StatusId: models.StatusInProcess}
+		if i := slices.IndexFunc(statuses, func(status models.Status) bool {
+			return strings.EqualFold(strings.TrimSpace(status.Type), strings.TrimSpace(list.Name))
+		}); i >= 0 {
+			item.StatusId = statuses[i].ID
+			item.Mapped = true
+		} else if list.Closed {
+			item.StatusId = models.StatusArchived
+			item.Mapped = true
+		} else {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("list %q has no status with the same name, its tasks are in process", list.Name))
+		}
+
+		if i := slices.IndexFunc(statuses, func(status models.Status) bool { return status.ID == item.StatusId }); i >= 0 {
+			item.Status = statuses[i].Type
+		}
+
+		lists[list.ExternalId] = item.StatusId
+		report.Statuses = append(report.Statuses, item)
+	}
+
+	members := make(map[string]uint, len(board.Members))
+	for _, member := range board.Members {
+		if len(member.Usernames) == 0 {
+			continue
+		}
+
+		item := dto.ImportMemberReportDto{Username: member.Usernames[0]}
+		for _, username := range member.Usernames {
+			if id, ok := users[strings.ToLower(username)]; ok {
+				item.UserId = id
+				item.Mapped = true
+				break
+			}
+		}
+
+		if !item.Mapped {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("member %q has no user with the same username", item.Username))
+		} else {
+			members[member.ExternalId] = item.UserId
+			if item.UserId != userID && !slices.Contains(plan.Members, item.UserId) {
+				plan.Members = append(plan.Members, item.UserId)
+			}
+		}
+
+		report.Members = append(report.Members, item)
+	}
+
+	options, err := t.planLabels(plan, board.Labels, fields, report)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	boardFields := t.planFields(plan, board.Fields, fields, report)
+	boardLabels := t.planBoardLabels(plan, board.BoardLabels, labels, report)
+
+	ids := make([]string, 0, len(board.Tasks))
+	for _, task := range board.Tasks {
+		ids = append(ids, task.ExternalId)
+	}
+
+	imported, err = t.storage.GetImported(userID, source, models.ImportedTask, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cut, dropped int
+	for _, task := range board.Tasks {
+		if _, ok := imported[task.ExternalId]; ok {
+			report.Skipped++
+			continue
+		}
+
+		item, long := t.planTask(task, lists, members, options, userID)
+		if long {
+			cut++
+		}
+		dropped += planValues(item, task, boardFields, boardLabels, members)
+
+		plan.Tasks = append(plan.Tasks, *item)
+		report.Tasks++
+		report.Checklists += len(task.Checklists)
+		report.Comments += len(task.Comments)
+	}
+
+	if cut > 0 {
+		report.Unmapped = append(report.Unmapped,
+			fmt.Sprintf("%d titles are longer than %d characters, full titles are kept in description", cut, taskTitleMaxLength))
+	}
+	if dropped > 0 {
+		report.Unmapped = append(report.Unmapped, fmt.Sprintf("%d values of fields don't fit fields of board and are skipped", dropped))
+	}
+
+	return plan, report, nil
+}
+
+// planLabels puts labels to options of multi_select labels field and returns names of options by labels
+func (t *ImportService) planLabels(plan *models.ImportPlan, labels []string, fields []models.BoardField, report *dto.ImportBoardReportDto) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	var options []string
+	if i := slices.IndexFunc(fields, func(field models.BoardField) bool {
+		return strings.EqualFold(field.Name, models.ImportLabelsField)
+	}); i >= 0 {
+		if fields[i].Type != models.FieldMultiSelect {
+			report.Unmapped = append(report.Unmapped,
+				fmt.Sprintf("field %q of board is not multi_select, labels are skipped", fields[i].Name))
+			return nil, nil
+		}
+
+		plan.LabelsId = fields[i].ID
+		options = slices.Clone(fields[i].Options)
+	} else if len(fields) >= fieldsMaxCount {
+		report.Unmapped = append(report.Unmapped, fmt.Sprintf("board has %d fields, labels are skipped", fieldsMaxCount))
+		return nil, nil
+	}
+
+	names := make(map[string]string, len(labels))
+	for _, label := range labels {
+		option := strings.TrimSpace(label)
+		if runes := []rune(option); len(runes) > fieldOptionMaxLength {
+			option = string(runes[:fieldOptionMaxLength])
+		}
+		if option == "" {
+			continue
+		}
+
+		if i := slices.IndexFunc(options, func(o string) bool { return strings.EqualFold(o, option) }); i >= 0 {
+			names[label] = options[i]
+		} else if len(options) < fieldOptionsMaxCount {
+			options = append(options, option)
+			names[label] = option
+		} else {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("label %q is over %d options of field", label, fieldOptionsMaxCount))
+			continue
+		}
+
+		if !slices.Contains(report.Labels, names[label]) {
+			report.Labels = append(report.Labels, names[label])
+		}
+	}
+
+	plan.Labels = options
+
+	return names, nil
+}
+
+// planFields matches fields by name, fields of another type are skipped. Other fields are created
+// while board has room for them. Returns fields by external ids
+func (t *ImportService) planFields(plan *models.ImportPlan, imported []models.ImportField, fields []models.BoardField,
+	report *dto.ImportBoardReportDto) map[string]models.BoardField {
+	res := make(map[string]models.BoardField, len(imported))
+	known := slices.Clone(fields)
+	for _, item := range imported {
+		name := strings.TrimSpace(item.Name)
+		if i := slices.IndexFunc(known, func(field models.BoardField) bool { return strings.EqualFold(field.Name, name) }); i >= 0 {
+			if known[i].Type != item.Type {
+				report.Unmapped = append(report.Unmapped, fmt.Sprintf("field %q of board is not %s, its values are skipped", known[i].Name, item.Type))
+				continue
+			}
+
+			res[item.ExternalId] = known[i]
+			report.Fields = append(report.Fields, known[i].Name)
+			continue
+		}
+
+		if len(known) >= fieldsMaxCount {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("field %q is over %d fields of board", item.Name, fieldsMaxCount))
+			continue
+		}
+
+		if !slices.Contains(models.FieldTypes, item.Type) {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("field %q has unknown type %q", item.Name, item.Type))
+			continue
+		}
+
+		field, err := fieldFromDto(dto.PostBoardFieldDto{Name: item.Name, Options: item.Options}, item.Type, known, 0)
+		if err != nil {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("field %q is skipped: %v", item.Name, err))
+			continue
+		}
+
+		known = append(known, *field)
+		plan.Fields = append(plan.Fields, *field)
+		res[item.ExternalId] = *field
+		report.Fields = append(report.Fields, field.Name)
+	}
+
+	return res
+}
+
+// planBoardLabels matches labels by name, other labels are created while board has room for them.
+// Returns names of labels by external ids
+func (t *ImportService) planBoardLabels(plan *models.ImportPlan, imported []models.ImportLabel, labels []models.BoardLabel,
+	report *dto.ImportBoardReportDto) map[string]string {
+	res := make(map[string]string, len(imported))
+	known := slices.Clone(labels)
+	for _, item := range imported {
+		name := strings.TrimSpace(item.Name)
+		if i := slices.IndexFunc(known, func(label models.BoardLabel) bool { return strings.EqualFold(label.Name, name) }); i >= 0 {
+			res[item.ExternalId] = known[i].Name
+		} else if len(known) >= labelsMaxCount {
+			report.Unmapped = append(report.Unmapped, fmt.Sprintf("label %q is over %d labels of board", item.Name, labelsMaxCount))
+			continue
+		} else {
+			label, err := labelFromDto(dto.PostBoardLabelDto{Name: item.Name, Color: item.Color}, known, 0)
+			if err != nil {
+				report.Unmapped = append(report.Unmapped, fmt.Sprintf("label %q is skipped: %v", item.Name, err))
+				continue
+			}
+
+			known = append(known, *label)
+			plan.BoardLabels = append(plan.BoardLabels, *label)
+			res[item.ExternalId] = label.Name
+		}
+
+		if !slices.Contains(report.Labels, res[item.ExternalId]) {
+			report.Labels = append(report.Labels, res[item.ExternalId])
+		}
+	}
+
+	return res
+}
+
+// planValues puts values of fields and labels of board to task, users are mapped like assignee.
+// Returns number of values which don't fit fields of board
+func planValues(item *models.ImportTaskPlan, task models.ImportTask, fields map[string]models.BoardField, labels map[string]string,
+	members map[string]uint) int {
+	var dropped int
+	for key, value := range task.Fields {
+		field, ok := fields[key]
+		if !ok {
+			dropped++
+			continue
+		}
+
+		if field.Type == models.FieldUser && value != nil {
+			userID, ok := members[fmt.Sprint(value)]
+			if !ok {
+				dropped++
+				continue
+			}
+			value = float64(userID)
+		}
+
+		normalized, err := fieldValue(&field, value)
+		if err != nil {
+			dropped++
+			continue
+		}
+
+		if normalized != nil {
+			if item.FieldValues == nil {
+				item.FieldValues = make(map[string]any, len(task.Fields))
+			}
+			item.FieldValues[strings.ToLower(field.Name)] = normalized
+		}
+	}
+
+	for _, label := range task.BoardLabels {
+		if name, ok := labels[label]; ok && !slices.Contains(item.LabelNames, name) {
+			item.LabelNames = append(item.LabelNames, name)
+		}
+	}
+
+	return dropped
+}
+
+// planTask prepares task, checklists are added to description as there are no checklists in tasks.
+// Returns whether title was cut
+func (t *ImportService) planTask(task models.ImportTask, lists map[string]uint, members map[string]uint, labels map[string]string,
+	userID uint) (*models.ImportTaskPlan, bool) {
+	statusID, ok := lists[task.ListId]
+	if !ok {
+		statusID = models.StatusInProcess
+	}
+	if task.Done {
+		statusID = models.StatusDone
+	}
+	if task.Closed {
+		statusID = models.StatusArchived
+	}
+
+	// task is assigned to its importer like to author of a new task
+	assignee := userID
+	for _, member := range task.Members {
+		if id, ok := members[member]; ok {
+			assignee = id
+			break
+		}
+	}
+
+	title := strings.TrimSpace(task.Title)
+	if title == "" {
+		title = "Без названия"
+	}
+
+	description := task.Description
+	long := len([]rune(title)) > taskTitleMaxLength
+	if long {
+		description = strings.TrimSpace(title + "\n\n" + description)
+		title = string([]rune(title)[:taskTitleMaxLength])
+	}
+	description = strings.TrimSpace(description + checklistsText(task.Checklists))
+
+	item := &models.ImportTaskPlan{
+		ExternalId: task.ExternalId,
+		Task: models.Task{
+			Title:       title,
+			Description: description,
+			StatusId:    statusID,
+			UserId:      assignee,
+			Priority:    task.Priority,
+			DueAt:       task.DueAt,
+		},
+	}
+	if validateEstimate(task.Estimate) == nil {
+		item.Task.Estimate = task.Estimate
+	}
+
+	for _, label := range task.Labels {
+		if option, ok := labels[label]; ok && !slices.Contains(item.Labels, option) {
+			item.Labels = append(item.Labels, option)
+		}
+	}
+
+	// comments of unknown authors keep their names in text
+	for _, comment := range task.Comments {
+		text := comment.Text
+		authorID, ok := members[comment.AuthorId]
+		if !ok && comment.Author != "" {
+			text = comment.Author + ": " + text
+		}
+		item.Comments = append(item.Comments, models.Comment{UserId: authorID, Text: text, CreatedAt: comment.CreatedAt})
+	}
+
+	return item, long
+}
+
+// checklists are written as markdown task lists
+func checklistsText(checklists []models.ImportChecklist) string {
+	var sb strings.Builder
+	for _, checklist := range checklists {
+		fmt.Fprintf(&sb, "\n\n**%s**\n", checklist.Name)
+		for _, item := range checklist.Items {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(&sb, "- [%s] %s\n", mark, item.Text)
+		}
+	}
+
+	return sb.String()
+}
+
+// board name is varchar(100)
+func importName(name string, source string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = source
+	}
+
+	if runes := []rune(name); len(runes) > boardNameMaxLength {
+		name = string(runes[:boardNameMaxLength])
+	}
+
+	return name
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	labelsMaxCount     = 100
+	labelNameMaxLength = 32
+)
+
+var labelColorRe = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// LabelsService manages labels of boards, tasks get labels of their board only
+type LabelsService struct {
+	storage LabelsStorager
+}
+
+type LabelsStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetLabels(boardID uint) ([]models.BoardLabel, error)
+	GetLabel(id uint) (*models.BoardLabel, error)
+	CreateLabel(label models.BoardLabel) (*models.BoardLabel, error)
+	UpdateLabel(label models.BoardLabel) (*models.BoardLabel, error)
+	DeleteLabel(label *models.BoardLabel) error
+}
+
+func NewLabelsService(stor LabelsStorager, logger *zap.Logger) *LabelsService {
+	return &LabelsService{
+		storage: stor,
+	}
+}
+
+// labels of board are visible to all its members
+func (t *LabelsService) GetLabels(boardID uint, userID uint) ([]dto.BoardLabelDto, error) {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	labels, err := t.storage.GetLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.BoardLabelDto, 0, len(labels))
+	for i := range labels {
+		res = append(res, *labelToDto(&labels[i]))
+	}
+
+	return res, nil
+}
+
+func (t *LabelsService) CreateLabel(boardID uint, body dto.PostBoardLabelDto, userID uint) (*dto.BoardLabelDto, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	labels, err := t.storage.GetLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(labels) >= labelsMaxCount {
+		return nil, fmt.Errorf("%w: board can have up to %d labels", models.ErrInvalidInput, labelsMaxCount)
+	}
+
+	label, err := labelFromDto(body, labels, 0)
+	if err != nil {
+		return nil, err
+	}
+	label.BoardId = boardID
+
+	created, err := t.storage.CreateLabel(*label)
+	if err != nil {
+		return nil, err
+	}
+
+	return labelToDto(created), nil
+}
+
+// replace name and color of label, tasks keep it
+func (t *LabelsService) UpdateLabel(boardID uint, id uint, body dto.PostBoardLabelDto, userID uint) (*dto.BoardLabelDto, error) {
+	if _, err := t.getOwnLabel(boardID, id, userID); err != nil {
+		return nil, err
+	}
+
+	labels, err := t.storage.GetLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := labelFromDto(body, labels, id)
+	if err != nil {
+		return nil, err
+	}
+	label.ID = id
+
+	updated, err := t.storage.UpdateLabel(*label)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, models.ErrNotFound
+	}
+
+	return labelToDto(updated), nil
+}
+
+// delete label, it is removed from tasks and task templates
+func (t *LabelsService) DeleteLabel(boardID uint, id uint, userID uint) error {
+	label, err := t.getOwnLabel(boardID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return t.storage.DeleteLabel(label)
+}
+
+// get label of board managed by user
+func (t *LabelsService) getOwnLabel(boardID uint, id uint, userID uint) (*models.BoardLabel, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	label, err := t.storage.GetLabel(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if label == nil || label.BoardId != boardID {
+		return nil, models.ErrNotFound
+	}
+
+	return label, nil
+}
+
+// only owner can change labels of board
+func (t *LabelsService) checkOwner(boardID uint, userID uint) error {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return models.ErrNotFound
+	}
+
+	if board.OwnerId != userID {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+// labelFromDto validates label, name must be unique on board except label with id
+func labelFromDto(body dto.PostBoardLabelDto, labels []models.BoardLabel, id uint) (*models.BoardLabel, error) {
+	name := strings.TrimSpace(body.Name)
+	if name == "" || len([]rune(name)) > labelNameMaxLength {
+		return nil, fmt.Errorf("%w: name must be from 1 to %d characters", models.ErrInvalidInput, labelNameMaxLength)
+	}
+
+	for _, label := range labels {
+		if label.ID != id && strings.EqualFold(label.Name, name) {
+			return nil, fmt.Errorf("%w: board already has label %q", models.ErrInvalidInput, label.Name)
+		}
+	}
+
+	color := strings.ToLower(strings.TrimSpace(body.Color))
+	if color != "" && !labelColorRe.MatchString(color) {
+		return nil, fmt.Errorf("%w: color must be #rrggbb", models.ErrInvalidInput)
+	}
+
+	return &models.BoardLabel{
+		Name:  name,
+		Color: color,
+	}, nil
+}
+
+func labelToDto(label *models.BoardLabel) *dto.BoardLabelDto {
+	return &dto.BoardLabelDto{
+		ID:        label.ID,
+		BoardId:   label.BoardId,
+		Name:      label.Name,
+		Color:     label.Color,
+		CreatedAt: label.CreatedAt,
+	}
+}
+
+// labelIDs checks ids are labels of board, repeated ids are dropped. With keep ids of labels
+// which board doesn't have, e.g. after move to another board, are dropped instead
+func labelIDs(labels []models.BoardLabel, ids []uint, keep bool) ([]uint, error) {
+	res := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !slices.ContainsFunc(labels, func(label models.BoardLabel) bool { return label.ID == id }) {
+			if keep {
+				continue
+			}
+			return nil, fmt.Errorf("%w: board has no label %d", models.ErrInvalidInput, id)
+		}
+
+		if !slices.Contains(res, id) {
+			res = append(res, id)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+	"todo/pkg/safehttp"
+
+	_ "time/tzdata" // timezones of users don't depend on system tzdata
+
+	"go.uber.org/zap"
+)
+
+const summaryPollPeriod = time.Minute
+
+// confirmation code of email is valid for a day
+const emailCodeTTL = 24 * time.Hour
+
+// kinds which channels can be chosen for
+var notificationKinds = []string{
+	models.NotifyCreated,
+	models.NotifyAssigned,
+	models.NotifyCommented,
+	models.NotifyDue,
+	models.NotifyStatusChanged,
+	models.NotifyDigest,
+}
+
+type NotificationsService struct {
+	storage NotificationsStorager
+	logger  *zap.Logger
+}
+
+type NotificationsStorager interface {
+	GetSettings(userID uint) (*models.NotificationSettings, error)
+	SaveSettings(settings models.NotificationSettings) error
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetHeldUsers() ([]uint, error)
+	FlushHeld(userID uint) error
+	CreateEmailCode(userID uint, email string, code string, expiresAt time.Time) (bool, error)
+	ConfirmEmail(userID uint, code string) (bool, error)
+}
+
+func NewNotificationsService(stor NotificationsStorager, logger *zap.Logger) *NotificationsService {
+	return &NotificationsService{
+		storage: stor,
+		logger:  logger,
+	}
+}
+
+func (t *NotificationsService) GetSettings(userID uint) (*dto.NotificationSettingsDto, error) {
+	settings, err := t.storage.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsToDto(settings), nil
+}
+
+func (t *NotificationsService) UpdateSettings(body dto.NotificationSettingsDto, userID uint) (*dto.NotificationSettingsDto, error) {
+	settings := models.NotificationSettings{
+		UserId:        userID,
+		Created:       body.Created,
+		Assigned:      body.Assigned,
+		Commented:     body.Commented,
+		Due:           body.Due,
+		StatusChanged: body.StatusChanged,
+		Digest:        body.Digest,
+		Timezone:      body.Timezone,
+		MutedBoards:   body.MutedBoards,
+		Channels:      body.Channels,
+		Email:         strings.TrimSpace(body.Email),
+		WebhookURL:    strings.TrimSpace(body.WebhookURL),
+		WebhookSecret: body.WebhookSecret,
+
+		DigestAnalytics: body.DigestAnalytics,
+	}
+
+	if err := t.validateChannels(&settings); err != nil {
+		return nil, err
+	}
+
+	if settings.Timezone == "" {
+		settings.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", models.ErrInvalidInput, settings.Timezone)
+	}
+
+	if (body.QuietFrom == "") != (body.QuietTo == "") {
+		return nil, fmt.Errorf("%w: quiet_from and quiet_to must be set together", models.ErrInvalidInput)
+	}
+	if body.QuietFrom != "" {
+		from, err := parseClock(body.QuietFrom)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseClock(body.QuietTo)
+		if err != nil {
+			return nil, err
+		}
+		if from != to {
+			settings.QuietFrom, settings.QuietTo = &from, &to
+		}
+	}
+
+	for _, boardID := range settings.MutedBoards {
+		ok, err := t.storage.IsBoardMember(boardID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, models.ErrForbidden
+		}
+	}
+
+	current, err := t.storage.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.storage.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	// new email gets letter with confirmation code, letters go to it only after confirmation
+	settings.EmailConfirmed = current.EmailConfirmed && current.Email == settings.Email
+	if settings.Email != "" && settings.Email != current.Email {
+		if _, err := t.createEmailCode(userID, settings.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	return settingsToDto(&settings), nil
+}
+
+// send new confirmation code to email of user
+func (t *NotificationsService) SendEmailCode(userID uint) error {
+	settings, err := t.storage.GetSettings(userID)
+	if err != nil {
+		return err
+	}
+
+	if settings.Email == "" {
+		return fmt.Errorf("%w: email is not set", models.ErrInvalidInput)
+	}
+	if settings.EmailConfirmed {
+		return fmt.Errorf("%w: email is already confirmed", models.ErrInvalidInput)
+	}
+
+	ok, err := t.createEmailCode(userID, settings.Email)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: code was sent less than a minute ago", models.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+func (t *NotificationsService) ConfirmEmail(body dto.PostEmailConfirmDto, userID uint) (*dto.NotificationSettingsDto, error) {
+	code := strings.TrimSpace(body.Code)
+	if code == "" {
+		return nil, fmt.Errorf("%w: code is required", models.ErrInvalidInput)
+	}
+
+	ok, err := t.storage.ConfirmEmail(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: code is invalid or expired", models.ErrInvalidInput)
+	}
+
+	return t.GetSettings(userID)
+}
+
+// createEmailCode generates code the same way as telegram link code, letter is sent through outbox
+func (t *NotificationsService) createEmailCode(userID uint, email string) (bool, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return false, err
+	}
+
+	return t.storage.CreateEmailCode(userID, email, hex.EncodeToString(buf), time.Now().Add(emailCodeTTL))
+}
+
+// validateChannels checks chosen channels are known and have address
+func (t *NotificationsService) validateChannels(settings *models.NotificationSettings) error {
+	if settings.Email != "" {
+		addr, err := mail.ParseAddress(settings.Email)
+		if err != nil || addr.Name != "" {
+			return fmt.Errorf("%w: invalid email %q", models.ErrInvalidInput, settings.Email)
+		}
+	}
+
+	if settings.WebhookURL != "" {
+		// webhook must not reach internal network, the address is checked again when connecting
+		if _, err := safehttp.CheckURL(context.Background(), settings.WebhookURL); err != nil {
+			return fmt.Errorf("%w: webhook_url %v", models.ErrInvalidInput, err)
+		}
+
+		// webhooks are always signed, so secret must be set once
+		if settings.WebhookSecret == "" {
+			current, err := t.storage.GetSettings(settings.UserId)
+			if err != nil {
+				return err
+			}
+			if current.WebhookSecret == "" {
+				return fmt.Errorf("%w: webhook_secret is required with webhook_url", models.ErrInvalidInput)
+			}
+		}
+	}
+
+	for kind, channels := range settings.Channels {
+		if !slices.Contains(notificationKinds, kind) {
+			return fmt.Errorf("%w: unknown notification kind %q", models.ErrInvalidInput, kind)
+		}
+
+		for _, channel := range channels {
+			switch channel {
+			case models.ChannelTelegram:
+			case models.ChannelEmail:
+				if settings.Email == "" {
+					return fmt.Errorf("%w: email is required for email channel", models.ErrInvalidInput)
+				}
+			case models.ChannelWebhook:
+				if settings.WebhookURL == "" {
+					return fmt.Errorf("%w: webhook_url is required for webhook channel", models.ErrInvalidInput)
+				}
+			default:
+				return fmt.Errorf("%w: unknown channel %q", models.ErrInvalidInput, channel)
+			}
+		}
+	}
+
+	return nil
+}
+
+// send summaries of held notifications to users whose quiet hours ended
+func (t *NotificationsService) StartSummaries() {
+	go func() {
+		for range time.Tick(summaryPollPeriod) {
+			t.FlushSummaries()
+		}
+	}()
+}
+
+func (t *NotificationsService) FlushSummaries() {
+	users, err := t.storage.GetHeldUsers()
+	if err != nil {
+		t.logger.Error("get users with held notifications", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, userID := range users {
+		settings, err := t.storage.GetSettings(userID)
+		if err != nil {
+			t.logger.Error("get notification settings", zap.Uint("user_id", userID), zap.Error(err))
+			continue
+		}
+
+		if quietNow(settings, now) {
+			continue
+		}
+
+		if err := t.storage.FlushHeld(userID); err != nil {
+			t.logger.Error("queue notifications summary", zap.Uint("user_id", userID), zap.Error(err))
+		}
+	}
+}
+
+// notificationEnabled checks kind of notification and board are not muted by user.
+// Empty kind is always enabled
+func notificationEnabled(settings *models.NotificationSettings, kind string, boardID uint) bool {
+	for _, muted := range settings.MutedBoards {
+		if boardID != 0 && muted == boardID {
+			return false
+		}
+	}
+
+	switch kind {
+	case models.NotifyCreated:
+		return settings.Created
+	case models.NotifyAssigned:
+		return settings.Assigned
+	case models.NotifyCommented:
+		return settings.Commented
+	case models.NotifyDue:
+		return settings.Due
+	case models.NotifyStatusChanged:
+		return settings.StatusChanged
+	case models.NotifyDigest:
+		return settings.Digest
+	}
+
+	return true
+}
+
+// channelsFor returns channels chosen for kind, telegram by default
+func channelsFor(settings *models.NotificationSettings, kind string) []string {
+	if channels := settings.Channels[kind]; len(channels) > 0 {
+		return channels
+	}
+
+	return []string{models.ChannelTelegram}
+}
+
+// quietNow checks now is inside quiet hours in timezone of user, they may cross midnight
+func quietNow(settings *models.NotificationSettings, now time.Time) bool {
+	if settings.QuietFrom == nil || settings.QuietTo == nil {
+		return false
+	}
+
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	from, to := *settings.QuietFrom, *settings.QuietTo
+
+	if from < to {
+		return minute >= from && minute < to
+	}
+
+	return minute >= from || minute < to
+}
+
+// parseClock parses "HH:MM" to minutes from midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time must be HH:MM, got %q", models.ErrInvalidInput, value)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(minutes *int) string {
+	if minutes == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%02d:%02d", *minutes/60, *minutes%60)
+}
+
+func settingsToDto(settings *models.NotificationSettings) *dto.NotificationSettingsDto {
+	muted := settings.MutedBoards
+	if muted == nil {
+		muted = []uint{}
+	}
+
+	channels := settings.Channels
+	if channels == nil {
+		channels = map[string][]string{}
+	}
+
+	return &dto.NotificationSettingsDto{
+		Created:       settings.Created,
+		Assigned:      settings.Assigned,
+		Commented:     settings.Commented,
+		Due:           settings.Due,
+		StatusChanged: settings.StatusChanged,
+		Digest:        settings.Digest,
+		QuietFrom:     formatClock(settings.QuietFrom),
+		QuietTo:       formatClock(settings.QuietTo),
+		Timezone:      settings.Timezone,
+		MutedBoards:   muted,
+		Channels:      channels,
+		Email:         settings.Email,
+		WebhookURL:    settings.WebhookURL,
+
+		EmailConfirmed:  settings.EmailConfirmed,
+		DigestAnalytics: settings.DigestAnalytics,
+	}
+}
@@ -0,0 +1,596 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo/internal/todo/api"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+	"todo/internal/todo/notify"
+
+	"go.uber.org/zap"
+)
+
+const (
+	outboxWorkers    = 10
+	outboxBatchSize  = 3 * outboxWorkers
+	outboxPollPeriod = 2 * time.Second
+	// the longest delivery through one channel, request to tg service waits for rate limits of telegram
+	outboxSendTimeout = 2 * time.Minute
+	// every worker delivers its share of batch before the lease ends, so messages aren't claimed twice
+	outboxLease       = (outboxBatchSize/outboxWorkers + 1) * outboxSendTimeout
+	outboxMaxAttempts = 10
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+type OutboxService struct {
+	storage   OutboxStorager
+	notifiers map[string]notify.Notifier // by channel
+	logger    *zap.Logger
+}
+
+type OutboxStorager interface {
+	ClaimDue(limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	MarkSent(id uint, status string) error
+	MarkFailed(id uint, lastError string, nextAttemptAt time.Time, dead bool) error
+	GetOutbox(status string) ([]models.OutboxMessage, error)
+	ReplayMessage(id uint) (*models.OutboxMessage, error)
+	GetChatIDByUser(userID uint) (*int64, error)
+	GetChatIDByBoard(boardID uint) (*int64, error)
+	DeactivateTelegram(userID uint, chatID int64) error
+	UnlinkBoardChat(boardID uint, chatID int64) error
+	GetNotificationSettings(userID uint) (*models.NotificationSettings, error)
+	HoldNotification(userID uint, text string, key string) error
+	Enqueue(eventType string, key string, payload any) error
+}
+
+// errSkip marks a message which can't be delivered and should not be retried
+var errSkip = fmt.Errorf("nothing to deliver")
+
+func NewOutboxService(stor OutboxStorager, notifiers map[string]notify.Notifier, logger *zap.Logger) *OutboxService {
+	return &OutboxService{
+		storage:   stor,
+		notifiers: notifiers,
+		logger:    logger,
+	}
+}
+
+// run dispatcher loop in background
+func (t *OutboxService) StartDispatcher() {
+	go func() {
+		for range time.Tick(outboxPollPeriod) {
+			t.Dispatch()
+		}
+	}()
+}
+
+// deliver all due messages once by a pool of workers, so slow channel holds only its worker
+func (t *OutboxService) Dispatch() {
+	messages, err := t.storage.ClaimDue(outboxBatchSize, outboxLease)
+	if err != nil {
+		t.logger.Error("claim outbox messages", zap.Error(err))
+		return
+	}
+
+	queue := make(chan models.OutboxMessage)
+	var wg sync.WaitGroup
+	for range min(outboxWorkers, len(messages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for message := range queue {
+				t.process(message)
+			}
+		}()
+	}
+
+	for _, message := range messages {
+		queue <- message
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// process delivers message and writes result of the attempt
+func (t *OutboxService) process(message models.OutboxMessage) {
+	status, err := t.deliver(message)
+	switch {
+	case err == nil:
+		err = t.storage.MarkSent(message.ID, status)
+	case err == errSkip:
+		err = t.storage.MarkSent(message.ID, models.OutboxSkipped)
+	default:
+		attempts := message.Attempts + 1
+		dead := attempts >= outboxMaxAttempts
+		if dead {
+			t.logger.Error("outbox message moved to dead letters", zap.Uint("id", message.ID), zap.Error(err))
+		} else {
+			t.logger.Warn("outbox delivery failed", zap.Uint("id", message.ID), zap.Int("attempt", attempts), zap.Error(err))
+		}
+		err = t.storage.MarkFailed(message.ID, err.Error(), time.Now().Add(retryDelay(err, attempts)), dead)
+	}
+
+	if err != nil {
+		t.logger.Error("update outbox message", zap.Uint("id", message.ID), zap.Error(err))
+	}
+}
+
+// deliver sends message and returns its final status
+func (t *OutboxService) deliver(message models.OutboxMessage) (string, error) {
+	key := message.IdempotencyKey
+	n := dto.NotificationDto{Event: message.EventType, Payload: message.Payload}
+
+	switch message.EventType {
+	case models.EventTaskReminder:
+		var event dto.TaskEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		// reminder was asked by user himself, so only quiet hours delay it
+		n.Subject = fmt.Sprintf("Напоминание о задаче #%d «%s»", event.TaskId, event.Title)
+		n.Text = taskText(n.Subject, event.Description)
+		return t.notifyUser(event.UserId, 0, n, key)
+	case models.EventTaskCreated:
+		var event dto.TaskEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		n.Kind, n.Subject = models.NotifyCreated, fmt.Sprintf("Новая задача #%d «%s»", event.TaskId, event.Title)
+		if event.ActorId != 0 && event.ActorId != event.UserId {
+			n.Kind, n.Subject = models.NotifyAssigned, fmt.Sprintf("Вам назначена задача #%d «%s»", event.TaskId, event.Title)
+		}
+		n.Text = taskText(n.Subject, event.Description)
+
+		// new task goes to assignee and to board chat
+		userStatus, err := t.notifyUser(event.UserId, event.BoardId, n, key)
+		if err != nil && err != errSkip {
+			return "", err
+		}
+
+		boardStatus, err := t.notifyBoard(event.BoardId, n, key)
+		if err != nil && err != errSkip {
+			return "", err
+		}
+
+		return mergeStatus(userStatus, boardStatus)
+	case models.EventTaskAssigned:
+		var event dto.TaskEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		if event.UserId == 0 || event.UserId == event.ActorId {
+			return "", errSkip
+		}
+
+		n.Kind, n.Subject = models.NotifyAssigned, fmt.Sprintf("Вам назначена задача #%d «%s»", event.TaskId, event.Title)
+		n.Text = taskText(n.Subject, event.Description)
+		return t.notifyUser(event.UserId, event.BoardId, n, key)
+	case models.EventTaskDue:
+		var event dto.TaskEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		n.Kind, n.Subject = models.NotifyDue, fmt.Sprintf("Подходит срок задачи #%d «%s»", event.TaskId, event.Title)
+		n.Text = taskText(n.Subject, event.Description)
+		return t.notifyUser(event.UserId, event.BoardId, n, key)
+	case models.EventTaskStatusChanged:
+		var event dto.TaskEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		n.Kind = models.NotifyStatusChanged
+		n.Subject = fmt.Sprintf("Задача #%d «%s» теперь в статусе «%s»", event.TaskId, event.Title, event.Status)
+		n.Text = taskText(n.Subject, event.Description)
+
+		// assignee is not notified about his own changes
+		userStatus := models.OutboxSkipped
+		if event.UserId != 0 && event.UserId != event.ActorId {
+			var err error
+			userStatus, err = t.notifyUser(event.UserId, event.BoardId, n, key)
+			if err != nil && err != errSkip {
+				return "", err
+			}
+		}
+
+		boardStatus, err := t.notifyBoard(event.BoardId, n, key)
+		if err != nil && err != errSkip {
+			return "", err
+		}
+
+		return mergeStatus(userStatus, boardStatus)
+	case models.EventCommentCreated:
+		var event dto.CommentEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		n.Kind = models.NotifyCommented
+		n.Subject = fmt.Sprintf("%s прокомментировал задачу #%d «%s»", event.Author, event.TaskId, event.Title)
+		n.Text = taskText(n.Subject, event.Text)
+
+		userStatus := models.OutboxSkipped
+		if event.UserId != 0 && event.UserId != event.AuthorId {
+			var err error
+			userStatus, err = t.notifyUser(event.UserId, event.BoardId, n, key)
+			if err != nil && err != errSkip {
+				return "", err
+			}
+		}
+
+		boardStatus, err := t.notifyBoard(event.BoardId, n, key)
+		if err != nil && err != errSkip {
+			return "", err
+		}
+
+		return mergeStatus(userStatus, boardStatus)
+	case models.EventTasksBulk:
+		var event dto.BulkEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		if event.UserId == 0 {
+			n.Subject = fmt.Sprintf("Изменено задач: %d", len(event.Changes))
+			n.Text = bulkText(n.Subject, event.Changes, false)
+			return t.notifyBoard(event.BoardId, n, key)
+		}
+
+		return t.notifyBulk(event, n, key)
+	case models.EventDigest:
+		var event dto.DigestEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		n.Kind = models.NotifyDigest
+		n.Subject = fmt.Sprintf("Ежедневная сводка: текущих задач %d, выполнено за день %d", len(event.Current), len(event.Done))
+		n.Text = digestText(event)
+		return t.notifyUser(event.UserId, 0, n, key)
+	case models.EventSummary:
+		var event dto.SummaryEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		settings, err := t.storage.GetNotificationSettings(event.UserId)
+		if err != nil {
+			return "", err
+		}
+
+		// summary goes the same way as daily digest
+		n.Subject = "Уведомления за время тихих часов"
+		n.Text = event.Text
+		return t.fanOut(event.UserId, settings, channelsFor(settings, models.NotifyDigest), n, key)
+	case models.EventEmailConfirm:
+		var event dto.EmailConfirmEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		// address isn't confirmed yet, so letter goes past settings of user
+		email, ok := t.notifiers[models.ChannelEmail]
+		if !ok {
+			return "", errSkip
+		}
+
+		n.Subject = "Подтверждение адреса для уведомлений"
+		n.Text = fmt.Sprintf("Код подтверждения: %s\n\nОтправьте его в POST /api/user/notifications/email/confirm. Если вы не указывали этот адрес, просто проигнорируйте письмо.", event.Code)
+		return models.OutboxSent, email.Notify(notify.Recipient{UserId: event.UserId, Email: event.Email}, n, key)
+	case models.EventDeliver:
+		var event dto.DeliveryEventDto
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return "", err
+		}
+
+		return models.OutboxSent, t.send(event, key)
+	default:
+		return "", fmt.Errorf("unknown event type %q", message.EventType)
+	}
+}
+
+// notifyUser checks settings of user and queues delivery through his channels.
+// During quiet hours only subject is kept for the summary
+func (t *OutboxService) notifyUser(userID uint, boardID uint, n dto.NotificationDto, key string) (string, error) {
+	settings, err := t.storage.GetNotificationSettings(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if !notificationEnabled(settings, n.Kind, boardID) {
+		return "", errSkip
+	}
+
+	if quietNow(settings, time.Now()) {
+		if err := t.storage.HoldNotification(userID, n.Subject, key); err != nil {
+			return "", err
+		}
+		return models.OutboxHeld, nil
+	}
+
+	return t.fanOut(userID, settings, channelsFor(settings, n.Kind), n, key)
+}
+
+// notifyBulk sends changes enabled by settings of user in one message,
+// it goes through channels of all kinds of changes
+func (t *OutboxService) notifyBulk(event dto.BulkEventDto, n dto.NotificationDto, key string) (string, error) {
+	settings, err := t.storage.GetNotificationSettings(event.UserId)
+	if err != nil {
+		return "", err
+	}
+
+	var changes []dto.BulkChangeDto
+	var channels []string
+	for _, change := range event.Changes {
+		if !notificationEnabled(settings, change.Kind, change.BoardId) {
+			continue
+		}
+		changes = append(changes, change)
+
+		for _, channel := range channelsFor(settings, change.Kind) {
+			if !slices.Contains(channels, channel) {
+				channels = append(channels, channel)
+			}
+		}
+	}
+
+	if len(changes) == 0 {
+		return "", errSkip
+	}
+
+	n.Subject = fmt.Sprintf("Изменено ваших задач: %d", len(changes))
+	n.Text = bulkText(n.Subject, changes, true)
+
+	if quietNow(settings, time.Now()) {
+		if err := t.storage.HoldNotification(event.UserId, n.Subject, key); err != nil {
+			return "", err
+		}
+		return models.OutboxHeld, nil
+	}
+
+	return t.fanOut(event.UserId, settings, channels, n, key)
+}
+
+// fanOut queues separate delivery for every channel, so they are retried independently
+func (t *OutboxService) fanOut(userID uint, settings *models.NotificationSettings, channels []string, n dto.NotificationDto, key string) (string, error) {
+	to, err := t.recipient(userID, settings)
+	if err != nil {
+		return "", err
+	}
+
+	queued := 0
+	for _, channel := range channels {
+		if _, ok := t.notifiers[channel]; !ok {
+			t.logger.Warn("notification channel is not configured", zap.String("channel", channel), zap.Uint("user_id", userID))
+			continue
+		}
+
+		if !to.Has(channel) {
+			continue
+		}
+
+		delivery := dto.DeliveryEventDto{Channel: channel, UserId: userID, Notification: n}
+		if err := t.storage.Enqueue(models.EventDeliver, key+":"+channel, delivery); err != nil {
+			return "", err
+		}
+		queued++
+	}
+
+	if queued == 0 {
+		return "", errSkip
+	}
+
+	return models.OutboxSent, nil
+}
+
+// send delivers notification through one channel
+func (t *OutboxService) send(delivery dto.DeliveryEventDto, key string) error {
+	notifier, ok := t.notifiers[delivery.Channel]
+	if !ok {
+		return errSkip
+	}
+
+	settings, err := t.storage.GetNotificationSettings(delivery.UserId)
+	if err != nil {
+		return err
+	}
+
+	to, err := t.recipient(delivery.UserId, settings)
+	if err != nil {
+		return err
+	}
+
+	err = notifier.Notify(*to, delivery.Notification, key)
+	if errors.Is(err, notify.ErrNoAddress) {
+		return errSkip
+	}
+
+	// user blocked bot or deleted chat, telegram is off till he sends /start again
+	if errors.Is(err, notify.ErrBlocked) && to.ChatId != nil {
+		t.logger.Info("telegram link is inactive", zap.Uint("user_id", delivery.UserId), zap.Error(err))
+		if err := t.storage.DeactivateTelegram(delivery.UserId, *to.ChatId); err != nil {
+			return err
+		}
+		return errSkip
+	}
+
+	return err
+}
+
+// notifyBoard posts event to group chat of board
+func (t *OutboxService) notifyBoard(boardID uint, n dto.NotificationDto, key string) (string, error) {
+	chatID, err := t.storage.GetChatIDByBoard(boardID)
+	if err != nil {
+		return "", err
+	}
+	if chatID == nil {
+		return "", errSkip
+	}
+
+	telegram, ok := t.notifiers[models.ChannelTelegram]
+	if !ok {
+		return "", errSkip
+	}
+
+	// on retry the chat which already got the message drops it by idempotency key
+	err = telegram.Notify(notify.Recipient{ChatId: chatID}, n, key+":board")
+
+	// bot was removed from group, owner links chat again by /linkboard
+	if errors.Is(err, notify.ErrBlocked) {
+		t.logger.Info("board chat is unlinked", zap.Uint("board_id", boardID), zap.Error(err))
+		if err := t.storage.UnlinkBoardChat(boardID, *chatID); err != nil {
+			return "", err
+		}
+		return "", errSkip
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return models.OutboxSent, nil
+}
+
+func (t *OutboxService) recipient(userID uint, settings *models.NotificationSettings) (*notify.Recipient, error) {
+	chatID, err := t.storage.GetChatIDByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// letters go only to confirmed email
+	email := settings.Email
+	if !settings.EmailConfirmed {
+		email = ""
+	}
+
+	return &notify.Recipient{
+		UserId:        userID,
+		ChatId:        chatID,
+		Email:         email,
+		WebhookURL:    settings.WebhookURL,
+		WebhookSecret: settings.WebhookSecret,
+	}, nil
+}
+
+// mergeStatus gives status of message delivered to user and board chat
+func mergeStatus(userStatus string, boardStatus string) (string, error) {
+	switch {
+	case userStatus == models.OutboxSent || boardStatus == models.OutboxSent:
+		return models.OutboxSent, nil
+	case userStatus == models.OutboxHeld:
+		return models.OutboxHeld, nil
+	}
+
+	return "", errSkip
+}
+
+func taskText(subject string, description string) string {
+	if description == "" {
+		return subject
+	}
+
+	return subject + "\n\n" + description
+}
+
+func bulkText(subject string, changes []dto.BulkChangeDto, personal bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(subject + "\n")
+	for _, change := range changes {
+		fmt.Fprintf(&sb, "• #%d «%s» — ", change.TaskId, change.Title)
+		switch {
+		case change.Kind == models.NotifyStatusChanged:
+			fmt.Fprintf(&sb, "статус «%s»\n", change.Status)
+		case personal:
+			sb.WriteString("назначена вам\n")
+		default:
+			sb.WriteString("новый исполнитель\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func digestText(event dto.DigestEventDto) string {
+	var sb strings.Builder
+
+	sb.WriteString("Ваши задачи:\n")
+	if len(event.Current) == 0 {
+		sb.WriteString("нет задач\n")
+	}
+	for _, task := range event.Current {
+		fmt.Fprintf(&sb, "• #%d %s\n", task.TaskId, task.Title)
+	}
+
+	sb.WriteString("\nВыполнено за день:\n")
+	if len(event.Done) == 0 {
+		sb.WriteString("нет задач\n")
+	}
+	for _, task := range event.Done {
+		fmt.Fprintf(&sb, "• #%d %s\n", task.TaskId, task.Title)
+	}
+
+	if len(event.Weekly) > 0 {
+		sb.WriteString("\n" + notify.WeeklyText(event.Weekly))
+	}
+
+	return sb.String()
+}
+
+// retryDelay is backoff, but not shorter than telegram asked to wait
+func retryDelay(err error, attempts int) time.Duration {
+	delay := backoff(attempts)
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		return apiErr.RetryAfter
+	}
+
+	return delay
+}
+
+// exponential backoff: 5s, 10s, 20s ... capped by an hour
+func backoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+
+	return delay
+}
+
+func (t *OutboxService) GetOutbox(status string) ([]models.OutboxMessage, error) {
+	messages, err := t.storage.GetOutbox(status)
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (t *OutboxService) ReplayMessage(id string) (*models.OutboxMessage, error) {
+	Uintid, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := t.storage.ReplayMessage(uint(Uintid))
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
@@ -1,6 +1,12 @@
 package services
 
 import (
+	"fmt"
+	"todo/internal/todo/blob"
+	"todo/internal/todo/config"
+	"todo/internal/todo/models"
+	"todo/internal/todo/notify"
+
 	"go.uber.org/zap"
 )
 
@@ -9,6 +15,22 @@ type TodoService struct {
 	StatusesService StatusesService
 	TasksService    TasksService
 	UserService     UserService
+	OutboxService   OutboxService
+
+	NotificationsService NotificationsService
+	WebhooksService      WebhooksService
+	EventsService        *EventsService
+	TimeService          TimeService
+	AnalyticsService     AnalyticsService
+	FieldsService        FieldsService
+	LabelsService        LabelsService
+	TemplatesService     TemplatesService
+	TransfersService     TransfersService
+	BulkService          BulkService
+	ExportService        ExportService
+	ImportService        ImportService
+	CalendarService      CalendarService
+	AttachmentsService   AttachmentsService
 }
 
 type Storager struct {
@@ -16,6 +38,22 @@ type Storager struct {
 	StatusesStorager StatusesStorager
 	TasksStorager    TasksStorager
 	UserStorager     UserStorager
+	OutboxStorager   OutboxStorager
+
+	NotificationsStorager NotificationsStorager
+	WebhooksStorager      WebhooksStorager
+	EventsStorager        EventsStorager
+	TimeStorager          TimeStorager
+	AnalyticsStorager     AnalyticsStorager
+	FieldsStorager        FieldsStorager
+	LabelsStorager        LabelsStorager
+	TemplatesStorager     TemplatesStorager
+	TransfersStorager     TransfersStorager
+	BulkStorager          BulkStorager
+	ExportStorager        ExportStorager
+	ImportStorager        ImportStorager
+	CalendarStorager      CalendarStorager
+	AttachmentsStorager   AttachmentsStorager
 }
 
 func New(stor Storager, log *zap.Logger) *TodoService {
@@ -24,5 +62,69 @@ func New(stor Storager, log *zap.Logger) *TodoService {
 		StatusesService: *NewStatusesService(stor.StatusesStorager, log),
 		TasksService:    *NewTasksService(stor.TasksStorager, log),
 		UserService:     *NewUserService(stor.UserStorager, log),
+		OutboxService:   *NewOutboxService(stor.OutboxStorager, notifiers(log), log),
+
+		NotificationsService: *NewNotificationsService(stor.NotificationsStorager, log),
+		WebhooksService:      *NewWebhooksService(stor.WebhooksStorager, log),
+		EventsService:        NewEventsService(stor.EventsStorager, log),
+		TimeService:          *NewTimeService(stor.TimeStorager, log),
+		AnalyticsService:     *NewAnalyticsService(stor.AnalyticsStorager, log),
+		FieldsService:        *NewFieldsService(stor.FieldsStorager, log),
+		LabelsService:        *NewLabelsService(stor.LabelsStorager, log),
+		TemplatesService:     *NewTemplatesService(stor.TemplatesStorager, log),
+		TransfersService:     *NewTransfersService(stor.TransfersStorager, log),
+		BulkService:          *NewBulkService(stor.BulkStorager, stor.TransfersStorager, log),
+		ExportService:        *NewExportService(stor.ExportStorager, log),
+		ImportService:        *NewImportService(stor.ImportStorager, log),
+		CalendarService:      *NewCalendarService(stor.CalendarStorager, log),
+		AttachmentsService:   *NewAttachmentsService(stor.AttachmentsStorager, blobStore(log), log),
+	}
+}
+
+// notifiers returns channels available for notifications, email needs SMTP_HOST
+func notifiers(log *zap.Logger) map[string]notify.Notifier {
+	n := map[string]notify.Notifier{
+		models.ChannelTelegram: notify.NewTelegram(),
+		models.ChannelWebhook:  notify.NewWebhook(),
+	}
+
+	cfg := config.AppConfig
+	if cfg != nil && cfg.SMTPHost != "" {
+		n[models.ChannelEmail] = notify.NewEmail(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom)
+	} else {
+		log.Info("SMTP_HOST is not set, email notifications are disabled")
 	}
+
+	return n
+}
+
+// blobStore returns storage of attachment files chosen by BLOB_STORE, local directory by default
+func blobStore(log *zap.Logger) blob.BlobStore {
+	cfg := config.AppConfig
+	if cfg == nil {
+		cfg = &config.Config{BlobStore: "local", BlobDir: "data/attachments"}
+	}
+
+	var store blob.BlobStore
+	var err error
+	switch cfg.BlobStore {
+	case "local":
+		store, err = blob.NewLocal(cfg.BlobDir)
+	case "s3":
+		store, err = blob.NewS3(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	default:
+		err = fmt.Errorf("unknown BLOB_STORE %q, use local or s3", cfg.BlobStore)
+	}
+
+	if err != nil {
+		log.Fatal("init blob store", zap.Error(err))
+	}
+
+	return store
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
 
 	"go.uber.org/zap"
 )
@@ -14,6 +15,7 @@ type StatusesService struct {
 
 type StatusesStorager interface {
 	SetStatus(body dto.PostStatusDto) error
+	GetAllStatuses() ([]models.Status, error)
 	DeleteStatus(id uint) error
 }
 
@@ -32,6 +34,15 @@ func (t *StatusesService) SetStatus(body dto.PostStatusDto) error {
 	return nil
 }
 
+func (t *StatusesService) GetAllStatuses() ([]models.Status, error) {
+	statuses, err := t.storage.GetAllStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
 func (t *StatusesService) DeleteStatus(id string) error {
 	if id == "" {
 		return fmt.Errorf("status ID is required")
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
+	"time"
 	"todo/internal/todo/api"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
@@ -15,35 +18,200 @@ type TasksService struct {
 }
 
 type TasksStorager interface {
-	SetTask(body dto.PostTaskDto) (*models.Task, error)
+	SetTask(body dto.PostTaskDto, authorID uint) (*models.Task, error)
 	GetTask(id uint) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
-	UpdateTask(body dto.PostTaskDto, id uint) (*models.Task, error)
-	DeleteTask(id uint) error
-	GetChatID(task *models.Task) (*int64, error)
-	GetMyTasks(tgName string, status int) ([]models.Task, *int64, error)
+	GetAllTasks(userID uint, filter dto.TaskFilterDto) ([]models.Task, error)
+	UpdateTask(body dto.PostTaskDto, id uint, actorID uint) (*models.Task, error)
+	UpdateTaskStatus(id uint, statusID uint, actorID uint) (*models.Task, error)
+	AddComment(taskID uint, userID uint, text string) (*models.Comment, error)
+	GetComments(taskID uint) ([]models.Comment, error)
+	SnoozeTask(task *models.Task, userID uint, at time.Time) error
+	DeleteTask(id uint, actorID uint) error
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetMyTasks(userID uint, status int) ([]models.Task, error)
+	GetTgUser(tgUserID int64) (*models.TgUser, error)
 	ChangeEndedTasksStatus() error
 	GetAllUsers() ([]models.TgUser, error)
+	QueueDueReminders(before time.Time) error
+	QueueDigest(event dto.DigestEventDto) error
+	GetNotificationSettings(userID uint) (*models.NotificationSettings, error)
+	GetWeeklyStats(userID uint, since time.Time) ([]models.BoardWeekStats, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardField(id uint) (*models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetStatuses() ([]models.Status, error)
 }
 
+// snooze is limited by a month
+const maxSnoozeMinutes = 30 * 24 * 60
+
+// estimate fits into numeric(8, 2) with a margin
+const maxEstimate = 10000
+
+// user is reminded about task an hour before its due date
+const dueReminderBefore = time.Hour
+
+// conditions on custom fields in one task listing
+const maxFieldFilters = 10
+
 func NewTasksService(stor TasksStorager, logger *zap.Logger) *TasksService {
 	return &TasksService{
 		storage: stor,
 	}
 }
 
-func (t *TasksService) SetTask(body dto.PostTaskDto) error {
-	task, err := t.storage.SetTask(body)
+func (t *TasksService) SetTask(body dto.PostTaskDto, userID uint) (*models.Task, error) {
+	boardID, err := strconv.ParseUint(body.BoardId, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid board_id", models.ErrInvalidInput)
+	}
+
+	if err := t.checkMember(uint(boardID), userID); err != nil {
+		return nil, err
+	}
+
+	// task is assigned to its author by default
+	if body.UserId == "" {
+		body.UserId = strconv.FormatUint(uint64(userID), 10)
+	}
+
+	if err := t.checkAssignee(uint(boardID), body.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := validatePriority(body.Priority); err != nil {
+		return nil, err
+	}
+
+	if err := validateEstimate(body.Estimate); err != nil {
+		return nil, err
+	}
+
+	if body.Fields, err = t.taskFields(uint(boardID), body.Fields, false); err != nil {
+		return nil, err
+	}
+
+	if body.Labels, err = t.taskLabels(uint(boardID), body.Labels, false); err != nil {
+		return nil, err
+	}
+
+	// notification is queued in outbox together with the task
+	task, err := t.storage.SetTask(body, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (t *TasksService) GetTask(id uint, userID uint) (*models.Task, error) {
+	task, err := t.storage.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == nil {
+		return nil, nil
+	}
+
+	if err := t.checkMember(task.BoardId, userID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (t *TasksService) GetAllTasks(userID uint, filter dto.TaskFilterDto) ([]models.Task, error) {
+	if len(filter.Fields) > maxFieldFilters {
+		return nil, fmt.Errorf("%w: up to %d field filters", models.ErrInvalidInput, maxFieldFilters)
+	}
+
+	// handler gives raw values from query, they are parsed by type of field
+	for i, condition := range filter.Fields {
+		field, err := t.storage.GetBoardField(condition.FieldId)
+		if err != nil {
+			return nil, err
+		}
+
+		if field == nil {
+			return nil, fmt.Errorf("%w: unknown field %d", models.ErrInvalidInput, condition.FieldId)
+		}
+
+		if err := t.checkMember(field.BoardId, userID); err != nil {
+			return nil, err
+		}
+
+		if filter.Fields[i], err = fieldFilter(field, condition.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks, err := t.storage.GetAllTasks(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, err
+}
+
+func (t *TasksService) UpdateTask(body dto.PostTaskDto, id uint, userID uint) error {
+	current, err := t.getMemberTask(id, userID)
 	if err != nil {
 		return err
 	}
 
-	chatID, err := t.storage.GetChatID(task)
+	// board is changed only by move-to-board, which maps statuses, fields and labels
+	boardID, err := strconv.ParseUint(body.BoardId, 10, 32)
 	if err != nil {
+		return fmt.Errorf("%w: invalid board_id", models.ErrInvalidInput)
+	}
+
+	if uint(boardID) != current.BoardId {
+		return fmt.Errorf("%w: task is moved to another board by POST /tasks/{id}/move-to-board", models.ErrInvalidInput)
+	}
+
+	// assignee is kept when it isn't given
+	if body.UserId == "" {
+		body.UserId = strconv.FormatUint(uint64(current.UserId), 10)
+	}
+
+	if err := t.checkAssignee(uint(boardID), body.UserId); err != nil {
+		return err
+	}
+
+	if err := t.checkStatus(body.StatusId); err != nil {
 		return err
 	}
 
-	err = api.Create(*task, *chatID)
+	if err := validatePriority(body.Priority); err != nil {
+		return err
+	}
+
+	if err := validateEstimate(body.Estimate); err != nil {
+		return err
+	}
+
+	// without fields in body the current values are kept
+	keep := body.Fields == nil
+	if keep {
+		body.Fields = current.CustomFields
+	}
+
+	if body.Fields, err = t.taskFields(uint(boardID), body.Fields, keep); err != nil {
+		return err
+	}
+
+	// labels are kept the same way as fields
+	keep = body.Labels == nil
+	if keep {
+		body.Labels = current.Labels
+	}
+
+	if body.Labels, err = t.taskLabels(uint(boardID), body.Labels, keep); err != nil {
+		return err
+	}
+
+	_, err = t.storage.UpdateTask(body, id, userID)
 	if err != nil {
 		return err
 	}
@@ -51,8 +219,16 @@ func (t *TasksService) SetTask(body dto.PostTaskDto) error {
 	return nil
 }
 
-func (t *TasksService) GetTask(id uint) (*models.Task, error) {
-	task, err := t.storage.GetTask(id)
+func (t *TasksService) UpdateTaskStatus(id uint, statusID uint, userID uint) (*models.Task, error) {
+	if _, err := t.getMemberTask(id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := t.checkStatus(statusID); err != nil {
+		return nil, err
+	}
+
+	task, err := t.storage.UpdateTaskStatus(id, statusID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -60,31 +236,57 @@ func (t *TasksService) GetTask(id uint) (*models.Task, error) {
 	return task, nil
 }
 
-func (t *TasksService) GetAllTasks() ([]models.Task, error) {
-	tasks, err := t.storage.GetAllTasks()
+func (t *TasksService) AddComment(id uint, text string, userID uint) (*models.Comment, error) {
+	if _, err := t.getMemberTask(id, userID); err != nil {
+		return nil, err
+	}
+
+	comment, err := t.storage.AddComment(id, userID, text)
 	if err != nil {
 		return nil, err
 	}
 
-	return tasks, err
+	return comment, nil
+}
+
+func (t *TasksService) GetComments(id uint, userID uint) ([]models.Comment, error) {
+	if _, err := t.getMemberTask(id, userID); err != nil {
+		return nil, err
+	}
+
+	comments, err := t.storage.GetComments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
 }
 
-func (t *TasksService) UpdateTask(body dto.PostTaskDto, id uint) error {
-	_, err := t.storage.UpdateTask(body, id)
+// remind user about task after given time
+func (t *TasksService) SnoozeTask(id uint, minutes int, userID uint) error {
+	if minutes <= 0 || minutes > maxSnoozeMinutes {
+		return fmt.Errorf("%w: minutes must be from 1 to %d", models.ErrInvalidInput, maxSnoozeMinutes)
+	}
+
+	task, err := t.getMemberTask(id, userID)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return t.storage.SnoozeTask(task, userID, time.Now().Add(time.Duration(minutes)*time.Minute))
 }
 
-func (t *TasksService) DeleteTask(id string) error {
+func (t *TasksService) DeleteTask(id string, userID uint) error {
 	Uintid, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return err
 	}
 
-	err = t.storage.DeleteTask(uint(Uintid))
+	if _, err := t.getMemberTask(uint(Uintid), userID); err != nil {
+		return err
+	}
+
+	err = t.storage.DeleteTask(uint(Uintid), userID)
 	if err != nil {
 		return err
 	}
@@ -92,23 +294,179 @@ func (t *TasksService) DeleteTask(id string) error {
 	return nil
 }
 
-func (t *TasksService) SendAllTasks(tgName string, chatID int64) error {
-	message, _, err := t.storage.GetMyTasks(tgName, 1)
+// get task and check user is a member of its board
+func (t *TasksService) getMemberTask(id uint, userID uint) (*models.Task, error) {
+	task, err := t.storage.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == nil {
+		return nil, models.ErrNotFound
+	}
+
+	if err := t.checkMember(task.BoardId, userID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (t *TasksService) checkMember(boardID uint, userID uint) error {
+	ok, err := t.storage.IsBoardMember(boardID, userID)
 	if err != nil {
-		zap.S().Error("Ошибка получения задач для пользователя", zap.String("tgName", tgName), zap.Error(err))
 		return err
 	}
-	err = api.SendDailyReports(message, chatID, 1)
+
+	if !ok {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+// checkAssignee checks assignee of task is a member of its board
+func (t *TasksService) checkAssignee(boardID uint, assignee string) error {
+	assigneeID, err := strconv.ParseUint(assignee, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user_id", models.ErrInvalidInput)
+	}
+
+	ok, err := t.storage.IsBoardMember(boardID, uint(assigneeID))
 	if err != nil {
 		return err
 	}
 
-	message, _, err = t.storage.GetMyTasks(tgName, 2)
+	if !ok {
+		return fmt.Errorf("%w: user %d is not a member of board", models.ErrInvalidInput, assigneeID)
+	}
+
+	return nil
+}
+
+func (t *TasksService) checkStatus(statusID uint) error {
+	statuses, err := t.storage.GetStatuses()
 	if err != nil {
-		zap.S().Error("Ошибка получения выполненных задач для пользователя", zap.String("tgName", tgName), zap.Error(err))
 		return err
 	}
-	err = api.SendDailyReports(message, chatID, 2)
+
+	if !slices.ContainsFunc(statuses, func(status models.Status) bool { return status.ID == statusID }) {
+		return fmt.Errorf("%w: unknown status %d", models.ErrInvalidInput, statusID)
+	}
+
+	return nil
+}
+
+// taskFields validates values of custom fields of task on board, users in values must be members of board.
+// Kept values of fields which board doesn't have, e.g. after move to another board, are dropped
+func (t *TasksService) taskFields(boardID uint, values map[string]any, keep bool) (map[string]any, error) {
+	if len(values) == 0 {
+		return map[string]any{}, nil
+	}
+
+	fields, err := t.storage.GetBoardFields(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if keep {
+		kept := make(map[string]any, len(values))
+		for _, field := range fields {
+			key := strconv.FormatUint(uint64(field.ID), 10)
+			if value, ok := values[key]; ok {
+				kept[key] = value
+			}
+		}
+
+		return fieldValues(fields, kept)
+	}
+
+	res, err := fieldValues(fields, values)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		memberID, ok := res[strconv.FormatUint(uint64(field.ID), 10)].(uint)
+		if !ok || field.Type != models.FieldUser {
+			continue
+		}
+
+		member, err := t.storage.IsBoardMember(boardID, memberID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !member {
+			return nil, fmt.Errorf("%w: user %d of field %q is not a member of board", models.ErrInvalidInput, memberID, field.Name)
+		}
+	}
+
+	return res, nil
+}
+
+// taskLabels checks labels of task belong to its board, kept labels of another board are dropped
+func (t *TasksService) taskLabels(boardID uint, ids []uint, keep bool) ([]uint, error) {
+	if len(ids) == 0 {
+		return []uint{}, nil
+	}
+
+	labels, err := t.storage.GetBoardLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return labelIDs(labels, ids, keep)
+}
+
+func validatePriority(priority int) error {
+	if priority < models.PriorityNone || priority > models.PriorityHigh {
+		return fmt.Errorf("%w: priority must be from %d to %d", models.ErrInvalidInput, models.PriorityNone, models.PriorityHigh)
+	}
+
+	return nil
+}
+
+// estimate is optional, in points or hours of board
+func validateEstimate(estimate *float64) error {
+	if estimate != nil && (*estimate < 0 || *estimate > maxEstimate) {
+		return fmt.Errorf("%w: estimate must be from 0 to %d", models.ErrInvalidInput, maxEstimate)
+	}
+
+	return nil
+}
+
+// tasks of digest: assigned to user, in process or done
+func (t *TasksService) GetMyTasks(userID uint, status int) ([]models.Task, error) {
+	if status != 1 && status != 2 {
+		return nil, fmt.Errorf("%w: status must be 1 or 2", models.ErrInvalidInput)
+	}
+
+	return t.storage.GetMyTasks(userID, status)
+}
+
+func (t *TasksService) SendAllTasks(tgUserID int64, chatID int64) error {
+	user, err := t.storage.GetTgUser(tgUserID)
+	if err != nil {
+		return err
+	}
+
+	message, err := t.storage.GetMyTasks(user.ID, 1)
+	if err != nil {
+		zap.S().Error("Ошибка получения задач для пользователя", zap.Uint("userID", user.ID), zap.Error(err))
+		return err
+	}
+	err = api.SendDailyReports(message, chatID, 1, "")
+	if err != nil {
+		return err
+	}
+
+	message, err = t.storage.GetMyTasks(user.ID, 2)
+	if err != nil {
+		zap.S().Error("Ошибка получения выполненных задач для пользователя", zap.Uint("userID", user.ID), zap.Error(err))
+		return err
+	}
+	err = api.SendDailyReports(message, chatID, 2, "")
 	if err != nil {
 		return err
 	}
@@ -116,6 +474,7 @@ func (t *TasksService) SendAllTasks(tgName string, chatID int64) error {
 	return nil
 }
 
+// queue daily digest for every user, it is delivered by channels chosen by user
 func (t *TasksService) SendDailyReport() {
 	users, err := t.storage.GetAllUsers()
 	if err != nil {
@@ -124,24 +483,94 @@ func (t *TasksService) SendDailyReport() {
 	}
 
 	for _, user := range users {
-		message, _, err := t.storage.GetMyTasks(user.TgName, 1)
+		current, err := t.storage.GetMyTasks(user.ID, 1)
 		if err != nil {
 			zap.S().Error("Ошибка получения задач для пользователя", zap.String("tgName", user.TgName), zap.Error(err))
 			continue
 		}
-		api.SendDailyReports(message, user.ChatID, 1)
 
-		message, _, err = t.storage.GetMyTasks(user.TgName, 2)
+		done, err := t.storage.GetMyTasks(user.ID, 2)
 		if err != nil {
 			zap.S().Error("Ошибка получения выполненных задач для пользователя", zap.String("tgName", user.TgName), zap.Error(err))
 			continue
 		}
-		api.SendDailyReports(message, user.ChatID, 2)
 
-		err = t.storage.ChangeEndedTasksStatus()
+		event := dto.DigestEventDto{
+			UserId:  user.ID,
+			Current: toDigestTasks(current),
+			Done:    toDigestTasks(done),
+		}
+
+		weekly, err := t.weeklyStats(user.ID)
 		if err != nil {
-			zap.L().Error("Ошибка обновления статуса задач", zap.String("tgName", user.TgName), zap.Error(err))
+			zap.L().Error("Ошибка получения статистики досок за неделю", zap.String("tgName", user.TgName), zap.Error(err))
 		}
+		event.Weekly = weekly
+		if err := t.storage.QueueDigest(event); err != nil {
+			zap.L().Error("Ошибка постановки сводки в очередь", zap.String("tgName", user.TgName), zap.Error(err))
+		}
+	}
+
+	err = t.storage.ChangeEndedTasksStatus()
+	if err != nil {
+		zap.L().Error("Ошибка обновления статуса задач", zap.Error(err))
+	}
+}
+
+// numbers of active boards for the last 7 days, nil when user didn't turn them on
+func (t *TasksService) weeklyStats(userID uint) ([]dto.DigestBoardStatsDto, error) {
+	settings, err := t.storage.GetNotificationSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.Digest || !settings.DigestAnalytics {
+		return nil, nil
+	}
+
+	stats, err := t.storage.GetWeeklyStats(userID, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+
+	var res []dto.DigestBoardStatsDto
+	for _, s := range stats {
+		if s.Created == 0 && s.Completed == 0 {
+			continue
+		}
+
+		res = append(res, dto.DigestBoardStatsDto{
+			BoardId:       s.BoardId,
+			BoardName:     s.BoardName,
+			EstimateUnit:  s.EstimateUnit,
+			Created:       s.Created,
+			Completed:     s.Completed,
+			Estimate:      round2(s.Estimate),
+			LeadTimeHours: round2(s.LeadTimeHours),
+		})
+	}
+
+	return res, nil
+}
+
+func toDigestTasks(tasks []models.Task) []dto.DigestTaskDto {
+	res := make([]dto.DigestTaskDto, 0, len(tasks))
+	for _, task := range tasks {
+		res = append(res, dto.DigestTaskDto{
+			TaskId:      task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			StatusId:    task.StatusId,
+		})
+	}
+
+	return res
+}
+
+// queue reminders about tasks which are due soon
+func (t *TasksService) QueueDueReminders() {
+	if err := t.storage.QueueDueReminders(time.Now().Add(dueReminderBefore)); err != nil {
+		zap.L().Error("Ошибка постановки напоминаний о сроках", zap.Error(err))
 	}
 }
 
@@ -150,6 +579,10 @@ func (t *TasksService) StartScheduler() {
 		t.SendDailyReport()
 	})
 
+	gocron.Every(1).Minute().Do(func() {
+		t.QueueDueReminders()
+	})
+
 	go func() {
 		<-gocron.Start()
 	}()
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	templateNameMaxLength = 100
+	templateMaxTasks      = 500
+	templateMaxDueMinutes = 366 * 24 * 60
+)
+
+// TemplatesService saves boards as templates and creates boards and tasks from templates
+type TemplatesService struct {
+	storage TemplatesStorager
+}
+
+type TemplatesStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetBoardTasks(boardID uint) ([]models.Task, error)
+	GetStatuses() ([]models.Status, error)
+	CreateBoardTemplate(tpl models.BoardTemplate) (*models.BoardTemplate, error)
+	GetBoardTemplates(ownerID uint) ([]models.BoardTemplate, error)
+	GetBoardTemplate(id uint) (*models.BoardTemplate, error)
+	DeleteBoardTemplate(id uint) error
+	CreateBoardFromTemplate(tpl *models.BoardTemplate, name string, userID uint) (*models.Board, error)
+	CreateTaskTemplate(tpl models.TaskTemplate) (*models.TaskTemplate, error)
+	GetTaskTemplates(boardID uint) ([]models.TaskTemplate, error)
+	GetTaskTemplate(id uint) (*models.TaskTemplate, error)
+	FindTaskTemplates(userID uint, name string, boardID uint) ([]models.TaskTemplate, error)
+	DeleteTaskTemplate(id uint) error
+}
+
+func NewTemplatesService(stor TemplatesStorager, logger *zap.Logger) *TemplatesService {
+	return &TemplatesService{
+		storage: stor,
+	}
+}
+
+// save statuses, fields, labels and tasks of board as template of user. Due dates of tasks are kept relative
+// to their creation, assignees and values of user fields are not saved
+func (t *TemplatesService) SaveBoardTemplate(body dto.PostBoardTemplateDto, userID uint) (*dto.BoardTemplateDto, error) {
+	name, err := validateTemplateName(body.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := t.getMemberBoard(body.BoardId, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := t.storage.GetBoardTemplates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tpl := range templates {
+		if strings.EqualFold(tpl.Name, name) {
+			return nil, fmt.Errorf("%w: you already have template %q", models.ErrInvalidInput, tpl.Name)
+		}
+	}
+
+	fields, err := t.storage.GetBoardFields(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := t.storage.GetBoardLabels(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := t.storage.GetBoardTasks(board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) > templateMaxTasks {
+		return nil, fmt.Errorf("%w: template can have up to %d tasks", models.ErrInvalidInput, templateMaxTasks)
+	}
+
+	statuses, err := t.storage.GetStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := models.BoardTemplate{
+		OwnerId:      userID,
+		Name:         name,
+		EstimateUnit: board.EstimateUnit,
+		Statuses:     []string{},
+		Fields:       []models.TemplateField{},
+		Labels:       []models.TemplateLabel{},
+		Tasks:        []models.TemplateTask{},
+	}
+
+	fieldNames := make(map[string]*models.BoardField, len(fields))
+	for i, field := range fields {
+		fieldNames[strconv.FormatUint(uint64(field.ID), 10)] = &fields[i]
+		tpl.Fields = append(tpl.Fields, models.TemplateField{
+			Name:     field.Name,
+			Type:     field.Type,
+			Options:  field.Options,
+			Position: field.Position,
+		})
+	}
+
+	labelNames := make(map[uint]string, len(labels))
+	for _, label := range labels {
+		labelNames[label.ID] = label.Name
+		tpl.Labels = append(tpl.Labels, models.TemplateLabel{
+			Name:  label.Name,
+			Color: label.Color,
+		})
+	}
+
+	for _, task := range tasks {
+		item := models.TemplateTask{
+			Title:       task.Title,
+			Description: task.Description,
+			Priority:    task.Priority,
+			Estimate:    task.Estimate,
+			Fields:      map[string]any{},
+			Labels:      []string{},
+		}
+
+		for _, id := range task.Labels {
+			if name, ok := labelNames[id]; ok {
+				item.Labels = append(item.Labels, name)
+			}
+		}
+
+		i := slices.IndexFunc(statuses, func(status models.Status) bool { return status.ID == task.StatusId })
+		if i >= 0 {
+			item.Status = statuses[i].Type
+			if !slices.Contains(tpl.Statuses, item.Status) {
+				tpl.Statuses = append(tpl.Statuses, item.Status)
+			}
+		}
+
+		if task.DueAt != nil {
+			minutes := max(int(task.DueAt.Sub(task.CreatedAt).Minutes()), 0)
+			item.DueInMinutes = &minutes
+		}
+
+		for key, value := range task.CustomFields {
+			field, ok := fieldNames[key]
+			if ok && field.Type != models.FieldUser {
+				item.Fields[field.Name] = value
+			}
+		}
+
+		tpl.Tasks = append(tpl.Tasks, item)
+	}
+
+	created, err := t.storage.CreateBoardTemplate(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return boardTemplateToDto(created), nil
+}
+
+func (t *TemplatesService) GetBoardTemplates(userID uint) ([]dto.BoardTemplateDto, error) {
+	templates, err := t.storage.GetBoardTemplates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.BoardTemplateDto, 0, len(templates))
+	for i := range templates {
+		res = append(res, *boardTemplateToDto(&templates[i]))
+	}
+
+	return res, nil
+}
+
+func (t *TemplatesService) GetBoardTemplate(id uint, userID uint) (*dto.BoardTemplateDto, error) {
+	tpl, err := t.getOwnBoardTemplate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return boardTemplateToDto(tpl), nil
+}
+
+func (t *TemplatesService) DeleteBoardTemplate(id uint, userID uint) error {
+	if _, err := t.getOwnBoardTemplate(id, userID); err != nil {
+		return err
+	}
+
+	return t.storage.DeleteBoardTemplate(id)
+}
+
+// create board with everything from template, by default board is named as template
+func (t *TemplatesService) CreateBoardFromTemplate(id uint, body dto.PostBoardFromTemplateDto, userID uint) (*models.Board, error) {
+	tpl, err := t.getOwnBoardTemplate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		name = tpl.Name
+	}
+
+	if len([]rune(name)) > templateNameMaxLength {
+		return nil, fmt.Errorf("%w: name is longer than %d characters", models.ErrInvalidInput, templateNameMaxLength)
+	}
+
+	return t.storage.CreateBoardFromTemplate(tpl, name, userID)
+}
+
+// add task template to board, values of fields are checked as values of task
+func (t *TemplatesService) CreateTaskTemplate(boardID uint, body dto.PostTaskTemplateDto, userID uint) (*dto.TaskTemplateDto, error) {
+	if _, err := t.getMemberBoard(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	name, err := validateTemplateName(body.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSpace(body.Title)
+	if title == "" || len([]rune(title)) > templateNameMaxLength {
+		return nil, fmt.Errorf("%w: title must be from 1 to %d characters", models.ErrInvalidInput, templateNameMaxLength)
+	}
+
+	if err := validatePriority(body.Priority); err != nil {
+		return nil, err
+	}
+
+	if err := validateEstimate(body.Estimate); err != nil {
+		return nil, err
+	}
+
+	if body.DueInMinutes != nil && (*body.DueInMinutes < 0 || *body.DueInMinutes > templateMaxDueMinutes) {
+		return nil, fmt.Errorf("%w: due_in_minutes must be from 0 to %d", models.ErrInvalidInput, templateMaxDueMinutes)
+	}
+
+	templates, err := t.storage.GetTaskTemplates(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tpl := range templates {
+		if strings.EqualFold(tpl.Name, name) {
+			return nil, fmt.Errorf("%w: board already has template %q", models.ErrInvalidInput, tpl.Name)
+		}
+	}
+
+	fields, err := t.storage.GetBoardFields(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := fieldValues(fields, body.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := t.storage.GetBoardLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskLabels, err := labelIDs(labels, body.Labels, false)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := t.storage.CreateTaskTemplate(models.TaskTemplate{
+		BoardId:      boardID,
+		AuthorId:     userID,
+		Name:         name,
+		Title:        title,
+		Description:  body.Description,
+		Priority:     body.Priority,
+		Estimate:     body.Estimate,
+		DueInMinutes: body.DueInMinutes,
+		CustomFields: values,
+		Labels:       taskLabels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return taskTemplateToDto(created), nil
+}
+
+func (t *TemplatesService) GetTaskTemplates(boardID uint, userID uint) ([]dto.TaskTemplateDto, error) {
+	if _, err := t.getMemberBoard(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	templates, err := t.storage.GetTaskTemplates(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.TaskTemplateDto, 0, len(templates))
+	for i := range templates {
+		res = append(res, *taskTemplateToDto(&templates[i]))
+	}
+
+	return res, nil
+}
+
+// task template can be deleted by its author or by owner of board
+func (t *TemplatesService) DeleteTaskTemplate(boardID uint, id uint, userID uint) error {
+	board, err := t.getMemberBoard(boardID, userID)
+	if err != nil {
+		return err
+	}
+
+	tpl, err := t.storage.GetTaskTemplate(id)
+	if err != nil {
+		return err
+	}
+
+	if tpl == nil || tpl.BoardId != boardID {
+		return models.ErrNotFound
+	}
+
+	if tpl.AuthorId != userID && board.OwnerId != userID {
+		return models.ErrForbidden
+	}
+
+	return t.storage.DeleteTaskTemplate(id)
+}
+
+// TaskFromTemplate returns new task by template, it is created and validated as any other task
+func (t *TemplatesService) TaskFromTemplate(body dto.PostTaskFromTemplateDto, userID uint) (*dto.PostTaskDto, error) {
+	var tpl *models.TaskTemplate
+	if body.TemplateId != 0 {
+		found, err := t.storage.GetTaskTemplate(body.TemplateId)
+		if err != nil {
+			return nil, err
+		}
+
+		if found == nil {
+			return nil, models.ErrNotFound
+		}
+
+		if _, err := t.getMemberBoard(found.BoardId, userID); err != nil {
+			return nil, err
+		}
+		tpl = found
+	} else {
+		name := strings.TrimSpace(body.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: template_id or name is required", models.ErrInvalidInput)
+		}
+
+		found, err := t.storage.FindTaskTemplates(userID, name, body.BoardId)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) == 0 {
+			return nil, fmt.Errorf("%w: template %q", models.ErrNotFound, name)
+		}
+
+		if len(found) > 1 {
+			return nil, fmt.Errorf("%w: several boards have template %q, set board_id", models.ErrInvalidInput, name)
+		}
+		tpl = &found[0]
+	}
+
+	title := tpl.Title
+	if strings.TrimSpace(body.Title) != "" {
+		title = strings.TrimSpace(body.Title)
+	}
+
+	task := &dto.PostTaskDto{
+		Title:       title,
+		Description: tpl.Description,
+		BoardId:     strconv.FormatUint(uint64(tpl.BoardId), 10),
+		Priority:    tpl.Priority,
+		Estimate:    tpl.Estimate,
+		Fields:      tpl.CustomFields,
+		Labels:      tpl.Labels,
+	}
+
+	if tpl.DueInMinutes != nil {
+		due := time.Now().Add(time.Duration(*tpl.DueInMinutes) * time.Minute)
+		task.DueAt = &due
+	}
+
+	return task, nil
+}
+
+// get board where user is added
+func (t *TemplatesService) getMemberBoard(boardID uint, userID uint) (*models.Board, error) {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	return board, nil
+}
+
+// board templates are private to their owners
+func (t *TemplatesService) getOwnBoardTemplate(id uint, userID uint) (*models.BoardTemplate, error) {
+	tpl, err := t.storage.GetBoardTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tpl == nil || tpl.OwnerId != userID {
+		return nil, models.ErrNotFound
+	}
+
+	return tpl, nil
+}
+
+func validateTemplateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > templateNameMaxLength {
+		return "", fmt.Errorf("%w: name must be from 1 to %d characters", models.ErrInvalidInput, templateNameMaxLength)
+	}
+
+	return name, nil
+}
+
+func boardTemplateToDto(tpl *models.BoardTemplate) *dto.BoardTemplateDto {
+	res := &dto.BoardTemplateDto{
+		ID:           tpl.ID,
+		Name:         tpl.Name,
+		EstimateUnit: tpl.EstimateUnit,
+		Statuses:     tpl.Statuses,
+		Fields:       make([]dto.PostBoardFieldDto, 0, len(tpl.Fields)),
+		Labels:       make([]dto.PostBoardLabelDto, 0, len(tpl.Labels)),
+		Tasks:        make([]dto.TemplateTaskDto, 0, len(tpl.Tasks)),
+		CreatedAt:    tpl.CreatedAt,
+	}
+
+	if res.Statuses == nil {
+		res.Statuses = []string{}
+	}
+
+	for _, field := range tpl.Fields {
+		res.Fields = append(res.Fields, dto.PostBoardFieldDto{
+			Name:     field.Name,
+			Type:     field.Type,
+			Options:  field.Options,
+			Position: field.Position,
+		})
+	}
+
+	for _, label := range tpl.Labels {
+		res.Labels = append(res.Labels, dto.PostBoardLabelDto{
+			Name:  label.Name,
+			Color: label.Color,
+		})
+	}
+
+	for _, task := range tpl.Tasks {
+		labels := task.Labels
+		if labels == nil {
+			labels = []string{}
+		}
+
+		res.Tasks = append(res.Tasks, dto.TemplateTaskDto{
+			Title:        task.Title,
+			Description:  task.Description,
+			Status:       task.Status,
+			Priority:     task.Priority,
+			Estimate:     task.Estimate,
+			DueInMinutes: task.DueInMinutes,
+			Fields:       task.Fields,
+			Labels:       labels,
+		})
+	}
+
+	return res
+}
+
+func taskTemplateToDto(tpl *models.TaskTemplate) *dto.TaskTemplateDto {
+	return &dto.TaskTemplateDto{
+		ID:           tpl.ID,
+		BoardId:      tpl.BoardId,
+		AuthorId:     tpl.AuthorId,
+		Name:         tpl.Name,
+		Title:        tpl.Title,
+		Description:  tpl.Description,
+		Priority:     tpl.Priority,
+		Estimate:     tpl.Estimate,
+		DueInMinutes: tpl.DueInMinutes,
+		Fields:       tpl.CustomFields,
+		Labels:       tpl.Labels,
+		CreatedAt:    tpl.CreatedAt,
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	timeReportDefaultDays = 7
+	timeNoteMaxLength     = 500
+
+	reportMaxDays = 366 // limit of time report and analytics range
+)
+
+type TimeService struct {
+	storage TimeStorager
+}
+
+type TimeStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	StartTimer(taskID uint, userID uint, note string) (*models.TimeEntry, *models.TimeEntry, error)
+	StopTimer(userID uint) (*models.TimeEntry, error)
+	GetRunningTimer(userID uint) (*models.TimeEntry, error)
+	AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error)
+	GetTimeEntries(taskID uint) ([]models.TimeEntry, error)
+	GetTimeEntry(id uint) (*models.TimeEntry, error)
+	DeleteTimeEntry(id uint) error
+	GetTimeReport(userID uint, from time.Time, to time.Time, timezone string, groupBy []string, boardID uint) ([]models.TimeReportRow, error)
+}
+
+func NewTimeService(stor TimeStorager, logger *zap.Logger) *TimeService {
+	return &TimeService{
+		storage: stor,
+	}
+}
+
+// start timer on task, running timer of user is stopped
+func (t *TimeService) StartTimer(taskID uint, body dto.PostTimerDto, userID uint) (*dto.TimerStartedDto, error) {
+	if _, err := t.getMemberTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	note, err := validateNote(body.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	started, stopped, err := t.storage.StartTimer(taskID, userID, note)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.TimerStartedDto{Timer: *timeEntryToDto(started)}
+	if stopped != nil {
+		res.Stopped = timeEntryToDto(stopped)
+	}
+
+	return res, nil
+}
+
+func (t *TimeService) StopTimer(userID uint) (*dto.TimeEntryDto, error) {
+	stopped, err := t.storage.StopTimer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stopped == nil {
+		return nil, fmt.Errorf("%w: no running timer", models.ErrNotFound)
+	}
+
+	return timeEntryToDto(stopped), nil
+}
+
+// running timer of user, nil if there is none
+func (t *TimeService) GetTimer(userID uint) (*dto.TimeEntryDto, error) {
+	running, err := t.storage.GetRunningTimer(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if running == nil {
+		return nil, nil
+	}
+
+	return timeEntryToDto(running), nil
+}
+
+// time entries of task by all users
+func (t *TimeService) GetTimeEntries(taskID uint, userID uint) ([]dto.TimeEntryDto, error) {
+	if _, err := t.getMemberTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := t.storage.GetTimeEntries(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.TimeEntryDto, 0, len(entries))
+	for i := range entries {
+		res = append(res, *timeEntryToDto(&entries[i]))
+	}
+
+	return res, nil
+}
+
+// add time spent on task without timer
+func (t *TimeService) AddTimeEntry(taskID uint, body dto.PostTimeEntryDto, userID uint) (*dto.TimeEntryDto, error) {
+	if _, err := t.getMemberTask(taskID, userID); err != nil {
+		return nil, err
+	}
+
+	if body.StartedAt.IsZero() || body.EndedAt.IsZero() {
+		return nil, fmt.Errorf("%w: started_at and ended_at are required", models.ErrInvalidInput)
+	}
+
+	if !body.EndedAt.After(body.StartedAt) {
+		return nil, fmt.Errorf("%w: ended_at must be after started_at", models.ErrInvalidInput)
+	}
+
+	// small clock difference of client is allowed
+	if body.EndedAt.After(time.Now().Add(time.Minute)) {
+		return nil, fmt.Errorf("%w: ended_at is in the future", models.ErrInvalidInput)
+	}
+
+	note, err := validateNote(body.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := t.storage.AddTimeEntry(models.TimeEntry{
+		TaskId:    taskID,
+		UserId:    userID,
+		StartedAt: body.StartedAt,
+		EndedAt:   &body.EndedAt,
+		Note:      note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return timeEntryToDto(entry), nil
+}
+
+// only author can delete time entry
+func (t *TimeService) DeleteTimeEntry(id uint, userID uint) error {
+	entry, err := t.storage.GetTimeEntry(id)
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		return models.ErrNotFound
+	}
+
+	if entry.UserId != userID {
+		return models.ErrForbidden
+	}
+
+	return t.storage.DeleteTimeEntry(id)
+}
+
+// time tracked on boards of user, by default for the last week grouped by user, board and day
+func (t *TimeService) GetTimeReport(filter dto.TimeReportFilterDto, userID uint) (*dto.TimeReportDto, error) {
+	groupBy, err := parseTimeGroups(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, timezone, err := parseDateRange(filter.From, filter.To, filter.Timezone, timeReportDefaultDays)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.BoardId != 0 {
+		ok, err := t.storage.IsBoardMember(filter.BoardId, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, models.ErrForbidden
+		}
+	}
+
+	rows, err := t.storage.GetTimeReport(userID, from, to, timezone, groupBy, filter.BoardId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.TimeReportDto{
+		From:     from,
+		To:       to,
+		Timezone: timezone,
+		GroupBy:  groupBy,
+		Rows:     make([]dto.TimeReportRowDto, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		res.Rows = append(res.Rows, dto.TimeReportRowDto{
+			UserId:    row.UserId,
+			Username:  row.Username,
+			BoardId:   row.BoardId,
+			BoardName: row.BoardName,
+			Day:       row.Day,
+			Seconds:   row.Seconds,
+		})
+		res.TotalSeconds += row.Seconds
+	}
+
+	return res, nil
+}
+
+// get task of board where user is added
+func (t *TimeService) getMemberTask(taskID uint, userID uint) (*models.Task, error) {
+	task, err := t.storage.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == nil {
+		return nil, models.ErrNotFound
+	}
+
+	ok, err := t.storage.IsBoardMember(task.BoardId, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, models.ErrForbidden
+	}
+
+	return task, nil
+}
+
+func validateNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > timeNoteMaxLength {
+		return "", fmt.Errorf("%w: note is longer than %d characters", models.ErrInvalidInput, timeNoteMaxLength)
+	}
+
+	return note, nil
+}
+
+// parseTimeGroups parses comma separated groups, all of them when value is empty
+func parseTimeGroups(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return models.TimeGroups, nil
+	}
+
+	groups := []string{}
+	for _, group := range strings.Split(value, ",") {
+		group = strings.TrimSpace(group)
+		if !slices.Contains(models.TimeGroups, group) {
+			return nil, fmt.Errorf("%w: unknown group_by %q", models.ErrInvalidInput, group)
+		}
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups, nil
+}
+
+// parseDateRange parses range of report in timezone, by default it ends today and lasts given days
+func parseDateRange(fromValue string, toValue string, timezone string, defaultDays int) (time.Time, time.Time, string, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: unknown timezone %q", models.ErrInvalidInput, timezone)
+	}
+
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	if toValue != "" {
+		// date is inclusive, so range ends at the start of the next day
+		if to, err = parseReportTime(toValue, loc, 1); err != nil {
+			return time.Time{}, time.Time{}, "", err
+		}
+	}
+
+	from := to.AddDate(0, 0, -defaultDays)
+	if fromValue != "" {
+		if from, err = parseReportTime(fromValue, loc, 0); err != nil {
+			return time.Time{}, time.Time{}, "", err
+		}
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: to must be after from", models.ErrInvalidInput)
+	}
+
+	if to.Sub(from) > reportMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("%w: range is limited by %d days", models.ErrInvalidInput, reportMaxDays)
+	}
+
+	return from.In(loc), to.In(loc), timezone, nil
+}
+
+// parseReportTime parses RFC 3339 time or date in loc, date is shifted by days
+func parseReportTime(value string, loc *time.Location, days int) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q, expected YYYY-MM-DD", models.ErrInvalidInput, value)
+	}
+
+	return date.AddDate(0, 0, days), nil
+}
+
+func timeEntryToDto(entry *models.TimeEntry) *dto.TimeEntryDto {
+	end := time.Now()
+	if entry.EndedAt != nil {
+		end = *entry.EndedAt
+	}
+
+	seconds := int64(end.Sub(entry.StartedAt).Seconds())
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	return &dto.TimeEntryDto{
+		ID:        entry.ID,
+		TaskId:    entry.TaskId,
+		UserId:    entry.UserId,
+		StartedAt: entry.StartedAt,
+		EndedAt:   entry.EndedAt,
+		Note:      entry.Note,
+		Seconds:   seconds,
+		Running:   entry.EndedAt == nil,
+	}
+}
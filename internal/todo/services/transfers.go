@@ -0,0 +1,341 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+// boards.name is varchar(100)
+const boardNameMaxLength = 100
+
+// TransfersService moves and copies tasks between boards and clones boards
+type TransfersService struct {
+	storage TransfersStorager
+}
+
+type TransfersStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetStatuses() ([]models.Status, error)
+	MoveTask(id uint, transfer models.TaskTransfer, actorID uint) (*models.Task, error)
+	CopyTask(source *models.Task, transfer models.TaskTransfer, actorID uint) (*models.Task, error)
+	CloneBoard(board *models.Board, body dto.PostBoardCloneDto, actorID uint) (*models.Board, error)
+	GetTransfers(taskID uint) ([]models.Transfer, error)
+}
+
+func NewTransfersService(stor TransfersStorager, logger *zap.Logger) *TransfersService {
+	return &TransfersService{
+		storage: stor,
+	}
+}
+
+// move task to another board where user is a member too
+func (t *TransfersService) MoveTask(id uint, body dto.PostTaskTransferDto, userID uint) (*models.Task, error) {
+	task, err := t.getMemberTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.BoardId == task.BoardId {
+		return nil, fmt.Errorf("%w: task is already on board %d", models.ErrInvalidInput, body.BoardId)
+	}
+
+	transfer, err := t.transfer(task, body, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.storage.MoveTask(id, *transfer, userID)
+}
+
+// copy task to board, the same board makes a duplicate
+func (t *TransfersService) CopyTask(id uint, body dto.PostTaskTransferDto, userID uint) (*models.Task, error) {
+	task, err := t.getMemberTask(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := t.transfer(task, body, userID)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Comments = body.Comments
+
+	return t.storage.CopyTask(task, *transfer, userID)
+}
+
+// clone board for any its member, user becomes owner of the clone
+func (t *TransfersService) CloneBoard(id uint, body dto.PostBoardCloneDto, userID uint) (*models.Board, error) {
+	board, err := t.storage.GetBoard(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if board == nil {
+		return nil, models.ErrNotFound
+	}
+
+	if err := t.checkMember(id, userID); err != nil {
+		return nil, err
+	}
+
+	if body.Comments && !body.Tasks {
+		return nil, fmt.Errorf("%w: comments are cloned only with tasks", models.ErrInvalidInput)
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		body.Name = board.Name + " (copy)"
+	}
+
+	if len([]rune(body.Name)) > boardNameMaxLength {
+		return nil, fmt.Errorf("%w: name is longer than %d characters", models.ErrInvalidInput, boardNameMaxLength)
+	}
+
+	return t.storage.CloneBoard(board, body, userID)
+}
+
+// history of moves and copies of task
+func (t *TransfersService) GetTransfers(id uint, userID uint) ([]models.Transfer, error) {
+	if _, err := t.getMemberTask(id, userID); err != nil {
+		return nil, err
+	}
+
+	return t.storage.GetTransfers(id)
+}
+
+// transfer maps status, assignee, custom fields and labels of task to target board
+func (t *TransfersService) transfer(task *models.Task, body dto.PostTaskTransferDto, userID uint) (*models.TaskTransfer, error) {
+	if body.BoardId == 0 {
+		return nil, fmt.Errorf("%w: board_id is required", models.ErrInvalidInput)
+	}
+
+	if err := t.checkMember(body.BoardId, userID); err != nil {
+		return nil, err
+	}
+
+	statusID, err := t.mapStatus(task.StatusId, body.Statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	// assignee who isn't a member of target board is replaced by user
+	assignee := userID
+	if task.UserId != 0 {
+		ok, err := t.storage.IsBoardMember(body.BoardId, task.UserId)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			assignee = task.UserId
+		}
+	}
+
+	values := task.CustomFields
+	labels := task.Labels
+	if body.BoardId != task.BoardId {
+		values, err = t.mapFields(task, body.BoardId, body.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		labels, err = t.mapLabels(task, body.BoardId, body.Labels)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.TaskTransfer{
+		BoardId:      body.BoardId,
+		StatusId:     statusID,
+		UserId:       assignee,
+		CustomFields: values,
+		Labels:       labels,
+	}, nil
+}
+
+// statuses are shared by boards, so without mapping status is kept
+func (t *TransfersService) mapStatus(statusID uint, mapping map[uint]uint) (uint, error) {
+	if len(mapping) == 0 {
+		return statusID, nil
+	}
+
+	statuses, err := t.storage.GetStatuses()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, to := range mapping {
+		if !slices.ContainsFunc(statuses, func(status models.Status) bool { return status.ID == to }) {
+			return 0, fmt.Errorf("%w: unknown status %d", models.ErrInvalidInput, to)
+		}
+	}
+
+	if to, ok := mapping[statusID]; ok {
+		return to, nil
+	}
+
+	return statusID, nil
+}
+
+// mapFields moves values of fields to fields of target board. Mapped values must fit target field,
+// values matched by name and type are dropped if they don't fit
+func (t *TransfersService) mapFields(task *models.Task, boardID uint, mapping map[uint]uint) (map[string]any, error) {
+	source, err := t.storage.GetBoardFields(task.BoardId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := t.storage.GetBoardFields(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	for from, to := range mapping {
+		if !slices.ContainsFunc(source, func(field models.BoardField) bool { return field.ID == from }) {
+			return nil, fmt.Errorf("%w: board %d has no field %d", models.ErrInvalidInput, task.BoardId, from)
+		}
+		if to != 0 && !slices.ContainsFunc(target, func(field models.BoardField) bool { return field.ID == to }) {
+			return nil, fmt.Errorf("%w: board %d has no field %d", models.ErrInvalidInput, boardID, to)
+		}
+	}
+
+	values := make(map[string]any, len(task.CustomFields))
+	for _, field := range source {
+		value, ok := task.CustomFields[strconv.FormatUint(uint64(field.ID), 10)]
+		if !ok {
+			continue
+		}
+
+		to, explicit := mapping[field.ID]
+		var i int
+		if explicit {
+			if to == 0 {
+				continue
+			}
+			i = slices.IndexFunc(target, func(f models.BoardField) bool { return f.ID == to })
+		} else {
+			i = slices.IndexFunc(target, func(f models.BoardField) bool {
+				return strings.EqualFold(f.Name, field.Name) && f.Type == field.Type
+			})
+			if i < 0 {
+				continue
+			}
+		}
+
+		normalized, err := fieldValue(&target[i], value)
+		if err == nil && target[i].Type == models.FieldUser && normalized != nil {
+			err = t.checkFieldUser(boardID, &target[i], normalized.(uint))
+		}
+		if err != nil {
+			if explicit {
+				return nil, err
+			}
+			continue
+		}
+
+		if normalized != nil {
+			values[strconv.FormatUint(uint64(target[i].ID), 10)] = normalized
+		}
+	}
+
+	return values, nil
+}
+
+// mapLabels replaces labels of task with labels of target board, labels without mapping
+// go to the label with the same name or are dropped
+func (t *TransfersService) mapLabels(task *models.Task, boardID uint, mapping map[uint]uint) ([]uint, error) {
+	source, err := t.storage.GetBoardLabels(task.BoardId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := t.storage.GetBoardLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	for from, to := range mapping {
+		if !slices.ContainsFunc(source, func(label models.BoardLabel) bool { return label.ID == from }) {
+			return nil, fmt.Errorf("%w: board %d has no label %d", models.ErrInvalidInput, task.BoardId, from)
+		}
+		if to != 0 && !slices.ContainsFunc(target, func(label models.BoardLabel) bool { return label.ID == to }) {
+			return nil, fmt.Errorf("%w: board %d has no label %d", models.ErrInvalidInput, boardID, to)
+		}
+	}
+
+	labels := make([]uint, 0, len(task.Labels))
+	for _, label := range source {
+		if !slices.Contains(task.Labels, label.ID) {
+			continue
+		}
+
+		to, explicit := mapping[label.ID]
+		if !explicit {
+			i := slices.IndexFunc(target, func(l models.BoardLabel) bool { return strings.EqualFold(l.Name, label.Name) })
+			if i < 0 {
+				continue
+			}
+			to = target[i].ID
+		}
+
+		if to != 0 && !slices.Contains(labels, to) {
+			labels = append(labels, to)
+		}
+	}
+
+	return labels, nil
+}
+
+func (t *TransfersService) checkFieldUser(boardID uint, field *models.BoardField, userID uint) error {
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: user %d of field %q is not a member of board", models.ErrInvalidInput, userID, field.Name)
+	}
+
+	return nil
+}
+
+// get task and check user is a member of its board
+func (t *TransfersService) getMemberTask(id uint, userID uint) (*models.Task, error) {
+	task, err := t.storage.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task == nil {
+		return nil, models.ErrNotFound
+	}
+
+	if err := t.checkMember(task.BoardId, userID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (t *TransfersService) checkMember(boardID uint, userID uint) error {
+	ok, err := t.storage.IsBoardMember(boardID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
@@ -1,6 +1,11 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"todo/internal/todo/config"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
 	"todo/internal/todo/utils/hash"
@@ -18,9 +23,17 @@ type UserStorager interface {
 	WriteRefreshToken(userId uint, refreshTokenValue string) error
 	GetAuthUser(id uint) (*models.UserToken, error)
 	UserLogout(id uint) error
-	AddChatID(tgName string, chatID int64) error
+	CreateLinkCode(userID uint, code string, expiresAt time.Time) error
+	LinkTelegram(code string, tgUserID int64, chatID int64, tgName string) (*uint, error)
+	UnlinkTelegram(userID uint) error
+	GetUserIDByTg(tgUserID int64) (*uint, error)
+	GetUser(id uint) (*models.User, error)
+	ActivateTelegram(userID uint, chatID int64) (bool, error)
 }
 
+// how long telegram link code stays valid
+const linkCodeTTL = 10 * time.Minute
+
 func NewUserService(stor UserStorager, logger *zap.Logger) *UserService {
 	return &UserService{
 		storage: stor,
@@ -83,11 +96,102 @@ func (t *UserService) WriteRefreshToken(userId uint, refreshTokenValue string) e
 	return nil
 }
 
-func (t *UserService) AddChatID(tgName string, chatID int64) error {
-	err := t.storage.AddChatID(tgName, chatID)
+// generate one-time code for /start command of the bot
+func (t *UserService) CreateLinkCode(userID uint) (*dto.TgLinkCodeDto, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	code := hex.EncodeToString(buf)
+	expiresAt := time.Now().Add(linkCodeTTL)
+
+	err := t.storage.CreateLinkCode(userID, code, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	link := dto.TgLinkCodeDto{
+		Code:      code,
+		ExpiresAt: expiresAt,
+	}
+
+	// deep link opens the bot with /start <code>
+	if config.AppConfig.TelegramBotName != "" {
+		link.URL = fmt.Sprintf("https://t.me/%s?start=%s", config.AppConfig.TelegramBotName, code)
+	}
+
+	return &link, nil
+}
+
+func (t *UserService) LinkTelegram(body dto.PostTgLinkDto) error {
+	if body.Code == "" || body.TgUserID == 0 {
+		return fmt.Errorf("code and telegram user id are required")
+	}
+
+	userID, err := t.storage.LinkTelegram(body.Code, body.TgUserID, body.ChatID, body.TgName)
 	if err != nil {
 		return err
 	}
 
+	if userID == nil {
+		return models.ErrLinkCodeInvalid
+	}
+
 	return nil
 }
+
+func (t *UserService) UnlinkTelegram(userID uint) error {
+	err := t.storage.UnlinkTelegram(userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// get user linked to telegram account, used for requests made by the bot
+func (t *UserService) GetUserIDByTg(tgUserID int64) (uint, error) {
+	id, err := t.storage.GetUserIDByTg(tgUserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if id == nil {
+		return 0, models.ErrTgNotLinked
+	}
+
+	return *id, nil
+}
+
+// get user with state of telegram link
+func (t *UserService) GetUser(id uint) (*dto.UserDto, error) {
+	user, err := t.storage.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, models.ErrNotFound
+	}
+
+	res := dto.UserDto{ID: user.ID, Username: user.Username}
+	if user.TgUserID != nil {
+		res.Telegram = &dto.TelegramDto{
+			TgName:     user.TgName,
+			Active:     user.TgActive,
+			InactiveAt: user.TgInactiveAt,
+		}
+	}
+
+	return &res, nil
+}
+
+// activate telegram link of user who sent /start after blocking bot
+func (t *UserService) ActivateTelegram(userID uint, chatID int64) (bool, error) {
+	if chatID == 0 {
+		return false, fmt.Errorf("%w: chat_id is required", models.ErrInvalidInput)
+	}
+
+	return t.storage.ActivateTelegram(userID, chatID)
+}
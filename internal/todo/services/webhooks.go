@@ -0,0 +1,398 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+	"todo/pkg/safehttp"
+
+	"go.uber.org/zap"
+)
+
+const (
+	webhookBatchSize    = 20
+	webhookPollPeriod   = 2 * time.Second
+	webhookLease        = time.Minute
+	webhookTimeout      = 10 * time.Second
+	webhookMaxAttempts  = 8
+	webhookResponseSize = 4096 // part of response body kept in delivery log
+	webhookLogSize      = 100
+
+	// headers of webhook request
+	HeaderWebhookEvent     = "X-Todo-Event"
+	HeaderWebhookDelivery  = "X-Todo-Delivery"
+	HeaderWebhookTimestamp = "X-Todo-Timestamp"
+	HeaderWebhookSignature = "X-Todo-Signature"
+)
+
+type WebhooksService struct {
+	storage WebhooksStorager
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+type WebhooksStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	CreateWebhook(hook models.Webhook) (*models.Webhook, error)
+	GetWebhooks(boardID uint) ([]models.Webhook, error)
+	GetWebhook(id uint) (*models.Webhook, error)
+	UpdateWebhook(hook models.Webhook) (*models.Webhook, error)
+	DeleteWebhook(id uint) error
+	GetDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error)
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	Redeliver(id uint) (*models.WebhookDelivery, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	FinishDelivery(delivery models.WebhookDelivery) error
+}
+
+func NewWebhooksService(stor WebhooksStorager, logger *zap.Logger) *WebhooksService {
+	return &WebhooksService{
+		storage: stor,
+		client:  safehttp.NewClient(webhookTimeout), // address is checked again when connecting
+		logger:  logger,
+	}
+}
+
+func (t *WebhooksService) CreateWebhook(boardID uint, body dto.PostWebhookDto, userID uint) (*dto.WebhookDto, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	hook, err := webhookFromDto(body)
+	if err != nil {
+		return nil, err
+	}
+	hook.BoardId = boardID
+
+	// secret is generated when it isn't given
+	if hook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		hook.Secret = hex.EncodeToString(secret)
+	}
+
+	created, err := t.storage.CreateWebhook(*hook)
+	if err != nil {
+		return nil, err
+	}
+
+	res := webhookToDto(created)
+	res.Secret = created.Secret
+	return res, nil
+}
+
+func (t *WebhooksService) GetWebhooks(boardID uint, userID uint) ([]dto.WebhookDto, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	hooks, err := t.storage.GetWebhooks(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.WebhookDto, 0, len(hooks))
+	for i := range hooks {
+		res = append(res, *webhookToDto(&hooks[i]))
+	}
+
+	return res, nil
+}
+
+func (t *WebhooksService) UpdateWebhook(boardID uint, id uint, body dto.PostWebhookDto, userID uint) (*dto.WebhookDto, error) {
+	if _, err := t.getOwnWebhook(boardID, id, userID); err != nil {
+		return nil, err
+	}
+
+	hook, err := webhookFromDto(body)
+	if err != nil {
+		return nil, err
+	}
+	hook.ID = id
+
+	updated, err := t.storage.UpdateWebhook(*hook)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, models.ErrNotFound
+	}
+
+	return webhookToDto(updated), nil
+}
+
+func (t *WebhooksService) DeleteWebhook(boardID uint, id uint, userID uint) error {
+	if _, err := t.getOwnWebhook(boardID, id, userID); err != nil {
+		return err
+	}
+
+	return t.storage.DeleteWebhook(id)
+}
+
+// latest deliveries of webhook, newest first
+func (t *WebhooksService) GetDeliveries(boardID uint, id uint, userID uint) ([]dto.WebhookDeliveryDto, error) {
+	if _, err := t.getOwnWebhook(boardID, id, userID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := t.storage.GetDeliveries(id, webhookLogSize)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.WebhookDeliveryDto, 0, len(deliveries))
+	for i := range deliveries {
+		res = append(res, *deliveryToDto(&deliveries[i]))
+	}
+
+	return res, nil
+}
+
+// send payload of delivery again, receiver sees the same delivery guid
+func (t *WebhooksService) Redeliver(boardID uint, id uint, deliveryID uint, userID uint) (*dto.WebhookDeliveryDto, error) {
+	if _, err := t.getOwnWebhook(boardID, id, userID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := t.storage.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil || delivery.WebhookId != id {
+		return nil, models.ErrNotFound
+	}
+
+	redelivery, err := t.storage.Redeliver(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if redelivery == nil {
+		return nil, models.ErrNotFound
+	}
+
+	return deliveryToDto(redelivery), nil
+}
+
+// run delivery loop in background
+func (t *WebhooksService) StartDispatcher() {
+	go func() {
+		for range time.Tick(webhookPollPeriod) {
+			t.Dispatch()
+		}
+	}()
+}
+
+// send all due deliveries once
+func (t *WebhooksService) Dispatch() {
+	deliveries, err := t.storage.ClaimDueDeliveries(webhookBatchSize, webhookLease)
+	if err != nil {
+		t.logger.Error("claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	for _, delivery := range deliveries {
+		hook, err := t.storage.GetWebhook(delivery.WebhookId)
+		if err != nil {
+			t.logger.Error("get webhook", zap.Uint("id", delivery.WebhookId), zap.Error(err))
+			continue
+		}
+
+		delivery.Attempts++
+		if hook == nil || !hook.Active {
+			delivery.Status = models.OutboxSkipped
+		} else {
+			t.send(hook, &delivery)
+		}
+
+		if err := t.storage.FinishDelivery(delivery); err != nil {
+			t.logger.Error("update webhook delivery", zap.Uint("id", delivery.ID), zap.Error(err))
+		}
+	}
+}
+
+// send makes one attempt and writes its result to delivery
+func (t *WebhooksService) send(hook *models.Webhook, delivery *models.WebhookDelivery) {
+	delivery.ResponseStatus, delivery.ResponseBody, delivery.DurationMs = nil, "", nil
+
+	start := time.Now()
+	status, body, err := t.post(hook, delivery)
+	duration := int(time.Since(start).Milliseconds())
+	delivery.DurationMs = &duration
+
+	if status != 0 {
+		delivery.ResponseStatus = &status
+		delivery.ResponseBody = body
+	}
+
+	if err == nil && (status < 200 || status > 299) {
+		err = fmt.Errorf("unexpected status %d", status)
+	}
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.OutboxSent
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.OutboxDead
+		t.logger.Warn("webhook delivery failed", zap.Uint("id", delivery.ID), zap.Uint("webhook_id", hook.ID), zap.Error(err))
+		return
+	}
+
+	delivery.Status = models.OutboxPending
+	delivery.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts))
+}
+
+func (t *WebhooksService) post(hook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-webhooks")
+	req.Header.Set(HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(HeaderWebhookDelivery, delivery.Guid)
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, "sha256="+signWebhook(hook.Secret, timestamp, delivery.Payload))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseSize))
+	return resp.StatusCode, strings.ToValidUTF8(string(body), ""), nil
+}
+
+// signWebhook returns hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// get webhook of board managed by user
+func (t *WebhooksService) getOwnWebhook(boardID uint, id uint, userID uint) (*models.Webhook, error) {
+	if err := t.checkOwner(boardID, userID); err != nil {
+		return nil, err
+	}
+
+	hook, err := t.storage.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hook == nil || hook.BoardId != boardID {
+		return nil, models.ErrNotFound
+	}
+
+	return hook, nil
+}
+
+// only owner can manage webhooks of board, they contain secrets
+func (t *WebhooksService) checkOwner(boardID uint, userID uint) error {
+	board, err := t.storage.GetBoard(boardID)
+	if err != nil {
+		return err
+	}
+
+	if board == nil {
+		return models.ErrNotFound
+	}
+
+	if board.OwnerId != userID {
+		return models.ErrForbidden
+	}
+
+	return nil
+}
+
+func webhookFromDto(body dto.PostWebhookDto) (*models.Webhook, error) {
+	// response is kept in delivery log, so webhook must not reach internal network
+	u, err := safehttp.CheckURL(context.Background(), strings.TrimSpace(body.URL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: url %v", models.ErrInvalidInput, err)
+	}
+
+	events := []string{}
+	for _, event := range body.Events {
+		if !slices.Contains(models.BoardEvents, event) {
+			return nil, fmt.Errorf("%w: unknown event %q", models.ErrInvalidInput, event)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	active := true
+	if body.Active != nil {
+		active = *body.Active
+	}
+
+	return &models.Webhook{
+		URL:    u.String(),
+		Secret: body.Secret,
+		Events: events,
+		Active: active,
+	}, nil
+}
+
+func webhookToDto(hook *models.Webhook) *dto.WebhookDto {
+	events := hook.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	return &dto.WebhookDto{
+		ID:        hook.ID,
+		BoardId:   hook.BoardId,
+		URL:       hook.URL,
+		Events:    events,
+		Active:    hook.Active,
+		CreatedAt: hook.CreatedAt,
+		UpdatedAt: hook.UpdatedAt,
+	}
+}
+
+func deliveryToDto(delivery *models.WebhookDelivery) *dto.WebhookDeliveryDto {
+	return &dto.WebhookDeliveryDto{
+		ID:             delivery.ID,
+		WebhookId:      delivery.WebhookId,
+		Guid:           delivery.Guid,
+		RedeliveryOf:   delivery.RedeliveryOf,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		DurationMs:     delivery.DurationMs,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type AnalyticsStorage struct {
+	db *pgxpool.Pool
+}
+
+type AnalyticsStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetStatuses() ([]models.Status, error)
+	GetTaskFlows(boardID uint) ([]models.TaskFlow, error)
+}
+
+func NewAnalyticsStore(Conn *pgxpool.Pool, log *zap.Logger) *AnalyticsStorage {
+	return &AnalyticsStorage{db: Conn}
+}
+
+// board for its estimate unit
+func (d *AnalyticsStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *AnalyticsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// get all statuses for names in cumulative flow
+func (d *AnalyticsStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+// get tasks which are on board now with their status history and start of tracked time
+func (d *AnalyticsStorage) GetTaskFlows(boardID uint) ([]models.TaskFlow, error) {
+	ctx := context.Background()
+
+	query := `SELECT t.id, COALESCE(t.status_id, 0), t.estimate::float8, COALESCE(t.created_at, NOW()),
+			(SELECT MIN(e.started_at) FROM time_entries e WHERE e.task_id = t.id)
+		FROM tasks t WHERE t.board_id = $1 ORDER BY t.id`
+	rows, err := d.db.Query(ctx, query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskFlow
+	index := make(map[uint]int)
+	for rows.Next() {
+		var task models.TaskFlow
+		if err := rows.Scan(&task.ID, &task.StatusId, &task.Estimate, &task.CreatedAt, &task.WorkStartedAt); err != nil {
+			return nil, err
+		}
+		index[task.ID] = len(tasks)
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT h.task_id, h.from_status_id, COALESCE(h.to_status_id, 0), h.changed_at
+		FROM task_status_history h JOIN tasks t ON t.id = h.task_id
+		WHERE t.board_id = $1 ORDER BY h.task_id, h.changed_at, h.id`
+	historyRows, err := d.db.Query(ctx, query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer historyRows.Close()
+
+	for historyRows.Next() {
+		var change models.StatusChange
+		if err := historyRows.Scan(&change.TaskId, &change.FromStatusId, &change.ToStatusId, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+
+		// task could be added to board after the first query
+		if i, ok := index[change.TaskId]; ok {
+			tasks[i].History = append(tasks[i].History, change)
+		}
+	}
+
+	if err := historyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
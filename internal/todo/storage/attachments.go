@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type AttachmentsStorage struct {
+	db *pgxpool.Pool
+}
+
+type AttachmentsStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	CountAttachments(taskID uint) (int, error)
+	AddAttachment(attachment models.Attachment) (*models.Attachment, error)
+	GetAttachments(taskID uint) ([]models.Attachment, error)
+	GetAttachment(id uint) (*models.Attachment, error)
+	DeleteAttachment(id uint) error
+	GetDeletedBlobs(limit int) ([]string, error)
+	RemoveDeletedBlobs(keys []string) error
+}
+
+const attachmentColumns = `id, task_id, COALESCE(user_id, 0), filename, content_type, size, sha256, storage_key, created_at`
+
+func NewAttachmentsStore(Conn *pgxpool.Pool, log *zap.Logger) *AttachmentsStorage {
+	return &AttachmentsStorage{db: Conn}
+}
+
+func (d *AttachmentsStorage) GetTask(id uint) (*models.Task, error) {
+	return getTask(d.db, id)
+}
+
+func (d *AttachmentsStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *AttachmentsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func (d *AttachmentsStorage) CountAttachments(taskID uint) (int, error) {
+	query := `SELECT COUNT(*) FROM task_attachments WHERE task_id = $1`
+
+	var count int
+	err := d.db.QueryRow(context.Background(), query, taskID).Scan(&count)
+
+	return count, err
+}
+
+// add attachment to task, nil when task is already deleted
+func (d *AttachmentsStorage) AddAttachment(attachment models.Attachment) (*models.Attachment, error) {
+	query := `INSERT INTO task_attachments (task_id, user_id, filename, content_type, size, sha256, storage_key)
+		SELECT id, $2, $3, $4, $5, $6, $7 FROM tasks WHERE id = $1
+		RETURNING ` + attachmentColumns
+
+	row := d.db.QueryRow(context.Background(), query, attachment.TaskId, attachment.UserId, attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.Sha256, attachment.StorageKey)
+
+	res, err := scanAttachment(row)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// get attachments of task, oldest first
+func (d *AttachmentsStorage) GetAttachments(taskID uint) ([]models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM task_attachments WHERE task_id = $1 ORDER BY id`
+	rows, err := d.db.Query(context.Background(), query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (d *AttachmentsStorage) GetAttachment(id uint) (*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM task_attachments WHERE id = $1`
+
+	attachment, err := scanAttachment(d.db.QueryRow(context.Background(), query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// delete attachment, trigger queues its file to deleted_blobs
+func (d *AttachmentsStorage) DeleteAttachment(id uint) error {
+	query := `DELETE FROM task_attachments WHERE id = $1`
+	_, err := d.db.Exec(context.Background(), query, id)
+
+	return err
+}
+
+// get keys of files left by deleted attachments, tasks and boards
+func (d *AttachmentsStorage) GetDeletedBlobs(limit int) ([]string, error) {
+	query := `SELECT storage_key FROM deleted_blobs ORDER BY created_at LIMIT $1`
+	rows, err := d.db.Query(context.Background(), query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// forget keys of files removed from blob store
+func (d *AttachmentsStorage) RemoveDeletedBlobs(keys []string) error {
+	query := `DELETE FROM deleted_blobs WHERE storage_key = ANY($1)`
+	_, err := d.db.Exec(context.Background(), query, keys)
+
+	return err
+}
+
+func scanAttachment(row pgx.Row) (*models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.TaskId, &a.UserId, &a.Filename, &a.ContentType, &a.Size, &a.Sha256, &a.StorageKey, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
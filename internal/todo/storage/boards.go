@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"strconv"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
 
@@ -15,27 +16,56 @@ type BoardsStorage struct {
 }
 
 type BoardsStorager interface {
-	SetBoard(body dto.PostBoardDto) (*models.Board, error)
-	GetAllBoards() ([]models.Board, error)
+	SetBoard(body dto.PostBoardDto, userID uint) (*models.Board, error)
+	GetAllBoards(userID uint) ([]models.Board, error)
 	GetBoard(id uint) (*models.Board, error)
-	UpdateBoard(body dto.PostBoardDto) (*models.Board, error)
+	UpdateBoard(body dto.PostBoardDto, id uint, actorID uint) (*models.Board, error)
 	DeleteBoard(id uint) error
+	User2Board(body dto.PostUser2BoardDto, actorID uint) error
+	RemoveUser(boardID uint, userID uint, actorID uint) error
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	SetBoardChat(boardID uint, chatID *int64) error
+}
+
+// columns for scanBoard
+const boardColumns = `b.id, b.name, COALESCE(b.owner_id, 0), b.chat_id, b.estimate_unit, b.created_at, b.updated_at`
+
+func scanBoard(row pgx.Row, board *models.Board) error {
+	return row.Scan(&board.ID, &board.Name, &board.OwnerId, &board.ChatId, &board.EstimateUnit, &board.CreatedAt, &board.UpdatedAt)
 }
 
 func NewBoardsStore(Conn *pgxpool.Pool, log *zap.Logger) *BoardsStorage {
 	return &BoardsStorage{db: Conn}
 }
 
-// add board
-func (d *BoardsStorage) SetBoard(body dto.PostBoardDto) (*models.Board, error) {
-	query := `INSERT INTO boards (name) VALUES ($1) RETURNING id`
+// add board, creator becomes its owner and member
+func (d *BoardsStorage) SetBoard(body dto.PostBoardDto, userID uint) (*models.Board, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO boards (name, owner_id, estimate_unit) VALUES ($1, $2, COALESCE(NULLIF($3, ''), 'points')) RETURNING id`
 
 	var id uint
-	err := d.db.QueryRow(context.Background(), query, body.Name).Scan(&id)
+	err = tx.QueryRow(ctx, query, body.Name, userID, body.EstimateUnit).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `INSERT INTO boards_users (user_id, board_id) VALUES ($1, $2)`
+	_, err = tx.Exec(ctx, query, userID, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	boardRet, err := d.GetBoard(uint(id))
 	if err != nil {
 		return nil, err
@@ -44,18 +74,21 @@ func (d *BoardsStorage) SetBoard(body dto.PostBoardDto) (*models.Board, error) {
 	return boardRet, nil
 }
 
-// get all boards
-func (d *BoardsStorage) GetAllBoards() ([]models.Board, error) {
-	query := `SELECT id, name, created_at, updated_at FROM boards ORDER BY created_at`
-	rows, err := d.db.Query(context.Background(), query)
+// get all boards of user
+func (d *BoardsStorage) GetAllBoards(userID uint) ([]models.Board, error) {
+	query := `SELECT ` + boardColumns + ` FROM boards b
+		JOIN boards_users bu ON bu.board_id = b.id
+		WHERE bu.user_id = $1 ORDER BY b.created_at`
+	rows, err := d.db.Query(context.Background(), query, userID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	var boards []models.Board
 	for rows.Next() {
 		var board models.Board
-		err := rows.Scan(&board.ID, &board.Name, &board.CreatedAt, &board.UpdatedAt)
+		err := scanBoard(rows, &board)
 		if err != nil {
 			return nil, err
 		}
@@ -67,11 +100,15 @@ func (d *BoardsStorage) GetAllBoards() ([]models.Board, error) {
 
 // get board
 func (d *BoardsStorage) GetBoard(id uint) (*models.Board, error) {
-	query := `SELECT * FROM boards WHERE id = $1`
-	row := d.db.QueryRow(context.Background(), query, id)
+	return getBoard(d.db, id)
+}
+
+func getBoard(db *pgxpool.Pool, id uint) (*models.Board, error) {
+	query := `SELECT ` + boardColumns + ` FROM boards b WHERE b.id = $1`
+	row := db.QueryRow(context.Background(), query, id)
 
 	var board models.Board
-	err := row.Scan(&board.ID, &board.Name, &board.CreatedAt, &board.UpdatedAt)
+	err := scanBoard(row, &board)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -83,12 +120,29 @@ func (d *BoardsStorage) GetBoard(id uint) (*models.Board, error) {
 }
 
 // update board
-func (d *BoardsStorage) UpdateBoard(body dto.PostBoardDto, id uint) (*models.Board, error) {
-	query := `UPDATE boards SET name=$1, updated_at=NOW() WHERE id=$2`
-	_, err := d.db.Exec(context.Background(), query, body.Name, id)
+func (d *BoardsStorage) UpdateBoard(body dto.PostBoardDto, id uint, actorID uint) (*models.Board, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
+
+	// empty estimate unit keeps the current one
+	query := `UPDATE boards SET name=$1, estimate_unit=COALESCE(NULLIF($2, ''), estimate_unit), updated_at=NOW() WHERE id=$3`
+	_, err = tx.Exec(ctx, query, body.Name, body.EstimateUnit, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := queueBoardEvent(ctx, tx, id, actorID, models.BoardEventBoardUpdated, body); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 
 	boardRet, err := d.GetBoard(id)
 	if err != nil {
@@ -110,13 +164,105 @@ func (d *BoardsStorage) DeleteBoard(id uint) error {
 }
 
 // add user to board
-func (d *BoardsStorage) User2Board(body dto.PostUser2BoardDto) error {
-	query := `INSERT INTO boards_users (user_id, board_id) VALUES ($1, $2)`
-	_, err := d.db.Exec(context.Background(), query, body.UserId, body.BoardId)
+func (d *BoardsStorage) User2Board(body dto.PostUser2BoardDto, actorID uint) error {
+	userID, err := strconv.ParseUint(body.UserId, 10, 32)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	boardID, err := strconv.ParseUint(body.BoardId, 10, 32)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO boards_users (user_id, board_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	tag, err := tx.Exec(ctx, query, userID, boardID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() > 0 {
+		data := dto.MemberEventDataDto{UserId: uint(userID)}
+		if err := queueBoardEvent(ctx, tx, uint(boardID), actorID, models.BoardEventMemberAdded, data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// remove user from board
+func (d *BoardsStorage) RemoveUser(boardID uint, userID uint, actorID uint) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `DELETE FROM boards_users WHERE board_id = $1 AND user_id = $2`
+	tag, err := tx.Exec(ctx, query, boardID, userID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() > 0 {
+		data := dto.MemberEventDataDto{UserId: userID}
+		if err := queueBoardEvent(ctx, tx, boardID, actorID, models.BoardEventMemberRemoved, data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// link telegram chat to board, nil unlinks it.
+// Chat can be linked to one board only, so it is taken from the previous one
+func (d *BoardsStorage) SetBoardChat(boardID uint, chatID *int64) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if chatID != nil {
+		query := `UPDATE boards SET chat_id = NULL WHERE chat_id = $1 AND id <> $2`
+		if _, err := tx.Exec(ctx, query, *chatID, boardID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE boards SET chat_id = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.Exec(ctx, query, chatID, boardID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// check user is added to board
+func (d *BoardsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func isBoardMember(db *pgxpool.Pool, boardID uint, userID uint) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM boards_users WHERE board_id = $1 AND user_id = $2)`
+	err := db.QueryRow(context.Background(), query, boardID, userID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
 
+	return exists, nil
 }
@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type BulkStorage struct {
+	db *pgxpool.Pool
+}
+
+type BulkStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetStatuses() ([]models.Status, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	ApplyBulk(ops []models.BulkOperation, atomic bool, actorID uint) ([]error, error)
+}
+
+func NewBulkStore(Conn *pgxpool.Pool, log *zap.Logger) *BulkStorage {
+	return &BulkStorage{db: Conn}
+}
+
+func (d *BulkStorage) GetTask(id uint) (*models.Task, error) {
+	return getTask(d.db, id)
+}
+
+// check user is added to board
+func (d *BulkStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func (d *BulkStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+func (d *BulkStorage) GetBoardLabels(boardID uint) ([]models.BoardLabel, error) {
+	return getBoardLabels(d.db, boardID)
+}
+
+// ApplyBulk applies operations in one transaction and returns error of every operation.
+// Atomic request is rolled back on the first error, otherwise every operation has its own savepoint.
+// Notifications are coalesced into one message per assignee and per board chat
+func (d *BulkStorage) ApplyBulk(ops []models.BulkOperation, atomic bool, actorID uint) ([]error, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockBulkBoards(ctx, tx, ops); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(ops))
+	var changes []dto.BulkChangeDto
+	for i, op := range ops {
+		if atomic {
+			opChanges, err := applyBulkOp(ctx, tx, op, actorID)
+			if err != nil {
+				errs[i] = bulkError(err)
+				return errs, nil
+			}
+			changes = append(changes, opChanges...)
+			continue
+		}
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		opChanges, err := applyBulkOp(ctx, savepoint, op, actorID)
+		if err != nil {
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, err
+			}
+			errs[i] = bulkError(err)
+			continue
+		}
+
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, err
+		}
+		changes = append(changes, opChanges...)
+	}
+
+	if err := queueBulkNotifications(ctx, tx, changes, actorID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// lockBulkBoards takes event locks of all boards of request before its first event, as queueTaskMoved does
+// for one move, so requests touching the same boards in different order don't deadlock
+func lockBulkBoards(ctx context.Context, tx pgx.Tx, ops []models.BulkOperation) error {
+	taskIDs := make([]uint, 0, len(ops))
+	var boardIDs []uint
+	for _, op := range ops {
+		taskIDs = append(taskIDs, op.TaskId)
+		if op.Transfer != nil {
+			boardIDs = append(boardIDs, op.Transfer.BoardId)
+		}
+	}
+
+	rows, err := tx.Query(ctx, `SELECT DISTINCT board_id FROM tasks WHERE id = ANY($1) AND board_id IS NOT NULL`, taskIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var boardID uint
+		if err := rows.Scan(&boardID); err != nil {
+			return err
+		}
+		boardIDs = append(boardIDs, boardID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return lockBoardEvents(ctx, tx, boardIDs...)
+}
+
+// task can be deleted by previous operation of the same request
+func bulkError(err error) error {
+	if err == pgx.ErrNoRows {
+		return models.ErrNotFound
+	}
+
+	return err
+}
+
+// applyBulkOp applies one operation and returns changes to notify about
+func applyBulkOp(ctx context.Context, tx pgx.Tx, op models.BulkOperation, actorID uint) ([]dto.BulkChangeDto, error) {
+	switch op.Op {
+	case models.BulkStatus:
+		old, err := lockTask(ctx, tx, op.TaskId)
+		if err != nil {
+			return nil, err
+		}
+
+		if old.StatusId == op.StatusId {
+			return nil, nil
+		}
+
+		query := `UPDATE tasks SET status_id = $1, updated_at = NOW() WHERE id = $2`
+		if _, err := tx.Exec(ctx, query, op.StatusId, op.TaskId); err != nil {
+			return nil, err
+		}
+
+		if err := recordStatusChange(ctx, tx, op.TaskId, old.StatusId, actorID); err != nil {
+			return nil, err
+		}
+
+		from := dto.TaskPlaceDto{BoardId: old.BoardId, StatusId: old.StatusId}
+		if err := queueTaskMoved(ctx, tx, op.TaskId, actorID, from); err != nil {
+			return nil, err
+		}
+
+		return bulkChanges(ctx, tx, op.TaskId, models.NotifyStatusChanged)
+	case models.BulkAssign:
+		old, err := lockTask(ctx, tx, op.TaskId)
+		if err != nil {
+			return nil, err
+		}
+
+		if old.UserId == op.UserId {
+			return nil, nil
+		}
+
+		query := `UPDATE tasks SET user_id = $1, updated_at = NOW() WHERE id = $2`
+		if _, err := tx.Exec(ctx, query, op.UserId, op.TaskId); err != nil {
+			return nil, err
+		}
+
+		if err := queueTaskEvent(ctx, tx, op.TaskId, actorID, models.BoardEventTaskUpdated); err != nil {
+			return nil, err
+		}
+
+		return bulkChanges(ctx, tx, op.TaskId, models.NotifyAssigned)
+	case models.BulkMove:
+		old, err := moveTask(ctx, tx, op.TaskId, *op.Transfer, actorID)
+		if err != nil {
+			return nil, err
+		}
+
+		var kinds []string
+		if old.StatusId != op.Transfer.StatusId {
+			kinds = append(kinds, models.NotifyStatusChanged)
+		}
+		if old.UserId != op.Transfer.UserId {
+			kinds = append(kinds, models.NotifyAssigned)
+		}
+
+		return bulkChanges(ctx, tx, op.TaskId, kinds...)
+	case models.BulkDelete:
+		return nil, deleteTask(ctx, tx, op.TaskId, actorID)
+	case models.BulkAddLabel, models.BulkRemoveLabel:
+		old, err := lockTask(ctx, tx, op.TaskId)
+		if err != nil {
+			return nil, err
+		}
+
+		// label is already added or removed
+		if slices.Contains(old.Labels, op.LabelId) == (op.Op == models.BulkAddLabel) {
+			return nil, nil
+		}
+
+		if op.Op == models.BulkAddLabel {
+			// task can be moved to another board by previous operation of the same request
+			query := `INSERT INTO task_labels (task_id, label_id) SELECT $1, id FROM board_labels WHERE id = $2 AND board_id = $3`
+			tag, err := tx.Exec(ctx, query, op.TaskId, op.LabelId, old.BoardId)
+			if err != nil {
+				return nil, err
+			}
+
+			if tag.RowsAffected() == 0 {
+				return nil, fmt.Errorf("%w: board has no label %d", models.ErrInvalidInput, op.LabelId)
+			}
+		} else {
+			query := `DELETE FROM task_labels WHERE task_id = $1 AND label_id = $2`
+			if _, err := tx.Exec(ctx, query, op.TaskId, op.LabelId); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE tasks SET updated_at = NOW() WHERE id = $1`, op.TaskId); err != nil {
+			return nil, err
+		}
+
+		// labels are shown on board, assignee isn't notified about them
+		return nil, queueTaskEvent(ctx, tx, op.TaskId, actorID, models.BoardEventTaskUpdated)
+	}
+
+	return nil, fmt.Errorf("%w: unknown operation %q", models.ErrInvalidInput, op.Op)
+}
+
+// bulkChanges describes current state of task for every kind of change
+func bulkChanges(ctx context.Context, tx pgx.Tx, id uint, kinds ...string) ([]dto.BulkChangeDto, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+
+	change := dto.BulkChangeDto{TaskId: id}
+	query := `SELECT COALESCE(t.board_id, 0), COALESCE(t.user_id, 0), t.title, COALESCE(s.type, '')
+		FROM tasks t LEFT JOIN statuses s ON s.id = t.status_id WHERE t.id = $1`
+	if err := tx.QueryRow(ctx, query, id).Scan(&change.BoardId, &change.UserId, &change.Title, &change.Status); err != nil {
+		return nil, err
+	}
+
+	changes := make([]dto.BulkChangeDto, 0, len(kinds))
+	for _, kind := range kinds {
+		change.Kind = kind
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// queueBulkNotifications writes one message per assignee and one per board chat,
+// assignees are not notified about their own changes and board chats get only status changes
+func queueBulkNotifications(ctx context.Context, tx pgx.Tx, changes []dto.BulkChangeDto, actorID uint) error {
+	var users, boards []uint
+	byUser := make(map[uint][]dto.BulkChangeDto)
+	byBoard := make(map[uint][]dto.BulkChangeDto)
+	for _, change := range changes {
+		if change.UserId != 0 && change.UserId != actorID {
+			if _, ok := byUser[change.UserId]; !ok {
+				users = append(users, change.UserId)
+			}
+			byUser[change.UserId] = append(byUser[change.UserId], change)
+		}
+
+		if change.Kind == models.NotifyStatusChanged {
+			if _, ok := byBoard[change.BoardId]; !ok {
+				boards = append(boards, change.BoardId)
+			}
+			byBoard[change.BoardId] = append(byBoard[change.BoardId], change)
+		}
+	}
+
+	batch := time.Now().UnixNano()
+	for _, userID := range users {
+		event := dto.BulkEventDto{UserId: userID, ActorId: actorID, Changes: byUser[userID]}
+		key := fmt.Sprintf("%s:%d:%d:user:%d", models.EventTasksBulk, actorID, batch, userID)
+		if err := insertOutbox(ctx, tx, models.EventTasksBulk, key, event, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	for _, boardID := range boards {
+		event := dto.BulkEventDto{BoardId: boardID, ActorId: actorID, Changes: byBoard[boardID]}
+		key := fmt.Sprintf("%s:%d:%d:board:%d", models.EventTasksBulk, actorID, batch, boardID)
+		if err := insertOutbox(ctx, tx, models.EventTasksBulk, key, event, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
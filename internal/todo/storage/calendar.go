@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type CalendarStorage struct {
+	db *pgxpool.Pool
+}
+
+type CalendarStorager interface {
+	GetCalendarToken(userID uint) (*models.CalendarToken, error)
+	SetCalendarToken(userID uint, token string) (*models.CalendarToken, error)
+	DeleteCalendarToken(userID uint) error
+	GetCalendarUser(token string) (uint, error)
+	GetCalendarTasks(userID uint, filter dto.CalendarFilterDto) ([]models.CalendarTask, error)
+}
+
+func NewCalendarStore(Conn *pgxpool.Pool, log *zap.Logger) *CalendarStorage {
+	return &CalendarStorage{db: Conn}
+}
+
+func (d *CalendarStorage) GetCalendarToken(userID uint) (*models.CalendarToken, error) {
+	query := `SELECT user_id, token, created_at FROM calendar_tokens WHERE user_id = $1`
+
+	var token models.CalendarToken
+	err := d.db.QueryRow(context.Background(), query, userID).Scan(&token.UserId, &token.Token, &token.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// set new token of user, old one stops working
+func (d *CalendarStorage) SetCalendarToken(userID uint, token string) (*models.CalendarToken, error) {
+	query := `INSERT INTO calendar_tokens (user_id, token) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = NOW()
+		RETURNING user_id, token, created_at`
+
+	var res models.CalendarToken
+	err := d.db.QueryRow(context.Background(), query, userID, token).Scan(&res.UserId, &res.Token, &res.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (d *CalendarStorage) DeleteCalendarToken(userID uint) error {
+	query := `DELETE FROM calendar_tokens WHERE user_id = $1`
+	_, err := d.db.Exec(context.Background(), query, userID)
+
+	return err
+}
+
+// get owner of token, 0 when token is unknown
+func (d *CalendarStorage) GetCalendarUser(token string) (uint, error) {
+	query := `SELECT user_id FROM calendar_tokens WHERE token = $1`
+
+	var userID uint
+	err := d.db.QueryRow(context.Background(), query, token).Scan(&userID)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+
+	return userID, err
+}
+
+// get not archived tasks with due date from boards of user. Labels are matched with names of
+// labels of task and values of select and multi_select fields in any case
+func (d *CalendarStorage) GetCalendarTasks(userID uint, filter dto.CalendarFilterDto) ([]models.CalendarTask, error) {
+	labels := make([]string, 0, len(filter.Labels))
+	for _, label := range filter.Labels {
+		labels = append(labels, strings.ToLower(label))
+	}
+
+	query := `SELECT ` + taskColumns + `, b.name, COALESCE(s.type, ''), COALESCE(u.username, '')
+		FROM tasks t
+		JOIN boards b ON b.id = t.board_id
+		JOIN boards_users bu ON bu.board_id = t.board_id AND bu.user_id = $1
+		LEFT JOIN statuses s ON s.id = t.status_id
+		LEFT JOIN users u ON u.id = t.user_id
+		WHERE t.due_at IS NOT NULL AND t.status_id IS DISTINCT FROM $2
+			AND (cardinality($3::int[]) = 0 OR t.board_id = ANY($3))
+			AND (cardinality($4::text[]) = 0 OR EXISTS (
+				SELECT 1 FROM task_labels tl JOIN board_labels l ON l.id = tl.label_id
+				WHERE tl.task_id = t.id AND lower(l.name) = ANY($4)) OR EXISTS (
+				SELECT 1 FROM board_fields f
+				JOIN jsonb_each(t.custom_fields) v ON v.key = f.id::text
+				WHERE f.board_id = t.board_id AND f.type IN ('select', 'multi_select')
+					AND CASE WHEN jsonb_typeof(v.value) = 'array'
+						THEN EXISTS (SELECT 1 FROM jsonb_array_elements_text(v.value) e WHERE lower(e) = ANY($4))
+						ELSE lower(v.value #>> '{}') = ANY($4) END))
+		ORDER BY t.due_at, t.id`
+	rows, err := d.db.Query(context.Background(), query, userID, models.StatusArchived, filter.Boards, labels)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.CalendarTask
+	for rows.Next() {
+		var task models.CalendarTask
+		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.Priority,
+			&task.DueAt, &task.Estimate, &task.CreatedAt, &task.UpdatedAt, &task.TrackedSeconds, &task.CustomFields, &task.Labels,
+			&task.BoardName, &task.Status, &task.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,387 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// postgres channel with "<board_id>:<event_id>" of new board events
+	boardEventsChannel = "board_events"
+	// postgres channel with id of board whose viewers changed
+	boardPresenceChannel = "board_presence"
+	// class of advisory locks which order events of one board
+	boardEventsLock = 1
+)
+
+type EventsStorage struct {
+	db *pgxpool.Pool
+}
+
+type EventsStorager interface {
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetEvent(id uint) (*models.BoardEvent, error)
+	GetEvents(boardID uint, afterID uint, limit int) ([]models.BoardEvent, error)
+	GetOldestEventID() (uint, error)
+	PruneEvents(before time.Time) error
+	Listen(ctx context.Context, onEvent func(boardID uint, id uint), onPresence func(boardID uint)) error
+	JoinBoard(connID string, boardID uint, userID uint) error
+	LeaveBoard(connID string) error
+	TouchPresence(connIDs []string) error
+	PrunePresence(before time.Time) error
+	GetViewers(boardID uint, since time.Time) ([]models.Viewer, error)
+}
+
+// columns for scanEvents
+const eventColumns = `id, board_id, event, payload, created_at`
+
+func scanEvents(rows pgx.Rows) ([]models.BoardEvent, error) {
+	var events []models.BoardEvent
+	for rows.Next() {
+		var e models.BoardEvent
+		if err := rows.Scan(&e.ID, &e.BoardId, &e.Event, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func NewEventsStore(Conn *pgxpool.Pool, log *zap.Logger) *EventsStorage {
+	return &EventsStorage{db: Conn}
+}
+
+// write board event to the log for live subscribers and queue its delivery
+// to every active webhook subscribed to it, inside the caller's transaction when tx is passed.
+// Listeners of boardEventsChannel are notified on commit.
+// Subscribers resume by the last event id, so ids of one board must follow commit order:
+// the lock of board is held till the end of transaction, the next event gets its id after that
+func queueBoardEvent(ctx context.Context, tx execer, boardID uint, actorID uint, event string, data any) error {
+	body, err := json.Marshal(dto.BoardEventDto{
+		Event:      event,
+		BoardId:    boardID,
+		ActorId:    actorID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	query := `WITH e AS (
+			INSERT INTO board_events (board_id, event, payload)
+			SELECT $1::integer, $2::varchar, $3::jsonb FROM (SELECT pg_advisory_xact_lock($4::integer, $1::integer)) l
+			RETURNING id
+		)
+		SELECT pg_notify('` + boardEventsChannel + `', $1::text || ':' || id) FROM e`
+	_, err = tx.Exec(ctx, query, boardID, event, body, boardEventsLock)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT id, $2, $3 FROM board_webhooks
+		WHERE board_id = $1 AND active AND (cardinality(events) = 0 OR $2 = ANY(events))`
+	_, err = tx.Exec(ctx, query, boardID, event, body)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// lockBoardEvents takes locks of queueBoardEvent for several boards at once in ascending order of ids,
+// transaction which writes events of several boards must take them before the first event
+func lockBoardEvents(ctx context.Context, tx execer, boardIDs ...uint) error {
+	ids := make([]uint, 0, len(boardIDs))
+	for _, id := range boardIDs {
+		if id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, id := range slices.Compact(ids) {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1::integer, $2::integer)`, boardEventsLock, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queue task event with current state of task
+func queueTaskEvent(ctx context.Context, tx pgx.Tx, id uint, actorID uint, event string) error {
+	task, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	return queueBoardEvent(ctx, tx, task.BoardId, actorID, event, dto.TaskEventDataDto{Task: boardTask(task)})
+}
+
+// queue task.moved to the new board and to the old one if task left it
+func queueTaskMoved(ctx context.Context, tx pgx.Tx, id uint, actorID uint, from dto.TaskPlaceDto) error {
+	task, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	to := dto.TaskPlaceDto{BoardId: task.BoardId, StatusId: task.StatusId}
+	if from == to {
+		return nil
+	}
+
+	// locks of both boards are taken in order of ids, so opposite moves don't deadlock
+	if err := lockBoardEvents(ctx, tx, from.BoardId, to.BoardId); err != nil {
+		return err
+	}
+
+	data := dto.TaskEventDataDto{Task: boardTask(task), From: &from, To: &to}
+	if err := queueBoardEvent(ctx, tx, task.BoardId, actorID, models.BoardEventTaskMoved, data); err != nil {
+		return err
+	}
+
+	if from.BoardId != 0 && from.BoardId != to.BoardId {
+		return queueBoardEvent(ctx, tx, from.BoardId, actorID, models.BoardEventTaskMoved, data)
+	}
+
+	return nil
+}
+
+// get task inside transaction, row is locked till its end
+func lockTask(ctx context.Context, tx pgx.Tx, id uint) (*models.Task, error) {
+	var task models.Task
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.id = $1 FOR UPDATE`
+	if err := scanTask(tx.QueryRow(ctx, query, id), &task); err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+func boardTask(task *models.Task) dto.BoardTaskDto {
+	return dto.BoardTaskDto{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		BoardId:     task.BoardId,
+		StatusId:    task.StatusId,
+		UserId:      task.UserId,
+		Priority:    task.Priority,
+		DueAt:       task.DueAt,
+		Estimate:    task.Estimate,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+
+		CustomFields: task.CustomFields,
+		Labels:       task.Labels,
+	}
+}
+
+// check user is added to board
+func (d *EventsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// get event from the log, nil if it is already pruned
+func (d *EventsStorage) GetEvent(id uint) (*models.BoardEvent, error) {
+	query := `SELECT ` + eventColumns + ` FROM board_events WHERE id = $1`
+	rows, err := d.db.Query(context.Background(), query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	return &events[0], nil
+}
+
+// get events of board after given id, oldest first
+func (d *EventsStorage) GetEvents(boardID uint, afterID uint, limit int) ([]models.BoardEvent, error) {
+	query := `SELECT ` + eventColumns + ` FROM board_events WHERE board_id = $1 AND id > $2 ORDER BY id LIMIT $3`
+	rows, err := d.db.Query(context.Background(), query, boardID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// id of the oldest event kept in the log, 0 when it is empty
+func (d *EventsStorage) GetOldestEventID() (uint, error) {
+	var id uint
+	query := `SELECT COALESCE(MIN(id), 0) FROM board_events`
+	if err := d.db.QueryRow(context.Background(), query).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// delete events older than before
+func (d *EventsStorage) PruneEvents(before time.Time) error {
+	query := `DELETE FROM board_events WHERE created_at < $1`
+	_, err := d.db.Exec(context.Background(), query, before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Listen holds a connection with LISTEN on board channels and calls handlers
+// for every notification, sent on commit on any instance. Returns when ctx is done or connection fails
+func (d *EventsStorage) Listen(ctx context.Context, onEvent func(boardID uint, id uint), onPresence func(boardID uint)) error {
+	conn, err := d.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// connection with LISTEN must not go back to the pool
+	defer conn.Hijack().Close(context.Background())
+
+	for _, channel := range []string{boardEventsChannel, boardPresenceChannel} {
+		if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return err
+		}
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch notification.Channel {
+		case boardEventsChannel:
+			boardID, id, err := parseEventNotification(notification.Payload)
+			if err != nil {
+				return err
+			}
+			onEvent(boardID, id)
+		case boardPresenceChannel:
+			boardID, err := strconv.ParseUint(notification.Payload, 10, 32)
+			if err != nil {
+				return err
+			}
+			onPresence(uint(boardID))
+		}
+	}
+}
+
+// register connection of user to board
+func (d *EventsStorage) JoinBoard(connID string, boardID uint, userID uint) error {
+	query := `WITH p AS (INSERT INTO board_presence (conn_id, board_id, user_id) VALUES ($1, $2, $3) RETURNING board_id)
+		SELECT pg_notify('` + boardPresenceChannel + `', board_id::text) FROM p`
+	_, err := d.db.Exec(context.Background(), query, connID, boardID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// remove closed connection
+func (d *EventsStorage) LeaveBoard(connID string) error {
+	query := `WITH p AS (DELETE FROM board_presence WHERE conn_id = $1 RETURNING board_id)
+		SELECT pg_notify('` + boardPresenceChannel + `', board_id::text) FROM p`
+	_, err := d.db.Exec(context.Background(), query, connID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// mark connections of this instance as alive
+func (d *EventsStorage) TouchPresence(connIDs []string) error {
+	query := `UPDATE board_presence SET seen_at = NOW() WHERE conn_id = ANY($1)`
+	_, err := d.db.Exec(context.Background(), query, connIDs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// remove connections not seen since before, e.g. of stopped instance
+func (d *EventsStorage) PrunePresence(before time.Time) error {
+	query := `WITH p AS (DELETE FROM board_presence WHERE seen_at < $1 RETURNING board_id)
+		SELECT pg_notify('` + boardPresenceChannel + `', board_id::text) FROM (SELECT DISTINCT board_id FROM p) b`
+	_, err := d.db.Exec(context.Background(), query, before)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// users with alive connections to board
+func (d *EventsStorage) GetViewers(boardID uint, since time.Time) ([]models.Viewer, error) {
+	query := `SELECT DISTINCT u.id, u.username FROM board_presence p JOIN users u ON u.id = p.user_id
+		WHERE p.board_id = $1 AND p.seen_at >= $2 ORDER BY u.id`
+	rows, err := d.db.Query(context.Background(), query, boardID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var viewers []models.Viewer
+	for rows.Next() {
+		var viewer models.Viewer
+		if err := rows.Scan(&viewer.UserId, &viewer.Username); err != nil {
+			return nil, err
+		}
+		viewers = append(viewers, viewer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return viewers, nil
+}
+
+func parseEventNotification(payload string) (uint, uint, error) {
+	boardStr, idStr, ok := strings.Cut(payload, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid board event notification %q", payload)
+	}
+
+	boardID, err := strconv.ParseUint(boardStr, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return uint(boardID), uint(id), nil
+}
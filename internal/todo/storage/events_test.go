@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDB connects to database with applied migrations, tests are skipped without it
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+// the first transaction gets the smaller id, the second one tries to commit before it.
+// Subscriber which saw the second event must not lose the first one
+func TestBoardEventsFollowCommitOrder(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	d := &EventsStorage{db: db}
+
+	var boardID uint
+	if err := db.QueryRow(ctx, `INSERT INTO boards (name) VALUES ('events order') RETURNING id`).Scan(&boardID); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM boards WHERE id = $1`, boardID) })
+
+	listenCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	notified := make(chan uint, 2)
+	go d.Listen(listenCtx, func(board uint, id uint) {
+		if board == boardID {
+			notified <- id
+		}
+	}, func(uint) {})
+	time.Sleep(100 * time.Millisecond) // LISTEN is registered
+
+	first, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback(ctx)
+
+	if err := queueBoardEvent(ctx, first, boardID, 0, models.BoardEventTaskCreated, map[string]int{"n": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	secondDone := make(chan error, 1)
+	go func() {
+		second, err := db.Begin(ctx)
+		if err != nil {
+			secondDone <- err
+			return
+		}
+		defer second.Rollback(ctx)
+
+		if err := queueBoardEvent(ctx, second, boardID, 0, models.BoardEventTaskCreated, map[string]int{"n": 2}); err != nil {
+			secondDone <- err
+			return
+		}
+		secondDone <- second.Commit(ctx)
+	}()
+
+	select {
+	case err := <-secondDone:
+		t.Fatalf("second event committed before the first one: %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if err := first.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-secondDone; err != nil {
+		t.Fatal(err)
+	}
+
+	// live subscriber gets events in order of ids, so "id <= last sent" never drops one
+	var ids []uint
+	for len(ids) < 2 {
+		select {
+		case id := <-notified:
+			ids = append(ids, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("notified about %v", ids)
+		}
+	}
+	if ids[0] >= ids[1] {
+		t.Fatalf("events notified in order %v", ids)
+	}
+
+	// resume after the first event gives the second one
+	events, err := d.GetEvents(boardID, ids[0], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ID != ids[1] {
+		t.Fatalf("events after %d: %+v", ids[0], events)
+	}
+}
+
+// events of a move from board a to board b and of the opposite move wait for each other instead of deadlock
+func TestOppositeMovesLockBoardsInOrder(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	var a, b uint
+	query := `INSERT INTO boards (name) VALUES ('lock order a'), ('lock order b') RETURNING id`
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []*uint{&a, &b} {
+		rows.Next()
+		if err := rows.Scan(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows.Close()
+	t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM boards WHERE id IN ($1, $2)`, a, b) })
+
+	// move b -> a writes event of a first
+	first, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback(ctx)
+
+	if err := lockBoardEvents(ctx, first, a, b); err != nil {
+		t.Fatal(err)
+	}
+
+	secondDone := make(chan error, 1)
+	go func() {
+		second, err := db.Begin(ctx)
+		if err != nil {
+			secondDone <- err
+			return
+		}
+		defer second.Rollback(ctx)
+
+		// move a -> b writes event of b first
+		if err := lockBoardEvents(ctx, second, b, a); err != nil {
+			secondDone <- err
+			return
+		}
+		if err := queueBoardEvent(ctx, second, b, 0, models.BoardEventTaskMoved, map[string]int{"n": 2}); err != nil {
+			secondDone <- err
+			return
+		}
+		if err := queueBoardEvent(ctx, second, a, 0, models.BoardEventTaskMoved, map[string]int{"n": 2}); err != nil {
+			secondDone <- err
+			return
+		}
+		secondDone <- second.Commit(ctx)
+	}()
+	time.Sleep(300 * time.Millisecond) // second waits for lock of a
+
+	for _, board := range []uint{a, b} {
+		if err := queueBoardEvent(ctx, first, board, 0, models.BoardEventTaskMoved, map[string]int{"n": 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := first.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-secondDone:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second move is not committed")
+	}
+}
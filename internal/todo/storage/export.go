@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ExportStorage struct {
+	db *pgxpool.Pool
+}
+
+type ExportStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetStatuses() ([]models.Status, error)
+	GetExportBoards(userID uint, boardID uint) ([]models.ExportBoard, error)
+	EachExportTask(boardIDs []uint, fn func(task models.ExportTask) error) error
+}
+
+func NewExportStore(Conn *pgxpool.Pool, log *zap.Logger) *ExportStorage {
+	return &ExportStorage{db: Conn}
+}
+
+func (d *ExportStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *ExportStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func (d *ExportStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+// get boards of user with members, fields and labels, boardID = 0 gives all boards of user
+func (d *ExportStorage) GetExportBoards(userID uint, boardID uint) ([]models.ExportBoard, error) {
+	query := `SELECT ` + boardColumns + ` FROM boards b
+		JOIN boards_users bu ON bu.board_id = b.id
+		WHERE bu.user_id = $1 AND ($2 = 0 OR b.id = $2) ORDER BY b.id`
+	rows, err := d.db.Query(context.Background(), query, userID, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var boards []models.ExportBoard
+	for rows.Next() {
+		var board models.ExportBoard
+		if err := scanBoard(rows, &board.Board); err != nil {
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range boards {
+		if boards[i].Members, err = d.getMembers(boards[i].ID); err != nil {
+			return nil, err
+		}
+
+		if boards[i].Fields, err = getBoardFields(d.db, boards[i].ID); err != nil {
+			return nil, err
+		}
+
+		if boards[i].Labels, err = getBoardLabels(d.db, boards[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return boards, nil
+}
+
+func (d *ExportStorage) getMembers(boardID uint) ([]models.ExportUser, error) {
+	query := `SELECT u.id, u.username FROM boards_users bu JOIN users u ON u.id = bu.user_id WHERE bu.board_id = $1 ORDER BY u.id`
+	rows, err := d.db.Query(context.Background(), query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.ExportUser
+	for rows.Next() {
+		var member models.ExportUser
+		if err := rows.Scan(&member.ID, &member.Username); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// EachExportTask calls fn for every task of boards without loading them all,
+// tasks go in order of boardIDs, then by status and id
+func (d *ExportStorage) EachExportTask(boardIDs []uint, fn func(task models.ExportTask) error) error {
+	// keys of comments are names of fields of models.ExportComment
+	query := `SELECT ` + taskColumns + `, COALESCE(u.username, ''),
+		COALESCE((SELECT json_agg(json_build_object('UserId', COALESCE(c.user_id, 0), 'Author', COALESCE(cu.username, ''),
+			'Text', c.text, 'CreatedAt', c.created_at) ORDER BY c.id)
+			FROM task_comments c LEFT JOIN users cu ON cu.id = c.user_id WHERE c.task_id = t.id), '[]')
+		FROM tasks t LEFT JOIN users u ON u.id = t.user_id
+		WHERE t.board_id = ANY($1) ORDER BY array_position($1, t.board_id), t.status_id, t.id`
+	rows, err := d.db.Query(context.Background(), query, boardIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task models.ExportTask
+		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.Priority,
+			&task.DueAt, &task.Estimate, &task.CreatedAt, &task.UpdatedAt, &task.TrackedSeconds, &task.CustomFields, &task.Labels,
+			&task.Assignee, &task.Comments)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
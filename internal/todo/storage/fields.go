@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type FieldsStorage struct {
+	db *pgxpool.Pool
+}
+
+type FieldsStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetFields(boardID uint) ([]models.BoardField, error)
+	GetField(id uint) (*models.BoardField, error)
+	CreateField(field models.BoardField) (*models.BoardField, error)
+	UpdateField(field models.BoardField) (*models.BoardField, error)
+	DeleteField(field *models.BoardField) error
+}
+
+// columns for scanField
+const fieldColumns = `id, board_id, name, type, options, position, created_at, updated_at`
+
+func scanField(row pgx.Row, field *models.BoardField) error {
+	return row.Scan(&field.ID, &field.BoardId, &field.Name, &field.Type, &field.Options, &field.Position, &field.CreatedAt, &field.UpdatedAt)
+}
+
+func NewFieldsStore(Conn *pgxpool.Pool, log *zap.Logger) *FieldsStorage {
+	return &FieldsStorage{db: Conn}
+}
+
+// board of fields, to check its owner
+func (d *FieldsStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *FieldsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// get fields of board in their order
+func (d *FieldsStorage) GetFields(boardID uint) ([]models.BoardField, error) {
+	return getBoardFields(d.db, boardID)
+}
+
+func getBoardFields(db *pgxpool.Pool, boardID uint) ([]models.BoardField, error) {
+	query := `SELECT ` + fieldColumns + ` FROM board_fields WHERE board_id = $1 ORDER BY position, id`
+	rows, err := db.Query(context.Background(), query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fields []models.BoardField
+	for rows.Next() {
+		var field models.BoardField
+		if err := scanField(rows, &field); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// get field, nil if there is none
+func (d *FieldsStorage) GetField(id uint) (*models.BoardField, error) {
+	return getBoardField(d.db, id)
+}
+
+func getBoardField(db *pgxpool.Pool, id uint) (*models.BoardField, error) {
+	query := `SELECT ` + fieldColumns + ` FROM board_fields WHERE id = $1`
+
+	var field models.BoardField
+	err := scanField(db.QueryRow(context.Background(), query, id), &field)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &field, nil
+}
+
+// add field to board
+func (d *FieldsStorage) CreateField(field models.BoardField) (*models.BoardField, error) {
+	query := `INSERT INTO board_fields (board_id, name, type, options, position) VALUES ($1, $2, $3, $4, $5) RETURNING ` + fieldColumns
+	row := d.db.QueryRow(context.Background(), query, field.BoardId, field.Name, field.Type, field.Options, field.Position)
+
+	var created models.BoardField
+	if err := scanField(row, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// update name, options and position of field. Values with removed options are removed from tasks and task templates
+func (d *FieldsStorage) UpdateField(field models.BoardField) (*models.BoardField, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE board_fields SET name = $1, options = $2, position = $3, updated_at = NOW() WHERE id = $4 RETURNING ` + fieldColumns
+	var updated models.BoardField
+	if err := scanField(tx.QueryRow(ctx, query, field.Name, field.Options, field.Position, field.ID), &updated); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// task templates keep values the same way as tasks
+	key := strconv.FormatUint(uint64(field.ID), 10)
+	for _, table := range []string{"tasks", "task_templates"} {
+		switch updated.Type {
+		case models.FieldSelect:
+			query = `UPDATE ` + table + ` SET custom_fields = custom_fields - $2::text
+				WHERE board_id = $1 AND custom_fields ? $2::text AND NOT (custom_fields->>$2::text = ANY($3))`
+			if _, err := tx.Exec(ctx, query, updated.BoardId, key, updated.Options); err != nil {
+				return nil, err
+			}
+		case models.FieldMultiSelect:
+			query = `UPDATE ` + table + ` SET custom_fields = jsonb_set(custom_fields, ARRAY[$2::text],
+					(SELECT COALESCE(jsonb_agg(v), '[]') FROM jsonb_array_elements_text(custom_fields->$2::text) v WHERE v = ANY($3)))
+				WHERE board_id = $1 AND custom_fields ? $2::text`
+			if _, err := tx.Exec(ctx, query, updated.BoardId, key, updated.Options); err != nil {
+				return nil, err
+			}
+
+			query = `UPDATE ` + table + ` SET custom_fields = custom_fields - $2::text WHERE board_id = $1 AND custom_fields->$2::text = '[]'`
+			if _, err := tx.Exec(ctx, query, updated.BoardId, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// delete field with its values in tasks and task templates of board
+func (d *FieldsStorage) DeleteField(field *models.BoardField) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	key := strconv.FormatUint(uint64(field.ID), 10)
+	for _, table := range []string{"tasks", "task_templates"} {
+		query := `UPDATE ` + table + ` SET custom_fields = custom_fields - $2::text WHERE board_id = $1 AND custom_fields ? $2::text`
+		if _, err := tx.Exec(ctx, query, field.BoardId, key); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM board_fields WHERE id = $1`, field.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
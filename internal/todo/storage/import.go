@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type ImportStorage struct {
+	db *pgxpool.Pool
+}
+
+type ImportStorager interface {
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetStatuses() ([]models.Status, error)
+	GetUsersByUsernames(usernames []string) (map[string]uint, error)
+	GetImported(userID uint, source string, kind string, externalIDs []string) (map[string]uint, error)
+	Import(plan models.ImportPlan, userID uint) (uint, error)
+}
+
+func NewImportStore(Conn *pgxpool.Pool, log *zap.Logger) *ImportStorage {
+	return &ImportStorage{db: Conn}
+}
+
+// check user is added to board
+func (d *ImportStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func (d *ImportStorage) GetBoardFields(boardID uint) ([]models.BoardField, error) {
+	return getBoardFields(d.db, boardID)
+}
+
+func (d *ImportStorage) GetBoardLabels(boardID uint) ([]models.BoardLabel, error) {
+	return getBoardLabels(d.db, boardID)
+}
+
+func (d *ImportStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+// get ids of users by usernames in any case, keys are lowercase usernames
+func (d *ImportStorage) GetUsersByUsernames(usernames []string) (map[string]uint, error) {
+	lower := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lower = append(lower, strings.ToLower(username))
+	}
+
+	query := `SELECT id, lower(username) FROM users WHERE lower(username) = ANY($1)`
+	rows, err := d.db.Query(context.Background(), query, lower)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string]uint)
+	for rows.Next() {
+		var id uint
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		users[username] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// get items imported by user before, values are ids of boards or tasks, 0 for deleted task
+func (d *ImportStorage) GetImported(userID uint, source string, kind string, externalIDs []string) (map[string]uint, error) {
+	query := `SELECT external_id, CASE WHEN kind = 'board' THEN board_id ELSE COALESCE(task_id, 0) END
+		FROM imports WHERE user_id = $1 AND source = $2 AND kind = $3 AND external_id = ANY($4)`
+	rows, err := d.db.Query(context.Background(), query, userID, source, kind, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	imported := make(map[string]uint)
+	for rows.Next() {
+		var externalID string
+		var id uint
+		if err := rows.Scan(&externalID, &id); err != nil {
+			return nil, err
+		}
+		imported[externalID] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// Import creates board of plan or adds to board of previous import members, fields, labels and new tasks.
+// Imported tasks are history, so no notifications and events are sent
+func (d *ImportStorage) Import(plan models.ImportPlan, userID uint) (uint, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	boardID := plan.BoardId
+	if boardID == 0 {
+		query := `INSERT INTO boards (name, owner_id, estimate_unit) VALUES ($1, $2, $3) RETURNING id`
+		if err := tx.QueryRow(ctx, query, plan.Name, userID, plan.EstimateUnit).Scan(&boardID); err != nil {
+			return 0, err
+		}
+
+		query = `INSERT INTO boards_users (user_id, board_id) VALUES ($1, $2)`
+		if _, err := tx.Exec(ctx, query, userID, boardID); err != nil {
+			return 0, err
+		}
+
+		if err := insertImport(ctx, tx, userID, plan.Source, models.ImportedBoard, plan.ExternalId, boardID, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, memberID := range plan.Members {
+		query := `INSERT INTO boards_users (user_id, board_id) SELECT $1, $2
+			WHERE NOT EXISTS (SELECT 1 FROM boards_users WHERE user_id = $1 AND board_id = $2)`
+		if _, err := tx.Exec(ctx, query, memberID, boardID); err != nil {
+			return 0, err
+		}
+	}
+
+	labelsID := plan.LabelsId
+	if len(plan.Labels) > 0 {
+		if labelsID == 0 {
+			query := `INSERT INTO board_fields (board_id, name, type, options, position)
+				VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(position) + 1, 0) FROM board_fields WHERE board_id = $1)) RETURNING id`
+			err := tx.QueryRow(ctx, query, boardID, models.ImportLabelsField, models.FieldMultiSelect, plan.Labels).Scan(&labelsID)
+			if err != nil {
+				return 0, err
+			}
+		} else {
+			query := `UPDATE board_fields SET options = $1, updated_at = NOW() WHERE id = $2`
+			if _, err := tx.Exec(ctx, query, plan.Labels, labelsID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	for _, field := range plan.Fields {
+		query := `INSERT INTO board_fields (board_id, name, type, options, position)
+			VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(position) + 1, 0) FROM board_fields WHERE board_id = $1))`
+		if _, err := tx.Exec(ctx, query, boardID, field.Name, field.Type, field.Options); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, label := range plan.BoardLabels {
+		query := `INSERT INTO board_labels (board_id, name, color) VALUES ($1, $2, $3)`
+		if _, err := tx.Exec(ctx, query, boardID, label.Name, label.Color); err != nil {
+			return 0, err
+		}
+	}
+
+	// values and labels of tasks are planned by names, new ones got their ids just now
+	fields, err := namedIDs(ctx, tx, `SELECT id, lower(name) FROM board_fields WHERE board_id = $1`, boardID)
+	if err != nil {
+		return 0, err
+	}
+
+	labels, err := namedIDs(ctx, tx, `SELECT id, lower(name) FROM board_labels WHERE board_id = $1`, boardID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range plan.Tasks {
+		task := item.Task
+		task.BoardId = boardID
+		task.CustomFields = make(map[string]any, len(item.FieldValues)+1)
+		if len(item.Labels) > 0 {
+			task.CustomFields[strconv.FormatUint(uint64(labelsID), 10)] = item.Labels
+		}
+		for name, value := range item.FieldValues {
+			if id, ok := fields[name]; ok {
+				task.CustomFields[strconv.FormatUint(uint64(id), 10)] = value
+			}
+		}
+		for _, name := range item.LabelNames {
+			if id, ok := labels[strings.ToLower(name)]; ok {
+				task.Labels = append(task.Labels, id)
+			}
+		}
+
+		id, err := copyTask(ctx, tx, &task, false, userID)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, comment := range item.Comments {
+			query := `INSERT INTO task_comments (task_id, user_id, text, created_at)
+				VALUES ($1, NULLIF($2, 0), $3, COALESCE($4, NOW()))`
+			var createdAt *time.Time
+			if !comment.CreatedAt.IsZero() {
+				createdAt = &comment.CreatedAt
+			}
+			if _, err := tx.Exec(ctx, query, id, comment.UserId, comment.Text, createdAt); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := insertImport(ctx, tx, userID, plan.Source, models.ImportedTask, item.ExternalId, boardID, id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return boardID, nil
+}
+
+// namedIDs returns ids by names of query rows
+func namedIDs(ctx context.Context, tx pgx.Tx, query string, boardID uint) (map[string]uint, error) {
+	rows, err := tx.Query(ctx, query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]uint)
+	for rows.Next() {
+		var id uint
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		ids[name] = id
+	}
+
+	return ids, rows.Err()
+}
+
+func insertImport(ctx context.Context, tx pgx.Tx, userID uint, source string, kind string, externalID string, boardID uint, taskID uint) error {
+	query := `INSERT INTO imports (user_id, source, kind, external_id, board_id, task_id) VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0))`
+	_, err := tx.Exec(ctx, query, userID, source, kind, externalID, boardID, taskID)
+
+	return err
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type LabelsStorage struct {
+	db *pgxpool.Pool
+}
+
+type LabelsStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetLabels(boardID uint) ([]models.BoardLabel, error)
+	GetLabel(id uint) (*models.BoardLabel, error)
+	CreateLabel(label models.BoardLabel) (*models.BoardLabel, error)
+	UpdateLabel(label models.BoardLabel) (*models.BoardLabel, error)
+	DeleteLabel(label *models.BoardLabel) error
+}
+
+// columns for scanLabel
+const labelColumns = `id, board_id, name, color, created_at`
+
+func scanLabel(row pgx.Row, label *models.BoardLabel) error {
+	return row.Scan(&label.ID, &label.BoardId, &label.Name, &label.Color, &label.CreatedAt)
+}
+
+func NewLabelsStore(Conn *pgxpool.Pool, log *zap.Logger) *LabelsStorage {
+	return &LabelsStorage{db: Conn}
+}
+
+// board of labels, to check its owner
+func (d *LabelsStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *LabelsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// get labels of board by name
+func (d *LabelsStorage) GetLabels(boardID uint) ([]models.BoardLabel, error) {
+	return getBoardLabels(d.db, boardID)
+}
+
+func getBoardLabels(db *pgxpool.Pool, boardID uint) ([]models.BoardLabel, error) {
+	query := `SELECT ` + labelColumns + ` FROM board_labels WHERE board_id = $1 ORDER BY lower(name), id`
+	rows, err := db.Query(context.Background(), query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var labels []models.BoardLabel
+	for rows.Next() {
+		var label models.BoardLabel
+		if err := scanLabel(rows, &label); err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// get label, nil if there is none
+func (d *LabelsStorage) GetLabel(id uint) (*models.BoardLabel, error) {
+	query := `SELECT ` + labelColumns + ` FROM board_labels WHERE id = $1`
+
+	var label models.BoardLabel
+	err := scanLabel(d.db.QueryRow(context.Background(), query, id), &label)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &label, nil
+}
+
+// add label to board
+func (d *LabelsStorage) CreateLabel(label models.BoardLabel) (*models.BoardLabel, error) {
+	query := `INSERT INTO board_labels (board_id, name, color) VALUES ($1, $2, $3) RETURNING ` + labelColumns
+	row := d.db.QueryRow(context.Background(), query, label.BoardId, label.Name, label.Color)
+
+	var created models.BoardLabel
+	if err := scanLabel(row, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// rename label or change its color, tasks keep it
+func (d *LabelsStorage) UpdateLabel(label models.BoardLabel) (*models.BoardLabel, error) {
+	query := `UPDATE board_labels SET name = $1, color = $2 WHERE id = $3 RETURNING ` + labelColumns
+
+	var updated models.BoardLabel
+	if err := scanLabel(d.db.QueryRow(context.Background(), query, label.Name, label.Color, label.ID), &updated); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// delete label, tasks lose it by foreign key and task templates of board here
+func (d *LabelsStorage) DeleteLabel(label *models.BoardLabel) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE task_templates SET labels = array_remove(labels, $2::integer) WHERE board_id = $1 AND $2 = ANY(labels)`
+	if _, err := tx.Exec(ctx, query, label.BoardId, label.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM board_labels WHERE id = $1`, label.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// setTaskLabels replaces labels of task, ids are checked to be labels of its board by service
+func setTaskLabels(ctx context.Context, tx pgx.Tx, taskID uint, labels []uint) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM task_labels WHERE task_id = $1`, taskID); err != nil {
+		return err
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO task_labels (task_id, label_id) SELECT $1, unnest($2::integer[]) ON CONFLICT DO NOTHING`
+	_, err := tx.Exec(ctx, query, taskID, labels)
+
+	return err
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// expired nonces are deleted not more often than this
+const nonceSweepPeriod = time.Minute
+
+// NoncesStorage keeps nonces of signed internal requests for all instances of todo
+type NoncesStorage struct {
+	db *pgxpool.Pool
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewNoncesStore(Conn *pgxpool.Pool, log *zap.Logger) *NoncesStorage {
+	return &NoncesStorage{db: Conn}
+}
+
+// Add stores nonce till expiresAt, false if it is already used.
+// Expired nonces are deleted once per sweep period, not on every request
+func (d *NoncesStorage) Add(nonce string, expiresAt time.Time) (bool, error) {
+	ctx := context.Background()
+
+	if d.sweepDue() {
+		if _, err := d.db.Exec(ctx, `DELETE FROM signature_nonces WHERE expires_at < NOW()`); err != nil {
+			return false, err
+		}
+	}
+
+	// expired row of the same nonce is replaced, its request is rejected by timestamp anyway
+	query := `INSERT INTO signature_nonces (nonce, expires_at) VALUES ($1, $2)
+		ON CONFLICT (nonce) DO UPDATE SET expires_at = $2 WHERE signature_nonces.expires_at < NOW()`
+	tag, err := d.db.Exec(ctx, query, nonce, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (d *NoncesStorage) sweepDue() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.lastSweep) < nonceSweepPeriod {
+		return false
+	}
+
+	d.lastSweep = time.Now()
+	return true
+}
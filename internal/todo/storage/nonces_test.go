@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// nonce is accepted once till it expires, the same for all instances
+func TestNonceIsUsedOnce(t *testing.T) {
+	db := testDB(t)
+	first, second := NewNoncesStore(db, nil), NewNoncesStore(db, nil)
+
+	nonce := "test-" + time.Now().Format(time.RFC3339Nano)
+	t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM signature_nonces WHERE nonce = $1`, nonce) })
+
+	if ok, err := first.Add(nonce, time.Now().Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("new nonce: %v %v", ok, err)
+	}
+	if ok, err := second.Add(nonce, time.Now().Add(time.Minute)); err != nil || ok {
+		t.Fatalf("nonce used on another instance: %v %v", ok, err)
+	}
+}
@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type NotificationsStorage struct {
+	db *pgxpool.Pool
+}
+
+type NotificationsStorager interface {
+	GetSettings(userID uint) (*models.NotificationSettings, error)
+	SaveSettings(settings models.NotificationSettings) error
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetHeldUsers() ([]uint, error)
+	FlushHeld(userID uint) error
+	CreateEmailCode(userID uint, email string, code string, expiresAt time.Time) (bool, error)
+	ConfirmEmail(userID uint, code string) (bool, error)
+}
+
+func NewNotificationsStore(Conn *pgxpool.Pool, log *zap.Logger) *NotificationsStorage {
+	return &NotificationsStorage{db: Conn}
+}
+
+// get settings of user, defaults if he didn't change them
+func (d *NotificationsStorage) GetSettings(userID uint) (*models.NotificationSettings, error) {
+	return getNotificationSettings(d.db, userID)
+}
+
+// save settings and replace muted boards
+func (d *NotificationsStorage) SaveSettings(settings models.NotificationSettings) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// empty webhook secret keeps the current one, new email must be confirmed again
+	query := `INSERT INTO notification_settings (user_id, notify_created, notify_assigned, notify_commented, notify_due,
+			notify_status_changed, notify_digest, quiet_from, quiet_to, timezone, email, webhook_url, webhook_secret, notify_digest_analytics)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, ''), $14)
+		ON CONFLICT (user_id) DO UPDATE SET notify_created = $2, notify_assigned = $3, notify_commented = $4,
+			notify_due = $5, notify_status_changed = $6, notify_digest = $7, quiet_from = $8, quiet_to = $9, timezone = $10,
+			email_confirmed = notification_settings.email_confirmed AND notification_settings.email IS NOT DISTINCT FROM NULLIF($11, ''),
+			email_code = CASE WHEN notification_settings.email IS NOT DISTINCT FROM NULLIF($11, '') THEN notification_settings.email_code END,
+			email = NULLIF($11, ''), webhook_url = NULLIF($12, ''),
+			webhook_secret = COALESCE(NULLIF($13, ''), notification_settings.webhook_secret), notify_digest_analytics = $14`
+	_, err = tx.Exec(ctx, query, settings.UserId, settings.Created, settings.Assigned, settings.Commented, settings.Due,
+		settings.StatusChanged, settings.Digest, settings.QuietFrom, settings.QuietTo, settings.Timezone,
+		settings.Email, settings.WebhookURL, settings.WebhookSecret, settings.DigestAnalytics)
+	if err != nil {
+		return err
+	}
+
+	query = `DELETE FROM notification_channels WHERE user_id = $1`
+	if _, err := tx.Exec(ctx, query, settings.UserId); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO notification_channels (user_id, kind, channel) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	for kind, channels := range settings.Channels {
+		for _, channel := range channels {
+			if _, err := tx.Exec(ctx, query, settings.UserId, kind, channel); err != nil {
+				return err
+			}
+		}
+	}
+
+	query = `DELETE FROM board_mutes WHERE user_id = $1`
+	if _, err := tx.Exec(ctx, query, settings.UserId); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO board_mutes (user_id, board_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, boardID := range settings.MutedBoards {
+		if _, err := tx.Exec(ctx, query, settings.UserId, boardID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// replace confirmation code of email and queue letter with it, false when email is already confirmed,
+// changed or the previous code was sent less than a minute ago
+func (d *NotificationsStorage) CreateEmailCode(userID uint, email string, code string, expiresAt time.Time) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE notification_settings SET email_code = $3, email_code_expires_at = $4
+		WHERE user_id = $1 AND email = $2 AND NOT email_confirmed
+			AND (email_code IS NULL OR email_code_expires_at < $4 - interval '1 minute')`
+	tag, err := tx.Exec(ctx, query, userID, email, code, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	event := dto.EmailConfirmEventDto{UserId: userID, Email: email, Code: code}
+	key := fmt.Sprintf("%s:%d:%s", models.EventEmailConfirm, userID, code)
+	if err := insertOutbox(ctx, tx, models.EventEmailConfirm, key, event, time.Now()); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// confirm email of user by code from letter, false when code is wrong or expired
+func (d *NotificationsStorage) ConfirmEmail(userID uint, code string) (bool, error) {
+	query := `UPDATE notification_settings SET email_confirmed = TRUE, email_code = NULL, email_code_expires_at = NULL
+		WHERE user_id = $1 AND email_code = $2 AND email_code_expires_at > NOW()`
+	tag, err := d.db.Exec(context.Background(), query, userID, code)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// check user is added to board
+func (d *NotificationsStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// users who have notifications held by quiet hours
+func (d *NotificationsStorage) GetHeldUsers() ([]uint, error) {
+	query := `SELECT DISTINCT user_id FROM held_notifications`
+	rows, err := d.db.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		users = append(users, id)
+	}
+
+	return users, rows.Err()
+}
+
+// replace held notifications of user with one summary in outbox
+func (d *NotificationsStorage) FlushHeld(userID uint) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `DELETE FROM held_notifications WHERE user_id = $1 RETURNING id, text`
+	rows, err := tx.Query(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	var lastID uint
+	var lines []string
+	for rows.Next() {
+		var id uint
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			rows.Close()
+			return err
+		}
+		if id > lastID {
+			lastID = id
+		}
+		lines = append(lines, "• "+text)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	event := dto.SummaryEventDto{
+		UserId: userID,
+		Text:   "Пока действовали тихие часы:\n\n" + strings.Join(lines, "\n"),
+	}
+	key := fmt.Sprintf("%s:%d:%d", models.EventSummary, userID, lastID)
+	if err := insertOutbox(ctx, tx, models.EventSummary, key, event, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func getNotificationSettings(db *pgxpool.Pool, userID uint) (*models.NotificationSettings, error) {
+	ctx := context.Background()
+	settings := models.DefaultNotificationSettings(userID)
+
+	query := `SELECT notify_created, notify_assigned, notify_commented, notify_due, notify_status_changed, notify_digest,
+		quiet_from, quiet_to, timezone, COALESCE(email, ''), COALESCE(webhook_url, ''), COALESCE(webhook_secret, ''), notify_digest_analytics,
+		email_confirmed
+		FROM notification_settings WHERE user_id = $1`
+	err := db.QueryRow(ctx, query, userID).Scan(&settings.Created, &settings.Assigned, &settings.Commented, &settings.Due,
+		&settings.StatusChanged, &settings.Digest, &settings.QuietFrom, &settings.QuietTo, &settings.Timezone,
+		&settings.Email, &settings.WebhookURL, &settings.WebhookSecret, &settings.DigestAnalytics, &settings.EmailConfirmed)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	query = `SELECT kind, channel FROM notification_channels WHERE user_id = $1 ORDER BY kind, channel`
+	channelRows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer channelRows.Close()
+
+	settings.Channels = make(map[string][]string)
+	for channelRows.Next() {
+		var kind, channel string
+		if err := channelRows.Scan(&kind, &channel); err != nil {
+			return nil, err
+		}
+		settings.Channels[kind] = append(settings.Channels[kind], channel)
+	}
+
+	if err := channelRows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT board_id FROM board_mutes WHERE user_id = $1 ORDER BY board_id`
+	rows, err := db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var boardID uint
+		if err := rows.Scan(&boardID); err != nil {
+			return nil, err
+		}
+		settings.MutedBoards = append(settings.MutedBoards, boardID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// keep notification till the end of quiet hours, retry of outbox message with the same key adds nothing
+func holdNotification(db *pgxpool.Pool, userID uint, text string, key string) error {
+	query := `INSERT INTO held_notifications (user_id, text, idempotency_key) VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO NOTHING`
+	_, err := db.Exec(context.Background(), query, userID, text, key)
+	return err
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type OutboxStorage struct {
+	db *pgxpool.Pool
+}
+
+type OutboxStorager interface {
+	ClaimDue(limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	MarkSent(id uint, status string) error
+	MarkFailed(id uint, lastError string, nextAttemptAt time.Time, dead bool) error
+	GetOutbox(status string) ([]models.OutboxMessage, error)
+	ReplayMessage(id uint) (*models.OutboxMessage, error)
+	GetChatIDByUser(userID uint) (*int64, error)
+	GetChatIDByBoard(boardID uint) (*int64, error)
+	DeactivateTelegram(userID uint, chatID int64) error
+	UnlinkBoardChat(boardID uint, chatID int64) error
+	GetNotificationSettings(userID uint) (*models.NotificationSettings, error)
+	HoldNotification(userID uint, text string, key string) error
+	Enqueue(eventType string, key string, payload any) error
+}
+
+func NewOutboxStore(Conn *pgxpool.Pool, log *zap.Logger) *OutboxStorage {
+	return &OutboxStorage{db: Conn}
+}
+
+// pgxpool.Pool and pgx.Tx both can execute queries
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// write event to outbox, inside the caller's transaction when tx is passed.
+// Event is delivered not earlier than at
+func insertOutbox(ctx context.Context, tx execer, eventType string, key string, payload any, at time.Time) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO outbox (event_type, idempotency_key, payload, next_attempt_at) VALUES ($1, $2, $3, $4) ON CONFLICT (idempotency_key) DO NOTHING`
+	_, err = tx.Exec(ctx, query, eventType, key, body, at)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// take due messages and hide them from other dispatchers for the lease time
+func (d *OutboxStorage) ClaimDue(limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	query := `UPDATE outbox SET next_attempt_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM outbox WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, idempotency_key, payload, status, attempts, COALESCE(last_error, ''), next_attempt_at, created_at, updated_at`
+	rows, err := d.db.Query(context.Background(), query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutbox(rows)
+}
+
+// mark message as delivered (or skipped when there is nobody to deliver to)
+func (d *OutboxStorage) MarkSent(id uint, status string) error {
+	query := `UPDATE outbox SET status = $1, attempts = attempts + 1, last_error = NULL, updated_at = NOW() WHERE id = $2`
+	_, err := d.db.Exec(context.Background(), query, status, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// register failed attempt, move message to dead letters when attempts are exhausted
+func (d *OutboxStorage) MarkFailed(id uint, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := models.OutboxPending
+	if dead {
+		status = models.OutboxDead
+	}
+
+	query := `UPDATE outbox SET status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = $3, updated_at = NOW() WHERE id = $4`
+	_, err := d.db.Exec(context.Background(), query, status, lastError, nextAttemptAt, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// get outbox messages, filtered by status if given
+func (d *OutboxStorage) GetOutbox(status string) ([]models.OutboxMessage, error) {
+	query := `SELECT id, event_type, idempotency_key, payload, status, attempts, COALESCE(last_error, ''), next_attempt_at, created_at, updated_at
+		FROM outbox WHERE ($1 = '' OR status = $1) ORDER BY id DESC LIMIT 500`
+	rows, err := d.db.Query(context.Background(), query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutbox(rows)
+}
+
+// put message back to the queue with fresh attempts counter
+func (d *OutboxStorage) ReplayMessage(id uint) (*models.OutboxMessage, error) {
+	query := `UPDATE outbox SET status = 'pending', attempts = 0, last_error = NULL, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, event_type, idempotency_key, payload, status, attempts, COALESCE(last_error, ''), next_attempt_at, created_at, updated_at`
+	rows, err := d.db.Query(context.Background(), query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanOutbox(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	return &messages[0], nil
+}
+
+// get chat_id of user, nil if user has no linked chat or it is inactive
+func (d *OutboxStorage) GetChatIDByUser(userID uint) (*int64, error) {
+	var chatID *int64
+	query := `SELECT chat_id FROM users WHERE id=$1 AND tg_active`
+	err := d.db.QueryRow(context.Background(), query, userID).Scan(&chatID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return chatID, nil
+}
+
+// group chat linked to board, nil if there is none
+func (d *OutboxStorage) GetChatIDByBoard(boardID uint) (*int64, error) {
+	var chatID *int64
+	query := `SELECT chat_id FROM boards WHERE id=$1`
+	err := d.db.QueryRow(context.Background(), query, boardID).Scan(&chatID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return chatID, nil
+}
+
+// stop telegram notifications of user till next /start, chat is checked in case user linked another one
+func (d *OutboxStorage) DeactivateTelegram(userID uint, chatID int64) error {
+	query := `UPDATE users SET tg_active = FALSE, tg_inactive_at = NOW() WHERE id = $1 AND chat_id = $2 AND tg_active`
+	_, err := d.db.Exec(context.Background(), query, userID, chatID)
+
+	return err
+}
+
+// unlink group chat which bot can't write to anymore
+func (d *OutboxStorage) UnlinkBoardChat(boardID uint, chatID int64) error {
+	query := `UPDATE boards SET chat_id = NULL, updated_at = NOW() WHERE id = $1 AND chat_id = $2`
+	_, err := d.db.Exec(context.Background(), query, boardID, chatID)
+
+	return err
+}
+
+// settings of user who receives notification
+func (d *OutboxStorage) GetNotificationSettings(userID uint) (*models.NotificationSettings, error) {
+	return getNotificationSettings(d.db, userID)
+}
+
+func (d *OutboxStorage) HoldNotification(userID uint, text string, key string) error {
+	return holdNotification(d.db, userID, text, key)
+}
+
+// add message to outbox, e.g. delivery of notification through one channel
+func (d *OutboxStorage) Enqueue(eventType string, key string, payload any) error {
+	return insertOutbox(context.Background(), d.db, eventType, key, payload, time.Now())
+}
+
+func scanOutbox(rows pgx.Rows) ([]models.OutboxMessage, error) {
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var m models.OutboxMessage
+		err := rows.Scan(&m.ID, &m.EventType, &m.IdempotencyKey, &m.Payload, &m.Status, &m.Attempts, &m.LastError, &m.NextAttemptAt, &m.CreatedAt, &m.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -14,6 +15,7 @@ type StatusesStorage struct {
 
 type StatusesStorager interface {
 	SetStatus(body dto.PostStatusDto) error
+	GetAllStatuses() ([]models.Status, error)
 	DeleteStatus(id uint) error
 }
 
@@ -32,6 +34,35 @@ func (d *StatusesStorage) SetStatus(body dto.PostStatusDto) error {
 	return nil
 }
 
+// get all statuses
+func (d *StatusesStorage) GetAllStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+func getStatuses(db *pgxpool.Pool) ([]models.Status, error) {
+	query := `SELECT id, type FROM statuses ORDER BY id`
+	rows, err := db.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []models.Status
+	for rows.Next() {
+		var status models.Status
+		if err := rows.Scan(&status.ID, &status.Type); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
 // delete status
 func (d *StatusesStorage) DeleteStatus(id uint) error {
 	query := `DELETE FROM statuses WHERE id = $1`
@@ -13,6 +13,23 @@ type Storage struct {
 	TasksStorage    TasksStorage
 	StatusesStorage StatusesStorage
 	UserStorage     UserStorage
+	OutboxStorage   OutboxStorage
+
+	NotificationsStorage NotificationsStorage
+	WebhooksStorage      WebhooksStorage
+	EventsStorage        EventsStorage
+	TimeStorage          TimeStorage
+	AnalyticsStorage     AnalyticsStorage
+	FieldsStorage        FieldsStorage
+	LabelsStorage        LabelsStorage
+	TemplatesStorage     TemplatesStorage
+	TransfersStorage     TransfersStorage
+	BulkStorage          BulkStorage
+	ExportStorage        ExportStorage
+	ImportStorage        ImportStorage
+	CalendarStorage      CalendarStorage
+	AttachmentsStorage   AttachmentsStorage
+	NoncesStorage        *NoncesStorage
 }
 
 func New(Conn *pgxpool.Pool, log *zap.Logger) *Storage {
@@ -21,6 +38,23 @@ func New(Conn *pgxpool.Pool, log *zap.Logger) *Storage {
 		TasksStorage:    *NewTasksStore(Conn, log),
 		StatusesStorage: *NewStatusesStore(Conn, log),
 		UserStorage:     *NewUserStore(Conn, log),
+		OutboxStorage:   *NewOutboxStore(Conn, log),
+
+		NotificationsStorage: *NewNotificationsStore(Conn, log),
+		WebhooksStorage:      *NewWebhooksStore(Conn, log),
+		EventsStorage:        *NewEventsStore(Conn, log),
+		TimeStorage:          *NewTimeStore(Conn, log),
+		AnalyticsStorage:     *NewAnalyticsStore(Conn, log),
+		FieldsStorage:        *NewFieldsStore(Conn, log),
+		LabelsStorage:        *NewLabelsStore(Conn, log),
+		TemplatesStorage:     *NewTemplatesStore(Conn, log),
+		TransfersStorage:     *NewTransfersStore(Conn, log),
+		BulkStorage:          *NewBulkStore(Conn, log),
+		ExportStorage:        *NewExportStore(Conn, log),
+		ImportStorage:        *NewImportStore(Conn, log),
+		CalendarStorage:      *NewCalendarStore(Conn, log),
+		AttachmentsStorage:   *NewAttachmentsStore(Conn, log),
+		NoncesStorage:        NewNoncesStore(Conn, log),
 	}
 }
 
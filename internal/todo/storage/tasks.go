@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
 
@@ -17,15 +18,55 @@ type TasksStorage struct {
 }
 
 type TasksStorager interface {
-	SetTask(body dto.PostTaskDto) (*models.Task, error)
+	SetTask(body dto.PostTaskDto, authorID uint) (*models.Task, error)
 	GetTask(id uint) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
-	UpdateTask(body dto.PostTaskDto) (*models.Task, error)
-	DeleteTask(id uint) error
-	GetChatID(task *models.Task) (*int64, error)
-	GetMyTasks(tgName string, status int) ([]models.Task, *int64, error)
+	GetAllTasks(userID uint, filter dto.TaskFilterDto) ([]models.Task, error)
+	UpdateTask(body dto.PostTaskDto, id uint, actorID uint) (*models.Task, error)
+	UpdateTaskStatus(id uint, statusID uint, actorID uint) (*models.Task, error)
+	AddComment(taskID uint, userID uint, text string) (*models.Comment, error)
+	GetComments(taskID uint) ([]models.Comment, error)
+	SnoozeTask(task *models.Task, userID uint, at time.Time) error
+	DeleteTask(id uint, actorID uint) error
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetMyTasks(userID uint, status int) ([]models.Task, error)
+	GetTgUser(tgUserID int64) (*models.TgUser, error)
 	ChangeEndedTasksStatus() error
 	GetAllUsers() ([]models.TgUser, error)
+	QueueDueReminders(before time.Time) error
+	QueueDigest(event dto.DigestEventDto) error
+	GetNotificationSettings(userID uint) (*models.NotificationSettings, error)
+	GetWeeklyStats(userID uint, since time.Time) ([]models.BoardWeekStats, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardField(id uint) (*models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetStatuses() ([]models.Status, error)
+}
+
+// columns for scanTask, nullable ones are replaced with zero values
+const taskColumns = `t.id, t.title, COALESCE(t.description, ''), COALESCE(t.board_id, 0), COALESCE(t.status_id, 0), COALESCE(t.user_id, 0), t.priority, t.due_at, t.estimate::float8, t.created_at, t.updated_at,
+	(SELECT COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(e.ended_at, NOW()) - e.started_at)), 0)::bigint FROM time_entries e WHERE e.task_id = t.id),
+	t.custom_fields, ARRAY(SELECT tl.label_id FROM task_labels tl WHERE tl.task_id = t.id ORDER BY tl.label_id)`
+
+func scanTask(row pgx.Row, task *models.Task) error {
+	return row.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.Priority, &task.DueAt, &task.Estimate, &task.CreatedAt, &task.UpdatedAt,
+		&task.TrackedSeconds, &task.CustomFields, &task.Labels)
+}
+
+func scanTasks(rows pgx.Rows) ([]models.Task, error) {
+	var tasks []models.Task
+	for rows.Next() {
+		var task models.Task
+		if err := scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
 }
 
 func NewTasksStore(Conn *pgxpool.Pool, log *zap.Logger) *TasksStorage {
@@ -33,7 +74,7 @@ func NewTasksStore(Conn *pgxpool.Pool, log *zap.Logger) *TasksStorage {
 }
 
 // set task
-func (d *TasksStorage) SetTask(body dto.PostTaskDto) (*models.Task, error) {
+func (d *TasksStorage) SetTask(body dto.PostTaskDto, authorID uint) (*models.Task, error) {
 	userId, err := strconv.ParseUint(body.UserId, 10, 32)
 	if err != nil {
 		return nil, err
@@ -44,13 +85,52 @@ func (d *TasksStorage) SetTask(body dto.PostTaskDto) (*models.Task, error) {
 		return nil, err
 	}
 
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	var id uint
-	query := `INSERT INTO tasks (title, description, board_id, status_id, user_id) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	err = d.db.QueryRow(context.Background(), query, body.Title, body.Description, boardId, 1, userId).Scan(&id)
+	query := `INSERT INTO tasks (title, description, board_id, status_id, user_id, priority, due_at, estimate, custom_fields) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err = tx.QueryRow(ctx, query, body.Title, body.Description, boardId, models.StatusInProcess, userId, body.Priority, body.DueAt, body.Estimate, customFields(body.Fields)).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setTaskLabels(ctx, tx, id, body.Labels); err != nil {
+		return nil, err
+	}
+
+	if err := recordStatusChange(ctx, tx, id, 0, authorID); err != nil {
+		return nil, err
+	}
+
+	// notification is written in the same transaction as the task
+	event := dto.TaskEventDto{
+		TaskId:      id,
+		UserId:      uint(userId),
+		ActorId:     authorID,
+		BoardId:     uint(boardId),
+		Title:       body.Title,
+		Description: body.Description,
+		StatusId:    1,
+	}
+	err = insertOutbox(ctx, tx, models.EventTaskCreated, fmt.Sprintf("%s:%d", models.EventTaskCreated, id), event, time.Now())
 	if err != nil {
 		return nil, err
 	}
 
+	if err := queueTaskEvent(ctx, tx, id, authorID, models.BoardEventTaskCreated); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	taskRet, err := d.GetTask(uint(id))
 	if err != nil {
 		return nil, err
@@ -61,11 +141,16 @@ func (d *TasksStorage) SetTask(body dto.PostTaskDto) (*models.Task, error) {
 
 // get task
 func (d *TasksStorage) GetTask(id uint) (*models.Task, error) {
-	query := `SELECT * FROM tasks WHERE id = $1`
-	row := d.db.QueryRow(context.Background(), query, id)
+	return getTask(d.db, id)
+}
+
+// get task, nil if there is none
+func getTask(db *pgxpool.Pool, id uint) (*models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.id = $1`
+	row := db.QueryRow(context.Background(), query, id)
 
 	var task models.Task
-	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.CreatedAt, &task.UpdatedAt)
+	err := scanTask(row, &task)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -76,29 +161,35 @@ func (d *TasksStorage) GetTask(id uint) (*models.Task, error) {
 	return &task, nil
 }
 
-// get all tasks
-func (d *TasksStorage) GetAllTasks() ([]models.Task, error) {
-	query := `SELECT id, title, description, board_id, status_id, user_id, created_at, updated_at FROM tasks ORDER BY updated_at`
-	rows, err := d.db.Query(context.Background(), query)
+// get all tasks from boards of user, conditions on custom fields are joined with AND
+func (d *TasksStorage) GetAllTasks(userID uint, filter dto.TaskFilterDto) ([]models.Task, error) {
+	args := []any{userID, filter.BoardId}
+	var conditions string
+	for _, field := range filter.Fields {
+		args = append(args, strconv.FormatUint(uint64(field.FieldId), 10), field.Value)
+		key, value := len(args)-1, len(args)
+		if field.Contains {
+			conditions += fmt.Sprintf(` AND strpos(lower(t.custom_fields->>$%d::text), lower($%d::text)) > 0`, key, value)
+		} else {
+			conditions += fmt.Sprintf(` AND t.custom_fields @> jsonb_build_object($%d::text, $%d::jsonb)`, key, value)
+		}
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t
+		JOIN boards_users bu ON bu.board_id = t.board_id AND bu.user_id = $1
+		WHERE ($2 = 0 OR t.board_id = $2)` + conditions + `
+		ORDER BY t.updated_at`
+	rows, err := d.db.Query(context.Background(), query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.CreatedAt, &task.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		tasks = append(tasks, task)
-	}
-	return tasks, nil
+	return scanTasks(rows)
 }
 
-// update task
-func (d *TasksStorage) UpdateTask(body dto.PostTaskDto, id uint) (*models.Task, error) {
+// update task, status change and new assignee are notified
+func (d *TasksStorage) UpdateTask(body dto.PostTaskDto, id uint, actorID uint) (*models.Task, error) {
 	userId, err := strconv.ParseUint(body.UserId, 10, 32)
 	if err != nil {
 		return nil, err
@@ -109,12 +200,70 @@ func (d *TasksStorage) UpdateTask(body dto.PostTaskDto, id uint) (*models.Task,
 		return nil, err
 	}
 
-	query := `UPDATE tasks SET title=$1, description=$2, board_id=$3, status_id=$4, user_id=$5, updated_at=NOW() WHERE id=$6`
-	_, err = d.db.Exec(context.Background(), query, body.Title, body.Description, boardId, body.StatusId, userId, id)
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var oldStatus, oldUser, oldBoard uint
+	query := `SELECT COALESCE(status_id, 0), COALESCE(user_id, 0), COALESCE(board_id, 0) FROM tasks WHERE id=$1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, id).Scan(&oldStatus, &oldUser, &oldBoard); err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE tasks SET title=$1, description=$2, board_id=$3, status_id=$4, user_id=$5, priority=$6, due_at=$7, estimate=$8, custom_fields=$9, updated_at=NOW() WHERE id=$10`
+	_, err = tx.Exec(ctx, query, body.Title, body.Description, boardId, body.StatusId, userId, body.Priority, body.DueAt, body.Estimate, customFields(body.Fields), id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := setTaskLabels(ctx, tx, id, body.Labels); err != nil {
+		return nil, err
+	}
+
+	if oldStatus != body.StatusId {
+		if err := recordStatusChange(ctx, tx, id, oldStatus, actorID); err != nil {
+			return nil, err
+		}
+
+		if err := queueStatusChanged(ctx, tx, id, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if oldUser != uint(userId) {
+		event := dto.TaskEventDto{
+			TaskId:      id,
+			UserId:      uint(userId),
+			ActorId:     actorID,
+			BoardId:     uint(boardId),
+			Title:       body.Title,
+			Description: body.Description,
+			StatusId:    body.StatusId,
+		}
+		key := fmt.Sprintf("%s:%d:%d:%d", models.EventTaskAssigned, id, userId, time.Now().UnixNano())
+		if err := insertOutbox(ctx, tx, models.EventTaskAssigned, key, event, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// webhooks of board get update and move of task separately
+	if err := queueTaskEvent(ctx, tx, id, actorID, models.BoardEventTaskUpdated); err != nil {
+		return nil, err
+	}
+
+	from := dto.TaskPlaceDto{BoardId: oldBoard, StatusId: oldStatus}
+	if err := queueTaskMoved(ctx, tx, id, actorID, from); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	taskRet, err := d.GetTask(uint(id))
 	if err != nil {
 		return nil, err
@@ -123,68 +272,224 @@ func (d *TasksStorage) UpdateTask(body dto.PostTaskDto, id uint) (*models.Task,
 	return taskRet, nil
 }
 
-// delete task
-func (d *TasksStorage) DeleteTask(id uint) error {
-	query := `DELETE FROM tasks WHERE id=$1`
-	_, err := d.db.Exec(context.Background(), query, id)
+// change only status of task
+func (d *TasksStorage) UpdateTaskStatus(id uint, statusID uint, actorID uint) (*models.Task, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var from dto.TaskPlaceDto
+	query := `SELECT COALESCE(board_id, 0), COALESCE(status_id, 0) FROM tasks WHERE id=$1 FOR UPDATE`
+	if err := tx.QueryRow(ctx, query, id).Scan(&from.BoardId, &from.StatusId); err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE tasks SET status_id=$1, updated_at=NOW() WHERE id=$2 AND status_id IS DISTINCT FROM $1`
+	tag, err := tx.Exec(ctx, query, statusID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() > 0 {
+		if err := recordStatusChange(ctx, tx, id, from.StatusId, actorID); err != nil {
+			return nil, err
+		}
+
+		if err := queueStatusChanged(ctx, tx, id, actorID); err != nil {
+			return nil, err
+		}
+
+		if err := queueTaskMoved(ctx, tx, id, actorID, from); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	taskRet, err := d.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return taskRet, nil
+}
+
+// write current status of task to its history, fromStatus is 0 for new task
+func recordStatusChange(ctx context.Context, tx pgx.Tx, id uint, fromStatus uint, actorID uint) error {
+	query := `INSERT INTO task_status_history (task_id, from_status_id, to_status_id, actor_id)
+		SELECT id, NULLIF($2, 0), status_id, NULLIF($3, 0) FROM tasks WHERE id = $1`
+	_, err := tx.Exec(ctx, query, id, fromStatus, actorID)
+
+	return err
+}
+
+// write status change event of updated task to outbox
+func queueStatusChanged(ctx context.Context, tx pgx.Tx, id uint, actorID uint) error {
+	event := dto.TaskEventDto{ActorId: actorID}
+	var updatedAt time.Time
+	query := `SELECT t.id, COALESCE(t.user_id, 0), COALESCE(t.board_id, 0), t.title, COALESCE(t.description, ''),
+		COALESCE(t.status_id, 0), COALESCE(s.type, ''), t.updated_at
+		FROM tasks t LEFT JOIN statuses s ON s.id = t.status_id WHERE t.id = $1`
+	err := tx.QueryRow(ctx, query, id).Scan(&event.TaskId, &event.UserId, &event.BoardId, &event.Title, &event.Description,
+		&event.StatusId, &event.Status, &updatedAt)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	key := fmt.Sprintf("%s:%d:%d", models.EventTaskStatusChanged, id, updatedAt.UnixNano())
+	return insertOutbox(ctx, tx, models.EventTaskStatusChanged, key, event, time.Now())
 }
 
-func (d *TasksStorage) GetChatID(task *models.Task) (*int64, error) {
-	var chatID int64
-	query := `SELECT chat_id FROM users WHERE id=$1`
-	err := d.db.QueryRow(context.Background(), query, task.UserId).Scan(&chatID)
+// add comment to task, it is posted to board chat
+func (d *TasksStorage) AddComment(taskID uint, userID uint, text string) (*models.Comment, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("username not found")
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	comment := models.Comment{TaskId: taskID, UserId: userID, Text: text}
+	query := `INSERT INTO task_comments (task_id, user_id, text) VALUES ($1, $2, $3) RETURNING id, created_at`
+	err = tx.QueryRow(ctx, query, taskID, userID, text).Scan(&comment.ID, &comment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	event := dto.CommentEventDto{CommentId: comment.ID, TaskId: taskID, AuthorId: userID, Text: text}
+	query = `SELECT COALESCE(t.board_id, 0), COALESCE(t.user_id, 0), t.title, COALESCE(NULLIF(u.tg_name, ''), u.username)
+		FROM tasks t, users u WHERE t.id = $1 AND u.id = $2`
+	err = tx.QueryRow(ctx, query, taskID, userID).Scan(&event.BoardId, &event.UserId, &event.Title, &event.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s:%d", models.EventCommentCreated, comment.ID)
+	if err := insertOutbox(ctx, tx, models.EventCommentCreated, key, event, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// get comments of task, oldest first
+func (d *TasksStorage) GetComments(taskID uint) ([]models.Comment, error) {
+	query := `SELECT id, task_id, COALESCE(user_id, 0), text, created_at FROM task_comments WHERE task_id = $1 ORDER BY id`
+	rows, err := d.db.Query(context.Background(), query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var comment models.Comment
+		if err := rows.Scan(&comment.ID, &comment.TaskId, &comment.UserId, &comment.Text, &comment.CreatedAt); err != nil {
+			return nil, err
 		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return &chatID, err
+	return comments, nil
 }
 
-func (d *TasksStorage) GetMyTasks(tgName string, status int) ([]models.Task, *int64, error) {
-	var id, chatID uint
+// delete task, webhooks get its last state
+func (d *TasksStorage) DeleteTask(id uint, actorID uint) error {
+	ctx := context.Background()
 
-	query := `SELECT id, chat_id FROM users WHERE tg_name=$1`
-	err := d.db.QueryRow(context.Background(), query, tgName).Scan(&id, &chatID)
-	fmt.Println("chatID from DB:", chatID)
+	tx, err := d.db.Begin(ctx)
 	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := deleteTask(ctx, tx, id, actorID); err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil, fmt.Errorf("user not found")
+			return nil
 		}
-		return nil, nil, err
+		return err
 	}
 
-	query = `SELECT * FROM tasks WHERE user_id=$1 and status_id=$2 ORDER BY updated_at`
-	rows, err := d.db.Query(context.Background(), query, id, status)
+	return tx.Commit(ctx)
+}
+
+// delete task, board gets task.deleted with its last state
+func deleteTask(ctx context.Context, tx pgx.Tx, id uint, actorID uint) error {
+	if err := queueTaskEvent(ctx, tx, id, actorID, models.BoardEventTaskDeleted); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM tasks WHERE id=$1`
+	_, err := tx.Exec(ctx, query, id)
+
+	return err
+}
+
+// queue reminder about task for user
+func (d *TasksStorage) SnoozeTask(task *models.Task, userID uint, at time.Time) error {
+	event := dto.TaskEventDto{
+		TaskId:      task.ID,
+		UserId:      userID,
+		BoardId:     task.BoardId,
+		Title:       task.Title,
+		Description: task.Description,
+		StatusId:    task.StatusId,
+	}
+
+	key := fmt.Sprintf("%s:%d:%d:%d", models.EventTaskReminder, task.ID, userID, at.Unix())
+	return insertOutbox(context.Background(), d.db, models.EventTaskReminder, key, event, at)
+}
+
+// check user is added to board
+func (d *TasksStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func (d *TasksStorage) GetMyTasks(userID uint, status int) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.user_id=$1 and t.status_id=$2 ORDER BY t.updated_at`
+	rows, err := d.db.Query(context.Background(), query, userID, status)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.CreatedAt, &task.UpdatedAt)
-		if err != nil {
-			return nil, nil, err
+	return scanTasks(rows)
+}
+
+// get user linked to telegram account
+func (d *TasksStorage) GetTgUser(tgUserID int64) (*models.TgUser, error) {
+	var user models.TgUser
+	query := `SELECT id, COALESCE(tg_name, ''), chat_id FROM users WHERE tg_user_id=$1`
+	err := d.db.QueryRow(context.Background(), query, tgUserID).Scan(&user.ID, &user.TgName, &user.ChatID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
 		}
-		tasks = append(tasks, task)
+		return nil, err
 	}
 
-	intChatID := int64(chatID)
-
-	return tasks, &intChatID, nil
+	return &user, nil
 }
 
+// archive done tasks, history gets the change too
 func (d *TasksStorage) ChangeEndedTasksStatus() error {
-	query := `UPDATE tasks SET status_id = 3 WHERE status_id = 2`
+	query := `WITH archived AS (UPDATE tasks SET status_id = 3 WHERE status_id = 2 RETURNING id)
+		INSERT INTO task_status_history (task_id, from_status_id, to_status_id) SELECT id, 2, 3 FROM archived`
 	_, err := d.db.Exec(context.Background(), query)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении статуса задач: %w", err)
@@ -194,7 +499,7 @@ func (d *TasksStorage) ChangeEndedTasksStatus() error {
 }
 
 func (d *TasksStorage) GetAllUsers() ([]models.TgUser, error) {
-	query := `SELECT id, tg_name, chat_id FROM users`
+	query := `SELECT id, COALESCE(tg_name, ''), COALESCE(chat_id, 0) FROM users`
 	rows, err := d.db.Query(context.Background(), query)
 	if err != nil {
 		return nil, err
@@ -217,3 +522,120 @@ func (d *TasksStorage) GetAllUsers() ([]models.TgUser, error) {
 
 	return users, nil
 }
+
+// queue reminders about unfinished tasks which are due before given time.
+// Every due date is reminded once, idempotency key includes it
+func (d *TasksStorage) QueueDueReminders(before time.Time) error {
+	ctx := context.Background()
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t
+		WHERE t.due_at IS NOT NULL AND t.due_at > NOW() AND t.due_at <= $1
+			AND t.user_id IS NOT NULL AND t.status_id NOT IN (2, 3)`
+	rows, err := d.db.Query(ctx, query, before)
+	if err != nil {
+		return err
+	}
+	tasks, err := scanTasks(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		event := dto.TaskEventDto{
+			TaskId:      task.ID,
+			UserId:      task.UserId,
+			BoardId:     task.BoardId,
+			Title:       task.Title,
+			Description: task.Description,
+			StatusId:    task.StatusId,
+		}
+
+		key := fmt.Sprintf("%s:%d:%d", models.EventTaskDue, task.ID, task.DueAt.Unix())
+		if err := insertOutbox(ctx, d.db, models.EventTaskDue, key, event, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queue daily digest of user, it is sent once a day
+func (d *TasksStorage) QueueDigest(event dto.DigestEventDto) error {
+	key := fmt.Sprintf("%s:%d:%s", models.EventDigest, event.UserId, time.Now().Format("2006-01-02"))
+	return insertOutbox(context.Background(), d.db, models.EventDigest, key, event, time.Now())
+}
+
+// settings of user, digest includes weekly numbers when user turned them on
+func (d *TasksStorage) GetNotificationSettings(userID uint) (*models.NotificationSettings, error) {
+	return getNotificationSettings(d.db, userID)
+}
+
+// get numbers of boards of user since given time. Task is completed when it got done or archived
+// from another status, tasks which were reopened since then are not counted
+func (d *TasksStorage) GetWeeklyStats(userID uint, since time.Time) ([]models.BoardWeekStats, error) {
+	query := `SELECT b.id, b.name, b.estimate_unit,
+			(SELECT COUNT(*) FROM tasks n WHERE n.board_id = b.id AND n.created_at >= $2),
+			COUNT(c.id), COALESCE(SUM(c.estimate), 0)::float8,
+			COALESCE(AVG(EXTRACT(EPOCH FROM c.completed_at - c.created_at)) / 3600, 0)::float8
+		FROM boards b
+		JOIN boards_users bu ON bu.board_id = b.id AND bu.user_id = $1
+		LEFT JOIN (
+			SELECT t.id, t.board_id, t.estimate, t.created_at, MAX(h.changed_at) AS completed_at
+			FROM tasks t JOIN task_status_history h ON h.task_id = t.id
+			WHERE t.board_id IN (SELECT board_id FROM boards_users WHERE user_id = $1)
+				AND t.status_id IN (2, 3) AND h.to_status_id IN (2, 3)
+				AND (h.from_status_id IS NULL OR h.from_status_id NOT IN (2, 3))
+			GROUP BY t.id
+		) c ON c.board_id = b.id AND c.completed_at >= $2
+		GROUP BY b.id, b.name, b.estimate_unit
+		ORDER BY b.name`
+	rows, err := d.db.Query(context.Background(), query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []models.BoardWeekStats
+	for rows.Next() {
+		var s models.BoardWeekStats
+		if err := rows.Scan(&s.BoardId, &s.BoardName, &s.EstimateUnit, &s.Created, &s.Completed, &s.Estimate, &s.LeadTimeHours); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// fields of board to validate values of its tasks
+func (d *TasksStorage) GetBoardFields(boardID uint) ([]models.BoardField, error) {
+	return getBoardFields(d.db, boardID)
+}
+
+// field for filter of tasks, nil if there is none
+func (d *TasksStorage) GetBoardField(id uint) (*models.BoardField, error) {
+	return getBoardField(d.db, id)
+}
+
+// labels of board to validate labels of its tasks
+func (d *TasksStorage) GetBoardLabels(boardID uint) ([]models.BoardLabel, error) {
+	return getBoardLabels(d.db, boardID)
+}
+
+func (d *TasksStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+// values of custom fields are never null in database
+func customFields(values map[string]any) map[string]any {
+	if values == nil {
+		return map[string]any{}
+	}
+
+	return values
+}
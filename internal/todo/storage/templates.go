@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type TemplatesStorage struct {
+	db *pgxpool.Pool
+}
+
+type TemplatesStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetBoardTasks(boardID uint) ([]models.Task, error)
+	GetStatuses() ([]models.Status, error)
+	CreateBoardTemplate(tpl models.BoardTemplate) (*models.BoardTemplate, error)
+	GetBoardTemplates(ownerID uint) ([]models.BoardTemplate, error)
+	GetBoardTemplate(id uint) (*models.BoardTemplate, error)
+	DeleteBoardTemplate(id uint) error
+	CreateBoardFromTemplate(tpl *models.BoardTemplate, name string, userID uint) (*models.Board, error)
+	CreateTaskTemplate(tpl models.TaskTemplate) (*models.TaskTemplate, error)
+	GetTaskTemplates(boardID uint) ([]models.TaskTemplate, error)
+	GetTaskTemplate(id uint) (*models.TaskTemplate, error)
+	FindTaskTemplates(userID uint, name string, boardID uint) ([]models.TaskTemplate, error)
+	DeleteTaskTemplate(id uint) error
+}
+
+// columns for scanBoardTemplate
+const boardTemplateColumns = `id, owner_id, name, estimate_unit, statuses, fields, labels, tasks, created_at`
+
+func scanBoardTemplate(row pgx.Row, tpl *models.BoardTemplate) error {
+	return row.Scan(&tpl.ID, &tpl.OwnerId, &tpl.Name, &tpl.EstimateUnit, &tpl.Statuses, &tpl.Fields, &tpl.Labels, &tpl.Tasks, &tpl.CreatedAt)
+}
+
+// columns for scanTaskTemplate
+const taskTemplateColumns = `tt.id, tt.board_id, COALESCE(tt.author_id, 0), tt.name, tt.title, tt.description, tt.priority, tt.estimate::float8,
+	tt.due_in_minutes, tt.custom_fields, tt.labels, tt.created_at`
+
+func scanTaskTemplate(row pgx.Row, tpl *models.TaskTemplate) error {
+	return row.Scan(&tpl.ID, &tpl.BoardId, &tpl.AuthorId, &tpl.Name, &tpl.Title, &tpl.Description, &tpl.Priority, &tpl.Estimate,
+		&tpl.DueInMinutes, &tpl.CustomFields, &tpl.Labels, &tpl.CreatedAt)
+}
+
+func scanTaskTemplates(rows pgx.Rows) ([]models.TaskTemplate, error) {
+	var templates []models.TaskTemplate
+	for rows.Next() {
+		var tpl models.TaskTemplate
+		if err := scanTaskTemplate(rows, &tpl); err != nil {
+			return nil, err
+		}
+		templates = append(templates, tpl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func NewTemplatesStore(Conn *pgxpool.Pool, log *zap.Logger) *TemplatesStorage {
+	return &TemplatesStorage{db: Conn}
+}
+
+// board to save it as template or to check its owner
+func (d *TemplatesStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *TemplatesStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// fields of board for template
+func (d *TemplatesStorage) GetBoardFields(boardID uint) ([]models.BoardField, error) {
+	return getBoardFields(d.db, boardID)
+}
+
+// labels of board for template
+func (d *TemplatesStorage) GetBoardLabels(boardID uint) ([]models.BoardLabel, error) {
+	return getBoardLabels(d.db, boardID)
+}
+
+// all tasks of board in order of creation
+func (d *TemplatesStorage) GetBoardTasks(boardID uint) ([]models.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.board_id = $1 ORDER BY t.id`
+	rows, err := d.db.Query(context.Background(), query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+// statuses to save their names in template
+func (d *TemplatesStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+// save snapshot of board
+func (d *TemplatesStorage) CreateBoardTemplate(tpl models.BoardTemplate) (*models.BoardTemplate, error) {
+	query := `INSERT INTO board_templates (owner_id, name, estimate_unit, statuses, fields, labels, tasks) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + boardTemplateColumns
+	row := d.db.QueryRow(context.Background(), query, tpl.OwnerId, tpl.Name, tpl.EstimateUnit, tpl.Statuses, tpl.Fields, tpl.Labels, tpl.Tasks)
+
+	var created models.BoardTemplate
+	if err := scanBoardTemplate(row, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// get board templates of user
+func (d *TemplatesStorage) GetBoardTemplates(ownerID uint) ([]models.BoardTemplate, error) {
+	query := `SELECT ` + boardTemplateColumns + ` FROM board_templates WHERE owner_id = $1 ORDER BY name`
+	rows, err := d.db.Query(context.Background(), query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.BoardTemplate
+	for rows.Next() {
+		var tpl models.BoardTemplate
+		if err := scanBoardTemplate(rows, &tpl); err != nil {
+			return nil, err
+		}
+		templates = append(templates, tpl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// get board template, nil if there is none
+func (d *TemplatesStorage) GetBoardTemplate(id uint) (*models.BoardTemplate, error) {
+	query := `SELECT ` + boardTemplateColumns + ` FROM board_templates WHERE id = $1`
+
+	var tpl models.BoardTemplate
+	if err := scanBoardTemplate(d.db.QueryRow(context.Background(), query, id), &tpl); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &tpl, nil
+}
+
+func (d *TemplatesStorage) DeleteBoardTemplate(id uint) error {
+	_, err := d.db.Exec(context.Background(), `DELETE FROM board_templates WHERE id = $1`, id)
+	return err
+}
+
+// create board with statuses, fields, labels and tasks of template in one transaction, user becomes owner of board
+// and assignee of its tasks. Tasks aren't notified, board has no other members yet
+func (d *TemplatesStorage) CreateBoardFromTemplate(tpl *models.BoardTemplate, name string, userID uint) (*models.Board, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var boardID uint
+	query := `INSERT INTO boards (name, owner_id, estimate_unit) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRow(ctx, query, name, userID, tpl.EstimateUnit).Scan(&boardID); err != nil {
+		return nil, err
+	}
+
+	query = `INSERT INTO boards_users (user_id, board_id) VALUES ($1, $2)`
+	if _, err := tx.Exec(ctx, query, userID, boardID); err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]uint, len(tpl.Statuses))
+	for _, status := range tpl.Statuses {
+		id, err := ensureStatus(ctx, tx, status)
+		if err != nil {
+			return nil, err
+		}
+		statuses[status] = id
+	}
+
+	// values of tasks are kept by field name, board gets new ids of fields
+	fields := make(map[string]string, len(tpl.Fields))
+	for _, field := range tpl.Fields {
+		var id uint
+		query = `INSERT INTO board_fields (board_id, name, type, options, position) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		if err := tx.QueryRow(ctx, query, boardID, field.Name, field.Type, field.Options, field.Position).Scan(&id); err != nil {
+			return nil, err
+		}
+		fields[field.Name] = strconv.FormatUint(uint64(id), 10)
+	}
+
+	// labels of tasks are kept by name too
+	labels := make(map[string]uint, len(tpl.Labels))
+	for _, label := range tpl.Labels {
+		var id uint
+		query = `INSERT INTO board_labels (board_id, name, color) VALUES ($1, $2, $3) RETURNING id`
+		if err := tx.QueryRow(ctx, query, boardID, label.Name, label.Color).Scan(&id); err != nil {
+			return nil, err
+		}
+		labels[strings.ToLower(label.Name)] = id
+	}
+
+	now := time.Now()
+	for _, task := range tpl.Tasks {
+		statusID, ok := statuses[task.Status]
+		if !ok {
+			statusID = models.StatusInProcess
+		}
+
+		var dueAt *time.Time
+		if task.DueInMinutes != nil {
+			due := now.Add(time.Duration(*task.DueInMinutes) * time.Minute)
+			dueAt = &due
+		}
+
+		values := make(map[string]any, len(task.Fields))
+		for fieldName, value := range task.Fields {
+			if key, ok := fields[fieldName]; ok {
+				values[key] = value
+			}
+		}
+
+		var id uint
+		query = `INSERT INTO tasks (title, description, board_id, status_id, user_id, priority, due_at, estimate, custom_fields)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+		err := tx.QueryRow(ctx, query, task.Title, task.Description, boardID, statusID, userID, task.Priority, dueAt, task.Estimate, values).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		taskLabels := make([]uint, 0, len(task.Labels))
+		for _, name := range task.Labels {
+			if labelID, ok := labels[strings.ToLower(name)]; ok {
+				taskLabels = append(taskLabels, labelID)
+			}
+		}
+
+		if err := setTaskLabels(ctx, tx, id, taskLabels); err != nil {
+			return nil, err
+		}
+
+		if err := recordStatusChange(ctx, tx, id, 0, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return getBoard(d.db, boardID)
+}
+
+// ensureStatus returns id of status with given name, missing status is added
+func ensureStatus(ctx context.Context, tx pgx.Tx, name string) (uint, error) {
+	var id uint
+	query := `SELECT id FROM statuses WHERE lower(type) = lower($1) ORDER BY id LIMIT 1`
+	err := tx.QueryRow(ctx, query, name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != pgx.ErrNoRows {
+		return 0, err
+	}
+
+	// default statuses were inserted with explicit ids, so sequence of table can be behind them
+	if _, err := tx.Exec(ctx, `LOCK TABLE statuses IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return 0, err
+	}
+
+	query = `INSERT INTO statuses (id, type) VALUES ((SELECT COALESCE(MAX(id), 0) + 1 FROM statuses), $1) RETURNING id`
+	if err := tx.QueryRow(ctx, query, strings.TrimSpace(name)).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// add task template to board
+func (d *TemplatesStorage) CreateTaskTemplate(tpl models.TaskTemplate) (*models.TaskTemplate, error) {
+	query := `INSERT INTO task_templates AS tt (board_id, author_id, name, title, description, priority, estimate, due_in_minutes, custom_fields, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING ` + taskTemplateColumns
+	row := d.db.QueryRow(context.Background(), query, tpl.BoardId, tpl.AuthorId, tpl.Name, tpl.Title, tpl.Description, tpl.Priority, tpl.Estimate,
+		tpl.DueInMinutes, tpl.CustomFields, tpl.Labels)
+
+	var created models.TaskTemplate
+	if err := scanTaskTemplate(row, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// get task templates of board
+func (d *TemplatesStorage) GetTaskTemplates(boardID uint) ([]models.TaskTemplate, error) {
+	query := `SELECT ` + taskTemplateColumns + ` FROM task_templates tt WHERE tt.board_id = $1 ORDER BY tt.name`
+	rows, err := d.db.Query(context.Background(), query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskTemplates(rows)
+}
+
+// get task template, nil if there is none
+func (d *TemplatesStorage) GetTaskTemplate(id uint) (*models.TaskTemplate, error) {
+	query := `SELECT ` + taskTemplateColumns + ` FROM task_templates tt WHERE tt.id = $1`
+
+	var tpl models.TaskTemplate
+	if err := scanTaskTemplate(d.db.QueryRow(context.Background(), query, id), &tpl); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &tpl, nil
+}
+
+// find task templates by name on boards of user, boardID limits search by one board
+func (d *TemplatesStorage) FindTaskTemplates(userID uint, name string, boardID uint) ([]models.TaskTemplate, error) {
+	query := `SELECT ` + taskTemplateColumns + ` FROM task_templates tt
+		JOIN boards_users bu ON bu.board_id = tt.board_id AND bu.user_id = $1
+		WHERE lower(tt.name) = lower($2) AND ($3 = 0 OR tt.board_id = $3)
+		ORDER BY tt.board_id`
+	rows, err := d.db.Query(context.Background(), query, userID, name, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTaskTemplates(rows)
+}
+
+func (d *TemplatesStorage) DeleteTaskTemplate(id uint) error {
+	_, err := d.db.Exec(context.Background(), `DELETE FROM task_templates WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type TimeStorage struct {
+	db *pgxpool.Pool
+}
+
+type TimeStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	StartTimer(taskID uint, userID uint, note string) (*models.TimeEntry, *models.TimeEntry, error)
+	StopTimer(userID uint) (*models.TimeEntry, error)
+	GetRunningTimer(userID uint) (*models.TimeEntry, error)
+	AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error)
+	GetTimeEntries(taskID uint) ([]models.TimeEntry, error)
+	GetTimeEntry(id uint) (*models.TimeEntry, error)
+	DeleteTimeEntry(id uint) error
+	GetTimeReport(userID uint, from time.Time, to time.Time, timezone string, groupBy []string, boardID uint) ([]models.TimeReportRow, error)
+}
+
+// columns for scanTimeEntry
+const timeEntryColumns = `id, task_id, user_id, started_at, ended_at, note, created_at`
+
+func scanTimeEntry(row pgx.Row, entry *models.TimeEntry) error {
+	return row.Scan(&entry.ID, &entry.TaskId, &entry.UserId, &entry.StartedAt, &entry.EndedAt, &entry.Note, &entry.CreatedAt)
+}
+
+// scanTimeEntryRow returns nil when there is no row
+func scanTimeEntryRow(row pgx.Row) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := scanTimeEntry(row, &entry); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func NewTimeStore(Conn *pgxpool.Pool, log *zap.Logger) *TimeStorage {
+	return &TimeStorage{db: Conn}
+}
+
+// task to check its board
+func (d *TimeStorage) GetTask(id uint) (*models.Task, error) {
+	return getTask(d.db, id)
+}
+
+// check user is added to board
+func (d *TimeStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+// start timer of user on task, running timer of user is stopped and returned as the second value
+func (d *TimeStorage) StartTimer(taskID uint, userID uint, note string) (*models.TimeEntry, *models.TimeEntry, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// concurrent starts of the same user wait for each other instead of failing on unique index
+	if _, err := tx.Exec(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+		return nil, nil, err
+	}
+
+	query := `UPDATE time_entries SET ended_at = GREATEST(NOW(), started_at) WHERE user_id = $1 AND ended_at IS NULL RETURNING ` + timeEntryColumns
+	stopped, err := scanTimeEntryRow(tx.QueryRow(ctx, query, userID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query = `INSERT INTO time_entries (task_id, user_id, started_at, note) VALUES ($1, $2, NOW(), $3) RETURNING ` + timeEntryColumns
+	var started models.TimeEntry
+	if err := scanTimeEntry(tx.QueryRow(ctx, query, taskID, userID, note), &started); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return &started, stopped, nil
+}
+
+// stop running timer of user, nil if there is none
+func (d *TimeStorage) StopTimer(userID uint) (*models.TimeEntry, error) {
+	query := `UPDATE time_entries SET ended_at = GREATEST(NOW(), started_at) WHERE user_id = $1 AND ended_at IS NULL RETURNING ` + timeEntryColumns
+
+	return scanTimeEntryRow(d.db.QueryRow(context.Background(), query, userID))
+}
+
+// get running timer of user, nil if there is none
+func (d *TimeStorage) GetRunningTimer(userID uint) (*models.TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE user_id = $1 AND ended_at IS NULL`
+
+	return scanTimeEntryRow(d.db.QueryRow(context.Background(), query, userID))
+}
+
+// add finished time entry
+func (d *TimeStorage) AddTimeEntry(entry models.TimeEntry) (*models.TimeEntry, error) {
+	query := `INSERT INTO time_entries (task_id, user_id, started_at, ended_at, note) VALUES ($1, $2, $3, $4, $5) RETURNING ` + timeEntryColumns
+	row := d.db.QueryRow(context.Background(), query, entry.TaskId, entry.UserId, entry.StartedAt, entry.EndedAt, entry.Note)
+
+	var created models.TimeEntry
+	if err := scanTimeEntry(row, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// get time entries of task, latest first
+func (d *TimeStorage) GetTimeEntries(taskID uint) ([]models.TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE task_id = $1 ORDER BY started_at DESC, id DESC`
+	rows, err := d.db.Query(context.Background(), query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.TimeEntry
+	for rows.Next() {
+		var entry models.TimeEntry
+		if err := scanTimeEntry(rows, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// get time entry, nil if there is none
+func (d *TimeStorage) GetTimeEntry(id uint) (*models.TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE id = $1`
+
+	return scanTimeEntryRow(d.db.QueryRow(context.Background(), query, id))
+}
+
+// delete time entry, running timer is deleted without being counted
+func (d *TimeStorage) DeleteTimeEntry(id uint) error {
+	query := `DELETE FROM time_entries WHERE id = $1`
+	_, err := d.db.Exec(context.Background(), query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// get time tracked on boards of user within [from, to), grouped by given fields.
+// Entries are split by days in timezone, so an entry over midnight is counted in both days
+func (d *TimeStorage) GetTimeReport(userID uint, from time.Time, to time.Time, timezone string, groupBy []string, boardID uint) ([]models.TimeReportRow, error) {
+	userCols, boardCols, dayCol := `0, ''`, `0, ''`, `''`
+	var group []string
+
+	if slices.Contains(groupBy, models.TimeGroupUser) {
+		userCols = `e.user_id, u.username`
+		group = append(group, `e.user_id`, `u.username`)
+	}
+	if slices.Contains(groupBy, models.TimeGroupBoard) {
+		boardCols = `t.board_id, b.name`
+		group = append(group, `t.board_id`, `b.name`)
+	}
+	if slices.Contains(groupBy, models.TimeGroupDay) {
+		dayCol = `d.day`
+		group = append(group, `d.day`)
+	}
+
+	groupClause := ""
+	if len(group) > 0 {
+		groupClause = `GROUP BY ` + strings.Join(group, ", ")
+	}
+
+	seconds := `SUM(EXTRACT(EPOCH FROM LEAST(COALESCE(e.ended_at, NOW()), d.day_end, $2::timestamptz) - GREATEST(e.started_at, d.day_start, $1::timestamptz)))`
+
+	query := `SELECT ` + userCols + `, ` + boardCols + `, ` + dayCol + `, ` + seconds + `::bigint
+		FROM time_entries e
+		JOIN tasks t ON t.id = e.task_id
+		JOIN boards_users bu ON bu.board_id = t.board_id AND bu.user_id = $4
+		JOIN boards b ON b.id = t.board_id
+		JOIN users u ON u.id = e.user_id
+		CROSS JOIN LATERAL (
+			SELECT to_char(s, 'YYYY-MM-DD') AS day, s AT TIME ZONE $3::text AS day_start, (s + interval '1 day') AT TIME ZONE $3::text AS day_end
+			FROM generate_series(
+				date_trunc('day', GREATEST(e.started_at, $1::timestamptz) AT TIME ZONE $3::text),
+				LEAST(COALESCE(e.ended_at, NOW()), $2::timestamptz) AT TIME ZONE $3::text,
+				interval '1 day'
+			) s
+		) d
+		WHERE e.started_at < $2::timestamptz AND COALESCE(e.ended_at, NOW()) > $1::timestamptz AND ($5 = 0 OR t.board_id = $5)
+		` + groupClause + `
+		HAVING ` + seconds + ` > 0
+		ORDER BY 5, 2, 4`
+	rows, err := d.db.Query(context.Background(), query, from, to, timezone, userID, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var report []models.TimeReportRow
+	for rows.Next() {
+		var row models.TimeReportRow
+		if err := rows.Scan(&row.UserId, &row.Username, &row.BoardId, &row.BoardName, &row.Day, &row.Seconds); err != nil {
+			return nil, err
+		}
+		report = append(report, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,390 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type TransfersStorage struct {
+	db *pgxpool.Pool
+}
+
+type TransfersStorager interface {
+	GetTask(id uint) (*models.Task, error)
+	GetBoard(id uint) (*models.Board, error)
+	IsBoardMember(boardID uint, userID uint) (bool, error)
+	GetBoardFields(boardID uint) ([]models.BoardField, error)
+	GetBoardLabels(boardID uint) ([]models.BoardLabel, error)
+	GetStatuses() ([]models.Status, error)
+	MoveTask(id uint, transfer models.TaskTransfer, actorID uint) (*models.Task, error)
+	CopyTask(source *models.Task, transfer models.TaskTransfer, actorID uint) (*models.Task, error)
+	CloneBoard(board *models.Board, body dto.PostBoardCloneDto, actorID uint) (*models.Board, error)
+	GetTransfers(taskID uint) ([]models.Transfer, error)
+}
+
+func NewTransfersStore(Conn *pgxpool.Pool, log *zap.Logger) *TransfersStorage {
+	return &TransfersStorage{db: Conn}
+}
+
+func (d *TransfersStorage) GetTask(id uint) (*models.Task, error) {
+	return getTask(d.db, id)
+}
+
+func (d *TransfersStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// check user is added to board
+func (d *TransfersStorage) IsBoardMember(boardID uint, userID uint) (bool, error) {
+	return isBoardMember(d.db, boardID, userID)
+}
+
+func (d *TransfersStorage) GetBoardFields(boardID uint) ([]models.BoardField, error) {
+	return getBoardFields(d.db, boardID)
+}
+
+func (d *TransfersStorage) GetBoardLabels(boardID uint) ([]models.BoardLabel, error) {
+	return getBoardLabels(d.db, boardID)
+}
+
+func (d *TransfersStorage) GetStatuses() ([]models.Status, error) {
+	return getStatuses(d.db)
+}
+
+// move task to another board, both boards get task.moved
+func (d *TransfersStorage) MoveTask(id uint, transfer models.TaskTransfer, actorID uint) (*models.Task, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	old, err := moveTask(ctx, tx, id, transfer, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if old.StatusId != transfer.StatusId {
+		if err := queueStatusChanged(ctx, tx, id, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	// assignee who isn't a member of the new board is replaced by actor
+	if old.UserId != transfer.UserId {
+		event := dto.TaskEventDto{
+			TaskId:      id,
+			UserId:      transfer.UserId,
+			ActorId:     actorID,
+			BoardId:     transfer.BoardId,
+			Title:       old.Title,
+			Description: old.Description,
+			StatusId:    transfer.StatusId,
+		}
+		key := fmt.Sprintf("%s:%d:%d:%d", models.EventTaskAssigned, id, transfer.UserId, time.Now().UnixNano())
+		if err := insertOutbox(ctx, tx, models.EventTaskAssigned, key, event, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return getTask(d.db, id)
+}
+
+// moveTask changes board of task with history and board events, notifications are left to caller.
+// Returns task before move
+func moveTask(ctx context.Context, tx pgx.Tx, id uint, transfer models.TaskTransfer, actorID uint) (*models.Task, error) {
+	old, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE tasks SET board_id = $1, status_id = $2, user_id = $3, custom_fields = $4, updated_at = NOW() WHERE id = $5`
+	_, err = tx.Exec(ctx, query, transfer.BoardId, transfer.StatusId, transfer.UserId, customFields(transfer.CustomFields), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setTaskLabels(ctx, tx, id, transfer.Labels); err != nil {
+		return nil, err
+	}
+
+	if old.StatusId != transfer.StatusId {
+		if err := recordStatusChange(ctx, tx, id, old.StatusId, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := insertTransfer(ctx, tx, models.TransferMove, id, 0, old.BoardId, transfer.BoardId, actorID); err != nil {
+		return nil, err
+	}
+
+	from := dto.TaskPlaceDto{BoardId: old.BoardId, StatusId: old.StatusId}
+	if err := queueTaskMoved(ctx, tx, id, actorID, from); err != nil {
+		return nil, err
+	}
+
+	return old, nil
+}
+
+// copy task to board, copy is created as a new task with notifications
+func (d *TransfersStorage) CopyTask(source *models.Task, transfer models.TaskTransfer, actorID uint) (*models.Task, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	copied := *source
+	copied.BoardId = transfer.BoardId
+	copied.StatusId = transfer.StatusId
+	copied.UserId = transfer.UserId
+	copied.CustomFields = transfer.CustomFields
+	copied.Labels = transfer.Labels
+
+	id, err := copyTask(ctx, tx, &copied, transfer.Comments, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertTransfer(ctx, tx, models.TransferCopy, id, source.ID, source.BoardId, transfer.BoardId, actorID); err != nil {
+		return nil, err
+	}
+
+	event := dto.TaskEventDto{
+		TaskId:      id,
+		UserId:      transfer.UserId,
+		ActorId:     actorID,
+		BoardId:     transfer.BoardId,
+		Title:       source.Title,
+		Description: source.Description,
+		StatusId:    transfer.StatusId,
+	}
+	err = insertOutbox(ctx, tx, models.EventTaskCreated, fmt.Sprintf("%s:%d", models.EventTaskCreated, id), event, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := queueTaskEvent(ctx, tx, id, actorID, models.BoardEventTaskCreated); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return getTask(d.db, id)
+}
+
+// insert task as a new one with its labels, with comments of source task if needed
+func copyTask(ctx context.Context, tx pgx.Tx, task *models.Task, comments bool, actorID uint) (uint, error) {
+	var id uint
+	query := `INSERT INTO tasks (title, description, board_id, status_id, user_id, priority, due_at, estimate, custom_fields)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := tx.QueryRow(ctx, query, task.Title, task.Description, task.BoardId, task.StatusId, task.UserId, task.Priority,
+		task.DueAt, task.Estimate, customFields(task.CustomFields)).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := setTaskLabels(ctx, tx, id, task.Labels); err != nil {
+		return 0, err
+	}
+
+	if err := recordStatusChange(ctx, tx, id, 0, actorID); err != nil {
+		return 0, err
+	}
+
+	if comments {
+		query = `INSERT INTO task_comments (task_id, user_id, text, created_at)
+			SELECT $1, user_id, text, created_at FROM task_comments WHERE task_id = $2 ORDER BY id`
+		if _, err := tx.Exec(ctx, query, id, task.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+// clone board with fields and labels and optionally with members, tasks and comments.
+// Cloned board is new, so no notifications and events are sent
+func (d *TransfersStorage) CloneBoard(board *models.Board, body dto.PostBoardCloneDto, actorID uint) (*models.Board, error) {
+	fields, err := getBoardFields(d.db, board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := getBoardLabels(d.db, board.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var boardID uint
+	query := `INSERT INTO boards (name, owner_id, estimate_unit) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRow(ctx, query, body.Name, actorID, board.EstimateUnit).Scan(&boardID); err != nil {
+		return nil, err
+	}
+
+	query = `INSERT INTO boards_users (user_id, board_id) VALUES ($1, $2)`
+	if _, err := tx.Exec(ctx, query, actorID, boardID); err != nil {
+		return nil, err
+	}
+
+	if body.Members {
+		query = `INSERT INTO boards_users (user_id, board_id) SELECT user_id, $2 FROM boards_users WHERE board_id = $1 AND user_id <> $3`
+		if _, err := tx.Exec(ctx, query, board.ID, boardID, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	// values of tasks are kept by field id, so ids of source fields are mapped to new ones
+	keys := make(map[string]string, len(fields))
+	userFields := make(map[string]bool)
+	for _, field := range fields {
+		var id uint
+		query = `INSERT INTO board_fields (board_id, name, type, options, position) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		if err := tx.QueryRow(ctx, query, boardID, field.Name, field.Type, field.Options, field.Position).Scan(&id); err != nil {
+			return nil, err
+		}
+
+		key := strconv.FormatUint(uint64(field.ID), 10)
+		keys[key] = strconv.FormatUint(uint64(id), 10)
+		userFields[key] = field.Type == models.FieldUser
+	}
+
+	labelIDs := make(map[uint]uint, len(labels))
+	for _, label := range labels {
+		var id uint
+		query = `INSERT INTO board_labels (board_id, name, color) VALUES ($1, $2, $3) RETURNING id`
+		if err := tx.QueryRow(ctx, query, boardID, label.Name, label.Color).Scan(&id); err != nil {
+			return nil, err
+		}
+		labelIDs[label.ID] = id
+	}
+
+	if err := insertTransfer(ctx, tx, models.TransferClone, 0, 0, board.ID, boardID, actorID); err != nil {
+		return nil, err
+	}
+
+	if body.Tasks {
+		query = `SELECT ` + taskColumns + ` FROM tasks t WHERE t.board_id = $1 ORDER BY t.id`
+		rows, err := tx.Query(ctx, query, board.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks, err := scanTasks(rows)
+		rows.Close() // connection of transaction is needed for inserts
+		if err != nil {
+			return nil, err
+		}
+
+		for _, task := range tasks {
+			values := make(map[string]any, len(task.CustomFields))
+			for key, value := range task.CustomFields {
+				newKey, ok := keys[key]
+				if !ok {
+					continue
+				}
+
+				// without members only actor stays in user fields
+				if userFields[key] && !body.Members {
+					if id, ok := value.(float64); !ok || uint(id) != actorID {
+						continue
+					}
+				}
+				values[newKey] = value
+			}
+
+			copiedLabels := make([]uint, 0, len(task.Labels))
+			for _, id := range task.Labels {
+				if newID, ok := labelIDs[id]; ok {
+					copiedLabels = append(copiedLabels, newID)
+				}
+			}
+
+			copied := task
+			copied.BoardId = boardID
+			copied.CustomFields = values
+			copied.Labels = copiedLabels
+			if !body.Members {
+				copied.UserId = actorID
+			}
+
+			id, err := copyTask(ctx, tx, &copied, body.Comments, actorID)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := insertTransfer(ctx, tx, models.TransferCopy, id, task.ID, board.ID, boardID, actorID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return getBoard(d.db, boardID)
+}
+
+func insertTransfer(ctx context.Context, tx pgx.Tx, kind string, taskID uint, sourceTaskID uint, fromBoardID uint, toBoardID uint, actorID uint) error {
+	query := `INSERT INTO transfers (kind, task_id, source_task_id, from_board_id, to_board_id, actor_id)
+		VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, 0), NULLIF($6, 0))`
+	_, err := tx.Exec(ctx, query, kind, taskID, sourceTaskID, fromBoardID, toBoardID, actorID)
+
+	return err
+}
+
+// get moves and copies of task, including copies made from it
+func (d *TransfersStorage) GetTransfers(taskID uint) ([]models.Transfer, error) {
+	query := `SELECT id, kind, COALESCE(task_id, 0), COALESCE(source_task_id, 0), COALESCE(from_board_id, 0),
+		COALESCE(to_board_id, 0), COALESCE(actor_id, 0), created_at
+		FROM transfers WHERE task_id = $1 OR source_task_id = $1 ORDER BY created_at, id`
+	rows, err := d.db.Query(context.Background(), query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []models.Transfer
+	for rows.Next() {
+		var transfer models.Transfer
+		err := rows.Scan(&transfer.ID, &transfer.Kind, &transfer.TaskId, &transfer.SourceTaskId, &transfer.FromBoardId,
+			&transfer.ToBoardId, &transfer.ActorId, &transfer.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
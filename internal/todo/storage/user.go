@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
 
@@ -22,11 +23,12 @@ type UserStorager interface {
 	GetAuthUser(id uint) (*models.UserToken, error)
 	UserLogout(id uint) error
 
-	GetAllUsers() ([]models.TgUser, error)
-	GetChatID(task *models.Task) (int64, error)
-	AddChatID(tgName string, chatID int64) error
-	GetMyTasks(tgName string, status int) ([]models.Task, int64, error)
-	ChangeEndedTasksStatus() error
+	CreateLinkCode(userID uint, code string, expiresAt time.Time) error
+	LinkTelegram(code string, tgUserID int64, chatID int64, tgName string) (*uint, error)
+	UnlinkTelegram(userID uint) error
+	GetUserIDByTg(tgUserID int64) (*uint, error)
+	GetUser(id uint) (*models.User, error)
+	ActivateTelegram(userID uint, chatID int64) (bool, error)
 }
 
 func NewUserStore(Conn *pgxpool.Pool, log *zap.Logger) *UserStorage {
@@ -127,83 +129,122 @@ func (d *UserStorage) Close() error {
 	return nil
 }
 
-func (d *UserStorage) AddChatID(tgName string, chatID int64) error {
-	query := `UPDATE users SET chat_id = $1 WHERE tg_name = $2`
-	_, err := d.db.Exec(context.Background(), query, chatID, tgName)
+// save one-time code for linking telegram, previous codes of user are dropped
+func (d *UserStorage) CreateLinkCode(userID uint, code string, expiresAt time.Time) error {
+	ctx := context.Background()
+
+	tx, err := d.db.Begin(ctx)
 	if err != nil {
-		fmt.Printf("Error registering user: %v\n", err)
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	return nil
+	_, err = tx.Exec(ctx, `DELETE FROM tg_link_codes WHERE user_id = $1 OR expires_at < NOW()`, userID)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO tg_link_codes (code, user_id, expires_at) VALUES ($1, $2, $3)`
+	_, err = tx.Exec(ctx, query, code, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-func (d *UserStorage) GetMyTasks(tgName string, status int) ([]models.Task, *int64, error) {
-	var id, chatID uint
+// consume link code and bind telegram account to its user, nil if code is unknown or expired
+func (d *UserStorage) LinkTelegram(code string, tgUserID int64, chatID int64, tgName string) (*uint, error) {
+	ctx := context.Background()
 
-	query := `SELECT id, chat_id FROM users WHERE tg_name=$1`
-	err := d.db.QueryRow(context.Background(), query, tgName).Scan(&id, &chatID)
-	fmt.Println("chatID from DB:", chatID)
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uint
+	query := `DELETE FROM tg_link_codes WHERE code = $1 AND expires_at > NOW() RETURNING user_id`
+	err = tx.QueryRow(ctx, query, code).Scan(&userID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, nil, fmt.Errorf("user not found")
+			return nil, nil
 		}
-		return nil, nil, err
+		return nil, err
 	}
 
-	query = `SELECT * FROM tasks WHERE user_id=$1 and status_id=$2 ORDER BY updated_at`
-	rows, err := d.db.Query(context.Background(), query, id, status)
+	// telegram account may be linked to one user only
+	query = `UPDATE users SET tg_user_id = NULL, chat_id = NULL WHERE tg_user_id = $1 AND id <> $2`
+	_, err = tx.Exec(ctx, query, tgUserID, userID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	defer rows.Close()
 
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.BoardId, &task.StatusId, &task.UserId, &task.CreatedAt, &task.UpdatedAt)
-		if err != nil {
-			return nil, nil, err
-		}
-		tasks = append(tasks, task)
+	query = `UPDATE users SET tg_user_id = $1, chat_id = $2, tg_name = NULLIF($3, ''), tg_active = TRUE, tg_inactive_at = NULL WHERE id = $4`
+	_, err = tx.Exec(ctx, query, tgUserID, chatID, tgName, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	intChatID := int64(chatID)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 
-	return tasks, &intChatID, nil
+	return &userID, nil
 }
 
-func (d *UserStorage) GetAllUsers() ([]models.TgUser, error) {
-	query := `SELECT id, tg_name, chat_id FROM users`
-	rows, err := d.db.Query(context.Background(), query)
+// remove telegram account from user
+func (d *UserStorage) UnlinkTelegram(userID uint) error {
+	query := `UPDATE users SET tg_user_id = NULL, chat_id = NULL WHERE id = $1`
+	_, err := d.db.Exec(context.Background(), query, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	var users []models.TgUser
-	for rows.Next() {
-		var user models.TgUser
-		if err := rows.Scan(&user.ID, &user.TgName, &user.ChatID); err != nil {
-			fmt.Println("Error during scanning row:", err)
-			return nil, err
+	return nil
+}
+
+// get id of user linked to telegram account, nil if there is no such user
+func (d *UserStorage) GetUserIDByTg(tgUserID int64) (*uint, error) {
+	var id uint
+	query := `SELECT id FROM users WHERE tg_user_id = $1`
+	err := d.db.QueryRow(context.Background(), query, tgUserID).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
 		}
-		users = append(users, user)
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	return &id, nil
+}
+
+// get user with state of telegram link, nil if there is no such user
+func (d *UserStorage) GetUser(id uint) (*models.User, error) {
+	var user models.User
+	query := `SELECT id, username, COALESCE(tg_name, ''), tg_user_id, tg_active, tg_inactive_at FROM users WHERE id = $1`
+	err := d.db.QueryRow(context.Background(), query, id).Scan(&user.ID, &user.Username, &user.TgName, &user.TgUserID, &user.TgActive, &user.TgInactiveAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	return users, nil
+	return &user, nil
 }
 
-func (d *UserStorage) ChangeEndedTasksStatus() error {
-	query := `UPDATE tasks SET status_id = 3 WHERE status_id = 2`
-	_, err := d.db.Exec(context.Background(), query)
+// turn telegram link on again after /start, false if it was active
+func (d *UserStorage) ActivateTelegram(userID uint, chatID int64) (bool, error) {
+	query := `UPDATE users SET tg_active = TRUE, tg_inactive_at = NULL, chat_id = $2 WHERE id = $1 AND NOT tg_active RETURNING id`
+	var id uint
+	err := d.db.QueryRow(context.Background(), query, userID, chatID).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("ошибка при обновлении статуса задач: %w", err)
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
 	}
 
-	return nil
+	return true, nil
 }
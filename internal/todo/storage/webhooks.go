@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"time"
+	"todo/internal/todo/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type WebhooksStorage struct {
+	db *pgxpool.Pool
+}
+
+type WebhooksStorager interface {
+	GetBoard(id uint) (*models.Board, error)
+	CreateWebhook(hook models.Webhook) (*models.Webhook, error)
+	GetWebhooks(boardID uint) ([]models.Webhook, error)
+	GetWebhook(id uint) (*models.Webhook, error)
+	UpdateWebhook(hook models.Webhook) (*models.Webhook, error)
+	DeleteWebhook(id uint) error
+	GetDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error)
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	Redeliver(id uint) (*models.WebhookDelivery, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	FinishDelivery(delivery models.WebhookDelivery) error
+}
+
+// columns for scanWebhook
+const webhookColumns = `id, board_id, url, secret, events, active, created_at, updated_at`
+
+func scanWebhook(row pgx.Row, hook *models.Webhook) error {
+	return row.Scan(&hook.ID, &hook.BoardId, &hook.URL, &hook.Secret, &hook.Events, &hook.Active, &hook.CreatedAt, &hook.UpdatedAt)
+}
+
+// columns for scanDelivery
+const deliveryColumns = `id, webhook_id, guid::text, redelivery_of, event, payload, status, attempts, response_status,
+	COALESCE(response_body, ''), duration_ms, COALESCE(last_error, ''), next_attempt_at, created_at, delivered_at`
+
+func scanDeliveries(rows pgx.Rows) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookId, &d.Guid, &d.RedeliveryOf, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.ResponseStatus,
+			&d.ResponseBody, &d.DurationMs, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func NewWebhooksStore(Conn *pgxpool.Pool, log *zap.Logger) *WebhooksStorage {
+	return &WebhooksStorage{db: Conn}
+}
+
+// board of webhooks, to check its owner
+func (d *WebhooksStorage) GetBoard(id uint) (*models.Board, error) {
+	return getBoard(d.db, id)
+}
+
+// add webhook to board
+func (d *WebhooksStorage) CreateWebhook(hook models.Webhook) (*models.Webhook, error) {
+	query := `INSERT INTO board_webhooks (board_id, url, secret, events, active) VALUES ($1, $2, $3, $4, $5) RETURNING ` + webhookColumns
+	row := d.db.QueryRow(context.Background(), query, hook.BoardId, hook.URL, hook.Secret, hook.Events, hook.Active)
+
+	var created models.Webhook
+	if err := scanWebhook(row, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// get webhooks of board
+func (d *WebhooksStorage) GetWebhooks(boardID uint) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM board_webhooks WHERE board_id = $1 ORDER BY id`
+	rows, err := d.db.Query(context.Background(), query, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []models.Webhook
+	for rows.Next() {
+		var hook models.Webhook
+		if err := scanWebhook(rows, &hook); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+// get webhook, nil if there is none
+func (d *WebhooksStorage) GetWebhook(id uint) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM board_webhooks WHERE id = $1`
+
+	var hook models.Webhook
+	err := scanWebhook(d.db.QueryRow(context.Background(), query, id), &hook)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &hook, nil
+}
+
+// update webhook, empty secret keeps the current one
+func (d *WebhooksStorage) UpdateWebhook(hook models.Webhook) (*models.Webhook, error) {
+	query := `UPDATE board_webhooks SET url = $1, secret = COALESCE(NULLIF($2, ''), secret), events = $3, active = $4, updated_at = NOW()
+		WHERE id = $5 RETURNING ` + webhookColumns
+	row := d.db.QueryRow(context.Background(), query, hook.URL, hook.Secret, hook.Events, hook.Active, hook.ID)
+
+	var updated models.Webhook
+	if err := scanWebhook(row, &updated); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// delete webhook with its deliveries
+func (d *WebhooksStorage) DeleteWebhook(id uint) error {
+	query := `DELETE FROM board_webhooks WHERE id = $1`
+	_, err := d.db.Exec(context.Background(), query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// get latest deliveries of webhook
+func (d *WebhooksStorage) GetDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`
+	rows, err := d.db.Query(context.Background(), query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// get delivery, nil if there is none
+func (d *WebhooksStorage) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	rows, err := d.db.Query(context.Background(), query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	return &deliveries[0], nil
+}
+
+// queue the same payload again as a new delivery with guid of the original one
+func (d *WebhooksStorage) Redeliver(id uint) (*models.WebhookDelivery, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, guid, redelivery_of, event, payload)
+		SELECT webhook_id, guid, id, event, payload FROM webhook_deliveries WHERE id = $1
+		RETURNING ` + deliveryColumns
+	rows, err := d.db.Query(context.Background(), query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+
+	return &deliveries[0], nil
+}
+
+// take due deliveries and hide them from other dispatchers for the lease time
+func (d *WebhooksStorage) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	rows, err := d.db.Query(context.Background(), query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// save result of delivery attempt
+func (d *WebhooksStorage) FinishDelivery(delivery models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries SET status = $1, attempts = $2, response_status = $3, response_body = $4, duration_ms = $5,
+		last_error = NULLIF($6, ''), next_attempt_at = $7, delivered_at = $8
+		WHERE id = $9`
+	_, err := d.db.Exec(context.Background(), query, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.ResponseBody,
+		delivery.DurationMs, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+type AnalyticsHandler struct {
+	service AnalyticsHandlerer
+	logger  *zap.Logger
+}
+
+type AnalyticsHandlerer interface {
+	GetBoardAnalytics(boardID uint, filter dto.AnalyticsFilterDto, userID uint) (*dto.BoardAnalyticsDto, error)
+}
+
+func NewAnalyticsHandler(t AnalyticsHandlerer, logger *zap.Logger) AnalyticsHandler {
+	return AnalyticsHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get analytics of board: ?from=&to=&tz=
+func (h *AnalyticsHandler) GetBoardAnalytics(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := dto.AnalyticsFilterDto{
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Timezone: query.Get("tz"),
+	}
+
+	analytics, err := h.service.GetBoardAnalytics(boardID, filter, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(analytics)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+// form field of uploaded file
+const attachmentFormField = "file"
+
+type AttachmentsHandler struct {
+	service AttachmentsHandlerer
+	logger  *zap.Logger
+}
+
+type AttachmentsHandlerer interface {
+	GetAttachments(taskID uint, userID uint) ([]dto.AttachmentDto, error)
+	Upload(taskID uint, filename string, content io.Reader, checksum string, userID uint) (*dto.AttachmentDto, error)
+	Open(taskID uint, id uint, userID uint) (*dto.AttachmentDto, io.ReadCloser, error)
+	DeleteAttachment(taskID uint, id uint, userID uint) error
+}
+
+func NewAttachmentsHandler(t AttachmentsHandlerer, logger *zap.Logger) AttachmentsHandler {
+	return AttachmentsHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get attachments of task
+func (h *AttachmentsHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.GetAttachments(taskID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// Upload file from multipart field "file", optional X-Checksum-SHA256 header is hex sha256 of file
+func (h *AttachmentsHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	// file is streamed to service without parsing the whole form into memory
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Multipart form is expected", http.StatusBadRequest)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			http.Error(w, "Field file is required", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+			return
+		}
+
+		if part.FormName() != attachmentFormField {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.service.Upload(taskID, part.FileName(), part, r.Header.Get("X-Checksum-SHA256"), userIDFromCtx(r))
+		part.Close()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(attachment)
+		return
+	}
+}
+
+// Download file of attachment, images are shown inline
+func (h *AttachmentsHandler) Download(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := urlUint(w, r, "attachmentID")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.Open(taskID, id, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer content.Close()
+
+	// content of attachment never changes, checksum is its etag
+	etag := `"` + attachment.Sha256 + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Checksum-SHA256", attachment.Sha256)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+
+	if _, err := io.Copy(w, content); err != nil {
+		h.logger.Error("write attachment", zap.Uint("id", id), zap.Error(err))
+	}
+}
+
+// Delete attachment, allowed for who uploaded it and for owner of board
+func (h *AttachmentsHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := urlUint(w, r, "attachmentID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(taskID, id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -18,13 +18,16 @@ type BoardsHandler struct {
 }
 
 type BoardsHandlerer interface {
-	SetBoard(body dto.PostBoardDto) (*models.Board, error)
-	GetAllBoards() ([]models.Board, error)
-	GetBoard(id uint) (*models.Board, error)
-	UpdateBoard(body dto.PostBoardDto, id uint) error
-	DeleteBoard(id string) error
-
-	User2Board(body dto.PostUser2BoardDto) error
+	SetBoard(body dto.PostBoardDto, userID uint) (*models.Board, error)
+	GetAllBoards(userID uint) ([]models.Board, error)
+	GetBoard(id uint, userID uint) (*models.Board, error)
+	UpdateBoard(body dto.PostBoardDto, id uint, userID uint) error
+	DeleteBoard(id string, userID uint) error
+
+	User2Board(body dto.PostUser2BoardDto, userID uint) error
+	LinkChat(id uint, chatID int64, userID uint) error
+	UnlinkChat(id uint, userID uint) error
+	RemoveUser(id uint, memberID uint, userID uint) error
 }
 
 func NewBoardsHandler(t BoardsHandlerer, logger *zap.Logger) BoardsHandler {
@@ -46,9 +49,9 @@ func (h *BoardsHandler) SetBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	boardRet, err := h.service.SetBoard(board)
+	boardRet, err := h.service.SetBoard(board, userIDFromCtx(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -59,9 +62,9 @@ func (h *BoardsHandler) SetBoard(w http.ResponseWriter, r *http.Request) {
 
 // Get all boards
 func (h *BoardsHandler) GetAllBoards(w http.ResponseWriter, r *http.Request) {
-	boards, err := h.service.GetAllBoards()
+	boards, err := h.service.GetAllBoards(userIDFromCtx(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -80,9 +83,9 @@ func (h *BoardsHandler) GetBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	board, err := h.service.GetBoard(uint(id))
+	board, err := h.service.GetBoard(uint(id), userIDFromCtx(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	if board == nil {
@@ -115,8 +118,8 @@ func (h *BoardsHandler) UpdateBoard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.UpdateBoard(board, uint(id)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.UpdateBoard(board, uint(id), userIDFromCtx(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -128,8 +131,8 @@ func (h *BoardsHandler) UpdateBoard(w http.ResponseWriter, r *http.Request) {
 func (h *BoardsHandler) DeleteBoard(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if err := h.service.DeleteBoard(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.DeleteBoard(id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -152,11 +155,75 @@ func (h *BoardsHandler) User2Board(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.User2Board(u2b); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.User2Board(u2b, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(u2b)
 }
+
+// Link telegram group to a board, only for board owner
+func (h *BoardsHandler) LinkChat(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var chat dto.PutBoardChatDto
+	if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if chat.ChatId == 0 {
+		http.Error(w, "chat_id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.LinkChat(uint(id), chat.ChatId, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unlink telegram group from a board
+func (h *BoardsHandler) UnlinkChat(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UnlinkChat(uint(id), userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Remove user from a board, members can remove only themselves
+func (h *BoardsHandler) RemoveUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := urlUint(w, r, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveUser(id, memberID, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
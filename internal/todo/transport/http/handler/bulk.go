@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+type BulkHandler struct {
+	service BulkHandlerer
+	logger  *zap.Logger
+}
+
+type BulkHandlerer interface {
+	Apply(body dto.PostBulkDto, userID uint) (*dto.BulkResultDto, error)
+}
+
+func NewBulkHandler(t BulkHandlerer, logger *zap.Logger) BulkHandler {
+	return BulkHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Apply list of operations on tasks, atomic request which is not applied gets 400 with results
+func (h *BulkHandler) ApplyBulk(w http.ResponseWriter, r *http.Request) {
+	var body dto.PostBulkDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.service.Apply(body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if body.Atomic && res.Applied == 0 {
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
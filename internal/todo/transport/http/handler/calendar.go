@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type CalendarHandler struct {
+	service CalendarHandlerer
+	logger  *zap.Logger
+}
+
+type CalendarHandlerer interface {
+	GetLink(userID uint) (*dto.CalendarLinkDto, error)
+	RegenerateToken(userID uint) (*dto.CalendarLinkDto, error)
+	DeleteToken(userID uint) error
+	Feed(token string, filter dto.CalendarFilterDto) ([]models.CalendarTask, error)
+}
+
+func NewCalendarHandler(t CalendarHandlerer, logger *zap.Logger) CalendarHandler {
+	return CalendarHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get url of calendar feed of user
+func (h *CalendarHandler) GetLink(w http.ResponseWriter, r *http.Request) {
+	link, err := h.service.GetLink(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+// Regenerate token, old url stops working
+func (h *CalendarHandler) RegenerateToken(w http.ResponseWriter, r *http.Request) {
+	link, err := h.service.RegenerateToken(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(link)
+}
+
+// Disable calendar feed of user
+func (h *CalendarHandler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteToken(userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Feed of tasks with due dates: ?board=1,2&label=bug&type=event|todo, token in url replaces jwt
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var filter dto.CalendarFilterDto
+	for _, value := range queryList(query["board"]) {
+		boardID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid board", http.StatusBadRequest)
+			return
+		}
+		filter.Boards = append(filter.Boards, uint(boardID))
+	}
+	filter.Labels = queryList(query["label"])
+
+	component := query.Get("type")
+	if component == "" {
+		component = icalEvent
+	}
+	if component != icalEvent && component != icalTodo {
+		http.Error(w, "Invalid type, use event or todo", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := h.service.Feed(chi.URLParam(r, "token"), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	body := icalFeed(tasks, component)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatch(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Write(body)
+}
+
+// values of query can be repeated or separated by commas
+func queryList(values []string) []string {
+	var res []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				res = append(res, item)
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"todo/internal/todo/models"
+	"todo/internal/todo/services"
+
+	"go.uber.org/zap"
+)
+
+// comment line keeps connection open behind proxies
+const sseHeartbeat = 25 * time.Second
+
+type EventsHandler struct {
+	service   EventsHandlerer
+	tasks     TasksHandlerer // for commands of WebSocket clients
+	transfers TransfersHandlerer
+	logger    *zap.Logger
+}
+
+type EventsHandlerer interface {
+	Subscribe(boardID uint, userID uint) (*services.Subscription, error)
+	Unsubscribe(sub *services.Subscription)
+	Replay(boardID uint, lastID uint) ([]models.BoardEvent, bool, error)
+	Join(sub *services.Subscription) error
+}
+
+func NewEventsHandler(t EventsHandlerer, tasks TasksHandlerer, transfers TransfersHandlerer, logger *zap.Logger) EventsHandler {
+	return EventsHandler{
+		service:   t,
+		tasks:     tasks,
+		transfers: transfers,
+		logger:    logger,
+	}
+}
+
+// Stream events of board as Server-Sent Events, Last-Event-ID header
+// (or ?last_event_id=) resumes the stream after reconnect
+func (h *EventsHandler) BoardEvents(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lastIDStr := r.Header.Get("Last-Event-ID")
+	if lastIDStr == "" {
+		lastIDStr = r.URL.Query().Get("last_event_id")
+	}
+
+	var lastID uint64
+	if lastIDStr != "" {
+		var err error
+		lastID, err = strconv.ParseUint(lastIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// subscribe before replay, so nothing is lost between them
+	sub, err := h.service.Subscribe(boardID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer h.service.Unsubscribe(sub)
+
+	var replay []models.BoardEvent
+	reset := false
+	if lastIDStr != "" {
+		replay, reset, err = h.service.Replay(boardID, uint(lastID))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// client has to reload the board, some events are no longer in the log
+	if reset {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+
+	sent := uint(lastID)
+	for _, event := range replay {
+		writeSSE(w, event)
+		sent = event.ID
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-sub.Events:
+			if !ok {
+				// dropped by service, client reconnects with Last-Event-ID
+				return
+			}
+			// already replayed, ids of board follow commit order, so later events are never skipped
+			if event.ID <= sent {
+				continue
+			}
+			writeSSE(w, event)
+			sent = event.ID
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event models.BoardEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event, event.Payload)
+}
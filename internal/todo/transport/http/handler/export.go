@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+type ExportHandler struct {
+	service ExportHandlerer
+	logger  *zap.Logger
+}
+
+type ExportHandlerer interface {
+	ExportBoard(id uint, userID uint) (*models.Export, error)
+	ExportAll(userID uint) (*models.Export, error)
+}
+
+func NewExportHandler(t ExportHandlerer, logger *zap.Logger) ExportHandler {
+	return ExportHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+var exportTypes = map[string]struct {
+	contentType string
+	ext         string
+}{
+	exportJSON:     {"application/json", "json"},
+	exportCSV:      {"text/csv; charset=utf-8", "csv"},
+	exportMarkdown: {"text/markdown; charset=utf-8", "md"},
+}
+
+// Export board with tasks as ?format=json|csv|markdown, json is default
+func (h *ExportHandler) ExportBoard(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.ExportBoard(id, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.write(w, export, format, fmt.Sprintf("board_%d", id))
+}
+
+// Export all boards of user
+func (h *ExportHandler) ExportAll(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.ExportAll(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.write(w, export, format, "boards")
+}
+
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return exportJSON, true
+	}
+
+	if _, ok := exportTypes[format]; !ok {
+		http.Error(w, "Invalid format, use json, csv or markdown", http.StatusBadRequest)
+		return "", false
+	}
+
+	return format, true
+}
+
+// tasks are streamed, errors after the start are only logged
+func (h *ExportHandler) write(w http.ResponseWriter, export *models.Export, format string, name string) {
+	kind := exportTypes[format]
+	filename := fmt.Sprintf("%s_%s.%s", name, time.Now().UTC().Format("2006-01-02"), kind.ext)
+	w.Header().Set("Content-Type", kind.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	writer := newExportWriter(format, w, export)
+	if err := writer.Begin(); err != nil {
+		h.logger.Error("export", zap.Error(err))
+		return
+	}
+
+	if err := export.Each(writer.Task); err != nil {
+		h.logger.Error("export", zap.Error(err))
+		return
+	}
+
+	if err := writer.End(); err != nil {
+		h.logger.Error("export", zap.Error(err))
+	}
+}
@@ -0,0 +1,422 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+)
+
+// export formats
+const (
+	exportJSON     = "json"
+	exportCSV      = "csv"
+	exportMarkdown = "markdown"
+)
+
+// exportWriter writes export by parts, tasks come one by one in order of boards
+type exportWriter interface {
+	Begin() error
+	Task(task models.ExportTask) error
+	End() error
+}
+
+func newExportWriter(format string, w io.Writer, export *models.Export) exportWriter {
+	meta := newExportMeta(export)
+
+	switch format {
+	case exportCSV:
+		return &csvExportWriter{w: csv.NewWriter(w), meta: meta}
+	case exportMarkdown:
+		return &markdownExportWriter{w: w, meta: meta}
+	}
+
+	return &jsonExportWriter{w: w, meta: meta}
+}
+
+// exportMeta holds names of statuses, users, fields and labels to describe tasks
+type exportMeta struct {
+	export   *models.Export
+	statuses map[uint]string
+	users    map[uint]string
+	labels   map[uint]string
+	boards   map[uint]int                          // index in export.Boards
+	fields   map[uint]map[string]models.BoardField // by board and field id
+}
+
+func newExportMeta(export *models.Export) *exportMeta {
+	meta := &exportMeta{
+		export:   export,
+		statuses: make(map[uint]string, len(export.Statuses)),
+		users:    make(map[uint]string),
+		labels:   make(map[uint]string),
+		boards:   make(map[uint]int, len(export.Boards)),
+		fields:   make(map[uint]map[string]models.BoardField, len(export.Boards)),
+	}
+
+	for _, status := range export.Statuses {
+		meta.statuses[status.ID] = status.Type
+	}
+
+	for i, board := range export.Boards {
+		meta.boards[board.ID] = i
+		for _, member := range board.Members {
+			meta.users[member.ID] = member.Username
+		}
+		for _, label := range board.Labels {
+			meta.labels[label.ID] = label.Name
+		}
+
+		fields := make(map[string]models.BoardField, len(board.Fields))
+		for _, field := range board.Fields {
+			fields[strconv.FormatUint(uint64(field.ID), 10)] = field
+		}
+		meta.fields[board.ID] = fields
+	}
+
+	return meta
+}
+
+// labelsText joins names of labels of task
+func (m *exportMeta) labelsText(ids []uint) string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := m.labels[id]; ok {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// fieldText formats value of custom field for people
+func (m *exportMeta) fieldText(field models.BoardField, value any) string {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ", ")
+	case float64:
+		if field.Type == models.FieldUser {
+			if username, ok := m.users[uint(v)]; ok {
+				return username
+			}
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+type jsonExportWriter struct {
+	w     io.Writer
+	meta  *exportMeta
+	tasks int
+}
+
+// json is written as dto.ExportDto, tasks are the last key
+func (e *jsonExportWriter) Begin() error {
+	export := e.meta.export
+	statuses := make([]dto.ExportStatusDto, 0, len(export.Statuses))
+	for _, status := range export.Statuses {
+		statuses = append(statuses, dto.ExportStatusDto{ID: status.ID, Name: status.Type})
+	}
+
+	boards := make([]dto.ExportBoardDto, 0, len(export.Boards))
+	for _, board := range export.Boards {
+		members := make([]dto.ExportUserDto, 0, len(board.Members))
+		for _, member := range board.Members {
+			members = append(members, dto.ExportUserDto{ID: member.ID, Username: member.Username})
+		}
+
+		fields := make([]dto.BoardFieldDto, 0, len(board.Fields))
+		for _, field := range board.Fields {
+			options := field.Options
+			if options == nil {
+				options = []string{}
+			}
+			fields = append(fields, dto.BoardFieldDto{
+				ID:        field.ID,
+				BoardId:   field.BoardId,
+				Name:      field.Name,
+				Type:      field.Type,
+				Options:   options,
+				Position:  field.Position,
+				CreatedAt: field.CreatedAt,
+				UpdatedAt: field.UpdatedAt,
+			})
+		}
+
+		labels := make([]dto.BoardLabelDto, 0, len(board.Labels))
+		for _, label := range board.Labels {
+			labels = append(labels, dto.BoardLabelDto{
+				ID:        label.ID,
+				BoardId:   label.BoardId,
+				Name:      label.Name,
+				Color:     label.Color,
+				CreatedAt: label.CreatedAt,
+			})
+		}
+
+		boards = append(boards, dto.ExportBoardDto{
+			ID:           board.ID,
+			Name:         board.Name,
+			OwnerId:      board.OwnerId,
+			EstimateUnit: board.EstimateUnit,
+			Members:      members,
+			Fields:       fields,
+			Labels:       labels,
+			CreatedAt:    board.CreatedAt,
+		})
+	}
+
+	head, err := json.Marshal(dto.ExportDto{
+		Version:    models.ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Statuses:   statuses,
+		Boards:     boards,
+		Tasks:      []dto.ExportTaskDto{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// tasks are the last field of ExportDto, its empty list is opened for streaming
+	head = head[:len(head)-len(`[]}`)]
+	if !strings.HasSuffix(string(head), `"tasks":`) {
+		return fmt.Errorf("unexpected json of export head")
+	}
+
+	_, err = e.w.Write(append(head, '['))
+	return err
+}
+
+func (e *jsonExportWriter) Task(task models.ExportTask) error {
+	item := dto.ExportTaskDto{
+		ID:             task.ID,
+		BoardId:        task.BoardId,
+		Title:          task.Title,
+		Description:    task.Description,
+		StatusId:       task.StatusId,
+		Status:         e.meta.statuses[task.StatusId],
+		Priority:       task.Priority,
+		DueAt:          task.DueAt,
+		Estimate:       task.Estimate,
+		TrackedSeconds: task.TrackedSeconds,
+		CustomFields:   task.CustomFields,
+		Labels:         task.Labels,
+		Comments:       make([]dto.ExportCommentDto, 0, len(task.Comments)),
+		CreatedAt:      task.CreatedAt,
+		UpdatedAt:      task.UpdatedAt,
+	}
+	if item.CustomFields == nil {
+		item.CustomFields = map[string]any{}
+	}
+	if item.Labels == nil {
+		item.Labels = []uint{}
+	}
+	if task.UserId != 0 {
+		item.Assignee = &dto.ExportUserDto{ID: task.UserId, Username: task.Assignee}
+	}
+	for _, comment := range task.Comments {
+		item.Comments = append(item.Comments, dto.ExportCommentDto{
+			UserId:    comment.UserId,
+			Author:    comment.Author,
+			Text:      comment.Text,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	body, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	if e.tasks > 0 {
+		body = append([]byte{','}, body...)
+	}
+	e.tasks++
+
+	_, err = e.w.Write(append(body, '\n'))
+	return err
+}
+
+func (e *jsonExportWriter) End() error {
+	_, err := e.w.Write([]byte("]}\n"))
+	return err
+}
+
+// csv has a row per task and a column per custom field name of all boards
+type csvExportWriter struct {
+	w      *csv.Writer
+	meta   *exportMeta
+	fields []string       // names of field columns
+	column map[string]int // by lower name
+}
+
+func (e *csvExportWriter) Begin() error {
+	e.column = make(map[string]int)
+	for _, board := range e.meta.export.Boards {
+		for _, field := range board.Fields {
+			key := strings.ToLower(field.Name)
+			if _, ok := e.column[key]; !ok {
+				e.column[key] = len(e.fields)
+				e.fields = append(e.fields, field.Name)
+			}
+		}
+	}
+
+	header := []string{"board_id", "board", "task_id", "title", "description", "status", "assignee_id", "assignee",
+		"priority", "due_at", "estimate", "estimate_unit", "tracked_seconds", "created_at", "updated_at", "labels", "comments"}
+
+	return e.w.Write(csvCells(append(header, e.fields...)))
+}
+
+func (e *csvExportWriter) Task(task models.ExportTask) error {
+	board := e.meta.export.Boards[e.meta.boards[task.BoardId]]
+
+	var dueAt, estimate, assigneeID string
+	if task.DueAt != nil {
+		dueAt = task.DueAt.UTC().Format(time.RFC3339)
+	}
+	if task.Estimate != nil {
+		estimate = strconv.FormatFloat(*task.Estimate, 'f', -1, 64)
+	}
+	if task.UserId != 0 {
+		assigneeID = strconv.FormatUint(uint64(task.UserId), 10)
+	}
+
+	comments := make([]string, 0, len(task.Comments))
+	for _, comment := range task.Comments {
+		comments = append(comments, fmt.Sprintf("%s (%s): %s", comment.Author, comment.CreatedAt.UTC().Format("2006-01-02 15:04"), comment.Text))
+	}
+
+	record := []string{
+		strconv.FormatUint(uint64(task.BoardId), 10), board.Name,
+		strconv.FormatUint(uint64(task.ID), 10), task.Title, task.Description,
+		e.meta.statuses[task.StatusId], assigneeID, task.Assignee,
+		strconv.Itoa(task.Priority), dueAt, estimate, board.EstimateUnit,
+		strconv.FormatInt(task.TrackedSeconds, 10),
+		task.CreatedAt.UTC().Format(time.RFC3339), task.UpdatedAt.UTC().Format(time.RFC3339),
+		e.meta.labelsText(task.Labels), strings.Join(comments, "\n"),
+	}
+
+	values := make([]string, len(e.fields))
+	for key, value := range task.CustomFields {
+		field, ok := e.meta.fields[task.BoardId][key]
+		if !ok {
+			continue
+		}
+		values[e.column[strings.ToLower(field.Name)]] = e.meta.fieldText(field, value)
+	}
+
+	return e.w.Write(csvCells(append(record, values...)))
+}
+
+func (e *csvExportWriter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// markdown groups tasks by boards and statuses
+type markdownExportWriter struct {
+	w      io.Writer
+	meta   *exportMeta
+	next   int  // index of the next board to write
+	status uint // status of the last task
+}
+
+var priorityNames = map[int]string{
+	models.PriorityLow:    "низкий",
+	models.PriorityMedium: "средний",
+	models.PriorityHigh:   "высокий",
+}
+
+func (e *markdownExportWriter) Begin() error {
+	return nil
+}
+
+// boards before the board of task are written with their tasks, so only headers are left
+func (e *markdownExportWriter) Task(task models.ExportTask) error {
+	var sb strings.Builder
+
+	index := e.meta.boards[task.BoardId]
+	for e.next <= index {
+		e.writeBoard(&sb, e.meta.export.Boards[e.next])
+		e.next++
+		e.status = 0
+	}
+
+	if task.StatusId != e.status {
+		fmt.Fprintf(&sb, "## %s\n\n", e.meta.statuses[task.StatusId])
+		e.status = task.StatusId
+	}
+
+	board := e.meta.export.Boards[index]
+	fmt.Fprintf(&sb, "### #%d %s\n\n", task.ID, task.Title)
+	if task.Assignee != "" {
+		fmt.Fprintf(&sb, "- Исполнитель: %s\n", task.Assignee)
+	}
+	if name, ok := priorityNames[task.Priority]; ok {
+		fmt.Fprintf(&sb, "- Приоритет: %s\n", name)
+	}
+	if task.DueAt != nil {
+		fmt.Fprintf(&sb, "- Срок: %s UTC\n", task.DueAt.UTC().Format("2006-01-02 15:04"))
+	}
+	if task.Estimate != nil {
+		fmt.Fprintf(&sb, "- Оценка: %s %s\n", strconv.FormatFloat(*task.Estimate, 'f', -1, 64), board.EstimateUnit)
+	}
+	if len(task.Labels) > 0 {
+		fmt.Fprintf(&sb, "- Метки: %s\n", e.meta.labelsText(task.Labels))
+	}
+	for _, field := range board.Fields {
+		if value, ok := task.CustomFields[strconv.FormatUint(uint64(field.ID), 10)]; ok {
+			fmt.Fprintf(&sb, "- %s: %s\n", field.Name, e.meta.fieldText(field, value))
+		}
+	}
+	sb.WriteString("\n")
+
+	if task.Description != "" {
+		sb.WriteString(task.Description + "\n\n")
+	}
+
+	for _, comment := range task.Comments {
+		text := strings.ReplaceAll(comment.Text, "\n", "\n> ")
+		fmt.Fprintf(&sb, "> **%s**, %s UTC: %s\n\n", comment.Author, comment.CreatedAt.UTC().Format("2006-01-02 15:04"), text)
+	}
+
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
+
+func (e *markdownExportWriter) writeBoard(sb *strings.Builder, board models.ExportBoard) {
+	fmt.Fprintf(sb, "# %s\n\n", board.Name)
+
+	if len(board.Members) == 0 {
+		return
+	}
+
+	members := make([]string, 0, len(board.Members))
+	for _, member := range board.Members {
+		members = append(members, member.Username)
+	}
+	fmt.Fprintf(sb, "Участники: %s\n\n", strings.Join(members, ", "))
+}
+
+// boards without tasks are written at the end
+func (e *markdownExportWriter) End() error {
+	var sb strings.Builder
+	for ; e.next < len(e.meta.export.Boards); e.next++ {
+		e.writeBoard(&sb, e.meta.export.Boards[e.next])
+	}
+
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
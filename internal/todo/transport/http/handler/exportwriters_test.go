@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"todo/internal/todo/importer"
+	"todo/internal/todo/models"
+	"todo/internal/todo/services"
+
+	"go.uber.org/zap"
+)
+
+// importStore keeps plans instead of writing them, users are found by usernames of users
+type importStore struct {
+	users map[string]uint
+	plans []models.ImportPlan
+}
+
+func (s *importStore) IsBoardMember(boardID uint, userID uint) (bool, error) { return true, nil }
+
+func (s *importStore) GetBoardFields(boardID uint) ([]models.BoardField, error) { return nil, nil }
+
+func (s *importStore) GetBoardLabels(boardID uint) ([]models.BoardLabel, error) { return nil, nil }
+
+func (s *importStore) GetStatuses() ([]models.Status, error) {
+	return []models.Status{{ID: 1, Type: "in process"}, {ID: 2, Type: "done"}, {ID: 3, Type: "archived"}}, nil
+}
+
+func (s *importStore) GetUsersByUsernames(usernames []string) (map[string]uint, error) {
+	users := make(map[string]uint)
+	for _, username := range usernames {
+		if id, ok := s.users[strings.ToLower(username)]; ok {
+			users[strings.ToLower(username)] = id
+		}
+	}
+	return users, nil
+}
+
+func (s *importStore) GetImported(userID uint, source string, kind string, externalIDs []string) (map[string]uint, error) {
+	return map[string]uint{}, nil
+}
+
+func (s *importStore) Import(plan models.ImportPlan, userID uint) (uint, error) {
+	s.plans = append(s.plans, plan)
+	return 100, nil
+}
+
+func testExport() *models.Export {
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	estimate := 2.5
+	tasks := []models.ExportTask{{
+		Task: models.Task{
+			ID: 10, Title: "Купить молоко", Description: "2 литра", BoardId: 5, StatusId: 2, UserId: 7,
+			Priority: models.PriorityHigh, DueAt: &due, Estimate: &estimate,
+			CustomFields: map[string]any{"21": "магазин", "22": []any{"срочно", "дом"}, "23": float64(8)},
+			Labels:       []uint{31, 32},
+		},
+		Assignee: "anna",
+		Comments: []models.ExportComment{
+			{UserId: 8, Author: "boris", Text: "куплю", CreatedAt: due},
+			{UserId: 9, Author: "gone", Text: "уже нет", CreatedAt: due},
+		},
+	}}
+
+	return &models.Export{
+		Statuses: []models.Status{{ID: 1, Type: "in process"}, {ID: 2, Type: "done"}, {ID: 3, Type: "archived"}},
+		Boards: []models.ExportBoard{{
+			Board:   models.Board{ID: 5, Name: "Дом", OwnerId: 7, EstimateUnit: models.EstimateHours},
+			Members: []models.ExportUser{{ID: 7, Username: "anna"}, {ID: 8, Username: "boris"}},
+			Fields: []models.BoardField{
+				{ID: 21, BoardId: 5, Name: "Где", Type: models.FieldText},
+				{ID: 22, BoardId: 5, Name: "Теги", Type: models.FieldMultiSelect, Options: []string{"срочно", "дом"}},
+				{ID: 23, BoardId: 5, Name: "Проверяет", Type: models.FieldUser},
+			},
+			Labels: []models.BoardLabel{{ID: 31, BoardId: 5, Name: "покупки", Color: "#00ff00"}, {ID: 32, BoardId: 5, Name: "семья"}},
+		}},
+		Each: func(fn func(task models.ExportTask) error) error {
+			for _, task := range tasks {
+				if err := fn(task); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func writeExport(t *testing.T, format string, export *models.Export) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := newExportWriter(format, &buf, export)
+	if err := w.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := export.Each(w.Task); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+// json export imported on another server gives the same board, users are matched by usernames
+func TestExportImportRoundTrip(t *testing.T) {
+	data := writeExport(t, exportJSON, testExport())
+
+	boards, err := importer.Parse(models.ImportNative, []byte(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 1 || boards[0].Name != "Дом" || len(boards[0].Tasks) != 1 {
+		t.Fatalf("boards %+v", boards)
+	}
+
+	store := &importStore{users: map[string]uint{"anna": 70, "boris": 80}}
+	report, err := services.NewImportService(store, zap.NewNop()).Import(models.ImportNative, []byte(data), "", false, 70)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.plans) != 1 {
+		t.Fatalf("plans %+v", store.plans)
+	}
+
+	plan := store.plans[0]
+	if plan.Name != "Дом" || plan.EstimateUnit != models.EstimateHours || !slices.Equal(plan.Members, []uint{80}) {
+		t.Errorf("board of plan %+v", plan)
+	}
+
+	var fields []string
+	for _, field := range plan.Fields {
+		fields = append(fields, field.Name+":"+field.Type)
+	}
+	if !slices.Equal(fields, []string{"Где:text", "Теги:multi_select", "Проверяет:user"}) || !slices.Equal(plan.Fields[1].Options, []string{"срочно", "дом"}) {
+		t.Errorf("fields %+v", plan.Fields)
+	}
+
+	if len(plan.BoardLabels) != 2 || plan.BoardLabels[0].Name != "покупки" || plan.BoardLabels[0].Color != "#00ff00" || plan.BoardLabels[1].Name != "семья" {
+		t.Errorf("labels %+v", plan.BoardLabels)
+	}
+
+	item := plan.Tasks[0]
+	task := item.Task
+	if item.ExternalId != "10" || task.Title != "Купить молоко" || task.Description != "2 литра" || task.StatusId != 2 || task.UserId != 70 ||
+		task.Priority != models.PriorityHigh || task.DueAt == nil || !task.DueAt.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) ||
+		task.Estimate == nil || *task.Estimate != 2.5 {
+		t.Errorf("task %+v", task)
+	}
+
+	if item.FieldValues["где"] != "магазин" || !slices.Equal(item.FieldValues["теги"].([]string), []string{"срочно", "дом"}) || item.FieldValues["проверяет"] != uint(80) {
+		t.Errorf("values %+v", item.FieldValues)
+	}
+	if !slices.Equal(item.LabelNames, []string{"покупки", "семья"}) {
+		t.Errorf("labels of task %v", item.LabelNames)
+	}
+
+	if len(item.Comments) != 2 || item.Comments[0].UserId != 80 || item.Comments[0].Text != "куплю" ||
+		item.Comments[1].UserId != 0 || item.Comments[1].Text != "gone: уже нет" {
+		t.Errorf("comments %+v", item.Comments)
+	}
+
+	if len(report.Boards) != 1 || report.Boards[0].Tasks != 1 || report.Boards[0].BoardId != 100 || len(report.Boards[0].Unmapped) != 0 {
+		t.Errorf("report %+v", report.Boards)
+	}
+}
+
+func TestImportNativeVersion(t *testing.T) {
+	_, err := importer.Parse(models.ImportNative, []byte(`{"version": 2, "boards": [], "tasks": []}`), "")
+	if !errors.Is(err, models.ErrInvalidInput) {
+		t.Fatalf("export of newer version: %v", err)
+	}
+}
+
+func TestExportLabels(t *testing.T) {
+	if csv := writeExport(t, exportCSV, testExport()); !strings.Contains(csv, ",labels,") || !strings.Contains(csv, `"покупки, семья"`) {
+		t.Errorf("csv without labels:\n%s", csv)
+	}
+
+	if md := writeExport(t, exportMarkdown, testExport()); !strings.Contains(md, "- Метки: покупки, семья\n") {
+		t.Errorf("markdown without labels:\n%s", md)
+	}
+}
+
+// cells which spreadsheet would run as formula are quoted
+func TestExportCSVFormula(t *testing.T) {
+	export := testExport()
+	each := export.Each
+	export.Each = func(fn func(task models.ExportTask) error) error {
+		return each(func(task models.ExportTask) error {
+			task.Title = `=HYPERLINK("http://evil","x")`
+			task.Description = "@SUM(1)"
+			return fn(task)
+		})
+	}
+
+	csv := writeExport(t, exportCSV, export)
+	for _, want := range []string{`"'=HYPERLINK(""http://evil"",""x"")"`, ",'@SUM(1),"} {
+		if !strings.Contains(csv, want) {
+			t.Errorf("csv without %s:\n%s", want, csv)
+		}
+	}
+}
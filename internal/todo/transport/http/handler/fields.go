@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+type FieldsHandler struct {
+	service FieldsHandlerer
+	logger  *zap.Logger
+}
+
+type FieldsHandlerer interface {
+	GetFields(boardID uint, userID uint) ([]dto.BoardFieldDto, error)
+	CreateField(boardID uint, body dto.PostBoardFieldDto, userID uint) (*dto.BoardFieldDto, error)
+	UpdateField(boardID uint, id uint, body dto.PostBoardFieldDto, userID uint) (*dto.BoardFieldDto, error)
+	DeleteField(boardID uint, id uint, userID uint) error
+}
+
+func NewFieldsHandler(t FieldsHandlerer, logger *zap.Logger) FieldsHandler {
+	return FieldsHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get custom fields of board
+func (h *FieldsHandler) GetFields(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	fields, err := h.service.GetFields(boardID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(fields)
+}
+
+// Add custom field to board
+func (h *FieldsHandler) CreateField(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostBoardFieldDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	field, err := h.service.CreateField(boardID, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(field)
+}
+
+// Replace name, options and position of field
+func (h *FieldsHandler) UpdateField(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "fieldID")
+	if !ok {
+		return
+	}
+
+	var body dto.PostBoardFieldDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	field, err := h.service.UpdateField(boardID, id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(field)
+}
+
+// Delete field with its values in tasks
+func (h *FieldsHandler) DeleteField(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "fieldID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteField(boardID, id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+	"todo/internal/todo/models"
+
 	"go.uber.org/zap"
 )
 
@@ -9,6 +13,22 @@ type TodoHandler struct {
 	StatusesHandler StatusesHandler
 	TasksHandler    TasksHandler
 	UserHandler     UserHandler
+	OutboxHandler   OutboxHandler
+
+	NotificationsHandler NotificationsHandler
+	WebhooksHandler      WebhooksHandler
+	EventsHandler        EventsHandler
+	TimeHandler          TimeHandler
+	AnalyticsHandler     AnalyticsHandler
+	FieldsHandler        FieldsHandler
+	LabelsHandler        LabelsHandler
+	TemplatesHandler     TemplatesHandler
+	TransfersHandler     TransfersHandler
+	BulkHandler          BulkHandler
+	ExportHandler        ExportHandler
+	ImportHandler        ImportHandler
+	CalendarHandler      CalendarHandler
+	AttachmentsHandler   AttachmentsHandler
 }
 
 type TodoService struct {
@@ -16,6 +36,22 @@ type TodoService struct {
 	StatusesService StatusesHandlerer
 	TasksService    TasksHandlerer
 	UserService     UserHandlerer
+	OutboxService   OutboxHandlerer
+
+	NotificationsService NotificationsHandlerer
+	WebhooksService      WebhooksHandlerer
+	EventsService        EventsHandlerer
+	TimeService          TimeHandlerer
+	AnalyticsService     AnalyticsHandlerer
+	FieldsService        FieldsHandlerer
+	LabelsService        LabelsHandlerer
+	TemplatesService     TemplatesHandlerer
+	TransfersService     TransfersHandlerer
+	BulkService          BulkHandlerer
+	ExportService        ExportHandlerer
+	ImportService        ImportHandlerer
+	CalendarService      CalendarHandlerer
+	AttachmentsService   AttachmentsHandlerer
 }
 
 func New(t TodoService, logger *zap.Logger) TodoHandler {
@@ -24,5 +60,45 @@ func New(t TodoService, logger *zap.Logger) TodoHandler {
 		StatusesHandler: NewStatusesHandler(t.StatusesService, logger),
 		TasksHandler:    NewTasksHandler(t.TasksService, logger),
 		UserHandler:     NewUserHandler(t.UserService, logger),
+		OutboxHandler:   NewOutboxHandler(t.OutboxService, logger),
+
+		NotificationsHandler: NewNotificationsHandler(t.NotificationsService, logger),
+		WebhooksHandler:      NewWebhooksHandler(t.WebhooksService, logger),
+		EventsHandler:        NewEventsHandler(t.EventsService, t.TasksService, t.TransfersService, logger),
+		TimeHandler:          NewTimeHandler(t.TimeService, logger),
+		AnalyticsHandler:     NewAnalyticsHandler(t.AnalyticsService, logger),
+		FieldsHandler:        NewFieldsHandler(t.FieldsService, logger),
+		LabelsHandler:        NewLabelsHandler(t.LabelsService, logger),
+		TemplatesHandler:     NewTemplatesHandler(t.TemplatesService, t.TasksService, logger),
+		TransfersHandler:     NewTransfersHandler(t.TransfersService, logger),
+		BulkHandler:          NewBulkHandler(t.BulkService, logger),
+		ExportHandler:        NewExportHandler(t.ExportService, logger),
+		ImportHandler:        NewImportHandler(t.ImportService, logger),
+		CalendarHandler:      NewCalendarHandler(t.CalendarService, logger),
+		AttachmentsHandler:   NewAttachmentsHandler(t.AttachmentsService, logger),
+	}
+}
+
+// get id of authorized user, set by JWT or TgUser middleware
+func userIDFromCtx(r *http.Request) uint {
+	userID, _ := r.Context().Value("user_id").(uint)
+	return userID
+}
+
+// write service error with matching status code
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrTgNotLinked):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, models.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
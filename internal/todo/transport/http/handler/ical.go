@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"todo/internal/todo/models"
+	"unicode/utf8"
+)
+
+// calendar components of tasks
+const (
+	icalEvent = "event"
+	icalTodo  = "todo"
+)
+
+const icalTime = "20060102T150405Z"
+
+// icalWriter writes RFC 5545 content lines with CRLF and folding
+type icalWriter struct {
+	buf bytes.Buffer
+}
+
+// lines longer than 75 octets are folded, utf-8 characters are not split
+func (w *icalWriter) line(name string, value string) {
+	line := name + ":" + value
+	for first := true; ; first = false {
+		limit := 75
+		if !first {
+			limit = 74 // folded line starts with space
+			w.buf.WriteByte(' ')
+		}
+
+		if len(line) <= limit {
+			w.buf.WriteString(line + "\r\n")
+			return
+		}
+
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut] + "\r\n")
+		line = line[cut:]
+	}
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func icalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// priorities of RFC 5545 are 1 for the highest and 9 for the lowest
+var icalPriorities = map[int]string{
+	models.PriorityHigh:   "1",
+	models.PriorityMedium: "5",
+	models.PriorityLow:    "9",
+}
+
+// icalFeed builds calendar of tasks as events or todos
+func icalFeed(tasks []models.CalendarTask, component string) []byte {
+	w := &icalWriter{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//todo//tasks//RU")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", "Задачи")
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT15M")
+	w.line("X-PUBLISHED-TTL", "PT15M")
+
+	name := "VEVENT"
+	if component == icalTodo {
+		name = "VTODO"
+	}
+
+	for _, task := range tasks {
+		done := slices.Contains(models.DoneStatuses, task.StatusId)
+
+		// stamps are times of changes, so feed and its ETag stay the same until tasks change
+		w.line("BEGIN", name)
+		w.line("UID", fmt.Sprintf("task-%d@todo", task.ID))
+		w.line("DTSTAMP", task.UpdatedAt.UTC().Format(icalTime))
+		w.line("CREATED", task.CreatedAt.UTC().Format(icalTime))
+		w.line("LAST-MODIFIED", task.UpdatedAt.UTC().Format(icalTime))
+		w.line("SUMMARY", icalText(task.Title))
+
+		description := task.Description
+		details := fmt.Sprintf("Доска: %s\nСтатус: %s", task.BoardName, task.Status)
+		if task.Assignee != "" {
+			details += "\nИсполнитель: " + task.Assignee
+		}
+		if description != "" {
+			description += "\n\n"
+		}
+		w.line("DESCRIPTION", icalText(description+details))
+		w.line("CATEGORIES", icalText(task.BoardName))
+
+		if priority, ok := icalPriorities[task.Priority]; ok {
+			w.line("PRIORITY", priority)
+		}
+
+		due := task.DueAt.UTC().Format(icalTime)
+		if component == icalTodo {
+			w.line("DUE", due)
+			if done {
+				w.line("STATUS", "COMPLETED")
+			} else {
+				w.line("STATUS", "NEEDS-ACTION")
+			}
+		} else {
+			// deadline is an event of zero duration
+			w.line("DTSTART", due)
+			w.line("TRANSP", "TRANSPARENT")
+		}
+
+		w.line("END", name)
+	}
+
+	w.line("END", "VCALENDAR")
+
+	return w.buf.Bytes()
+}
+
+// weak and strong tags are compared by value, * matches any tag
+func etagMatch(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
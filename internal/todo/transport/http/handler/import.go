@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+// size of export file
+const importMaxBytes = 50 << 20
+
+type ImportHandler struct {
+	service ImportHandlerer
+	logger  *zap.Logger
+}
+
+type ImportHandlerer interface {
+	Import(source string, data []byte, name string, dryRun bool, userID uint) (*dto.ImportReportDto, error)
+}
+
+func NewImportHandler(t ImportHandlerer, logger *zap.Logger) ImportHandler {
+	return ImportHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Import export file of Trello or Todoist from body: ?source=trello|todoist&dry_run=true&name=
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, importMaxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "File is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := query.Get("dry_run") == "true" || query.Get("dry_run") == "1"
+
+	res, err := h.service.Import(query.Get("source"), data, query.Get("name"), dryRun, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+type LabelsHandler struct {
+	service LabelsHandlerer
+	logger  *zap.Logger
+}
+
+type LabelsHandlerer interface {
+	GetLabels(boardID uint, userID uint) ([]dto.BoardLabelDto, error)
+	CreateLabel(boardID uint, body dto.PostBoardLabelDto, userID uint) (*dto.BoardLabelDto, error)
+	UpdateLabel(boardID uint, id uint, body dto.PostBoardLabelDto, userID uint) (*dto.BoardLabelDto, error)
+	DeleteLabel(boardID uint, id uint, userID uint) error
+}
+
+func NewLabelsHandler(t LabelsHandlerer, logger *zap.Logger) LabelsHandler {
+	return LabelsHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get labels of board
+func (h *LabelsHandler) GetLabels(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	labels, err := h.service.GetLabels(boardID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(labels)
+}
+
+// Add label to board
+func (h *LabelsHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostBoardLabelDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	label, err := h.service.CreateLabel(boardID, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(label)
+}
+
+// Replace name and color of label
+func (h *LabelsHandler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "labelID")
+	if !ok {
+		return
+	}
+
+	var body dto.PostBoardLabelDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	label, err := h.service.UpdateLabel(boardID, id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(label)
+}
+
+// Delete label, tasks lose it
+func (h *LabelsHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "labelID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteLabel(boardID, id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
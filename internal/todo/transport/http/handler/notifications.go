@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+
+	"go.uber.org/zap"
+)
+
+type NotificationsHandler struct {
+	service NotificationsHandlerer
+	logger  *zap.Logger
+}
+
+type NotificationsHandlerer interface {
+	GetSettings(userID uint) (*dto.NotificationSettingsDto, error)
+	UpdateSettings(body dto.NotificationSettingsDto, userID uint) (*dto.NotificationSettingsDto, error)
+	SendEmailCode(userID uint) error
+	ConfirmEmail(body dto.PostEmailConfirmDto, userID uint) (*dto.NotificationSettingsDto, error)
+}
+
+func NewNotificationsHandler(t NotificationsHandlerer, logger *zap.Logger) NotificationsHandler {
+	return NotificationsHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get notification settings of user
+func (h *NotificationsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.service.GetSettings(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settings)
+}
+
+// Replace notification settings of user
+func (h *NotificationsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var body dto.NotificationSettingsDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settings)
+}
+
+// Send new confirmation code to email of user
+func (h *NotificationsHandler) SendEmailCode(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.SendEmailCode(userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Confirm email of user by code from letter
+func (h *NotificationsHandler) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	var body dto.PostEmailConfirmDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.service.ConfirmEmail(body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settings)
+}
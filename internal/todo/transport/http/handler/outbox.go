@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type OutboxHandler struct {
+	service OutboxHandlerer
+	logger  *zap.Logger
+}
+
+type OutboxHandlerer interface {
+	GetOutbox(status string) ([]models.OutboxMessage, error)
+	ReplayMessage(id string) (*models.OutboxMessage, error)
+}
+
+func NewOutboxHandler(t OutboxHandlerer, logger *zap.Logger) OutboxHandler {
+	return OutboxHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Get outbox messages, ?status=dead shows failed ones
+func (h *OutboxHandler) GetOutbox(w http.ResponseWriter, r *http.Request) {
+	messages, err := h.service.GetOutbox(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(messages)
+}
+
+// Put message back to the delivery queue
+func (h *OutboxHandler) ReplayMessage(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	message, err := h.service.ReplayMessage(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if message == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(message)
+}
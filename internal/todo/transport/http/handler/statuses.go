@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -16,6 +17,7 @@ type StatusesHandler struct {
 
 type StatusesHandlerer interface {
 	SetStatus(body dto.PostStatusDto) error
+	GetAllStatuses() ([]models.Status, error)
 	DeleteStatus(id string) error
 }
 
@@ -43,6 +45,19 @@ func (h *StatusesHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
+// GetAllStatuses
+func (h *StatusesHandler) GetAllStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.GetAllStatuses()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(statuses)
+}
+
 // DeleteStatus
 func (h *StatusesHandler) DeleteStatus(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
 
@@ -17,12 +18,17 @@ type TasksHandler struct {
 }
 
 type TasksHandlerer interface {
-	SetTask(body dto.PostTaskDto) error
-	GetTask(id uint) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
-	UpdateTask(body dto.PostTaskDto, id uint) error
-	DeleteTask(id string) error
-	SendAllTasks(tgName string, chatID int64) error
+	SetTask(body dto.PostTaskDto, userID uint) (*models.Task, error)
+	GetTask(id uint, userID uint) (*models.Task, error)
+	GetAllTasks(userID uint, filter dto.TaskFilterDto) ([]models.Task, error)
+	UpdateTask(body dto.PostTaskDto, id uint, userID uint) error
+	UpdateTaskStatus(id uint, statusID uint, userID uint) (*models.Task, error)
+	SnoozeTask(id uint, minutes int, userID uint) error
+	AddComment(id uint, text string, userID uint) (*models.Comment, error)
+	GetComments(id uint, userID uint) ([]models.Comment, error)
+	DeleteTask(id string, userID uint) error
+	GetMyTasks(userID uint, status int) ([]models.Task, error)
+	SendAllTasks(tgUserID int64, chatID int64) error
 }
 
 func NewTasksHandler(t TasksHandlerer, logger *zap.Logger) TasksHandler {
@@ -45,21 +51,50 @@ func (h *TasksHandler) SetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.SetTask(task); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	taskRet, err := h.service.SetTask(task, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(task)
+	json.NewEncoder(w).Encode(taskRet)
 }
 
-// Get all tasks
+// Get all tasks, ?board_id= filters by board, ?field.<id>=<value> by value of custom field
 func (h *TasksHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.GetAllTasks()
+	var filter dto.TaskFilterDto
+	query := r.URL.Query()
+	if boardIDStr := query.Get("board_id"); boardIDStr != "" {
+		boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid board_id", http.StatusBadRequest)
+			return
+		}
+		filter.BoardId = uint(boardID)
+	}
+
+	for key, values := range query {
+		fieldIDStr, ok := strings.CutPrefix(key, "field.")
+		if !ok {
+			continue
+		}
+
+		fieldID, err := strconv.ParseUint(fieldIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid field id in "+key, http.StatusBadRequest)
+			return
+		}
+
+		for _, value := range values {
+			filter.Fields = append(filter.Fields, dto.FieldFilterDto{FieldId: uint(fieldID), Value: value})
+		}
+	}
+
+	tasks, err := h.service.GetAllTasks(userIDFromCtx(r), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -77,9 +112,9 @@ func (h *TasksHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.service.GetTask(uint(id))
+	task, err := h.service.GetTask(uint(id), userIDFromCtx(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 	if task == nil {
@@ -112,35 +147,164 @@ func (h *TasksHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.UpdateTask(task, uint(id)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.UpdateTask(task, uint(id), userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+// Change status of a task
+func (h *TasksHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var status dto.PutTaskStatusDto
+	if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if status.StatusId == 0 {
+		http.Error(w, "status_id is required", http.StatusBadRequest)
 		return
 	}
 
+	task, err := h.service.UpdateTaskStatus(uint(id), status.StatusId, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(task)
 }
 
+// Add a comment to a task
+func (h *TasksHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var comment dto.PostCommentDto
+	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(comment.Text) == "" {
+		http.Error(w, "comment text cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	commentRet, err := h.service.AddComment(uint(id), comment.Text, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(commentRet)
+}
+
+// Get comments of a task
+func (h *TasksHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	comments, err := h.service.GetComments(uint(id), userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(comments)
+}
+
+// Remind about a task later
+func (h *TasksHandler) SnoozeTask(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var snooze dto.PostSnoozeDto
+	if err := json.NewDecoder(r.Body).Decode(&snooze); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if snooze.Minutes <= 0 {
+		http.Error(w, "minutes must be positive", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SnoozeTask(uint(id), snooze.Minutes, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
 // Delete a task
 func (h *TasksHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if err := h.service.DeleteTask(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.DeleteTask(id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Get tasks assigned to user with ?status=, pages of digest are built from them
+func (h *TasksHandler) GetMyTasks(w http.ResponseWriter, r *http.Request) {
+	status, err := strconv.Atoi(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := h.service.GetMyTasks(userIDFromCtx(r), status)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tasks)
+}
+
 func (h *TasksHandler) SendAllTasks(w http.ResponseWriter, r *http.Request) {
-	var user dto.PostUserDto
+	var user dto.TgUserDto
 	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	err := h.service.SendAllTasks(user.TgName, user.ChatID)
+	err := h.service.SendAllTasks(user.TgUserID, user.ChatID)
 	if err != nil {
 		http.Error(w, "No tg user", http.StatusUnauthorized)
 		return
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+type TemplatesHandler struct {
+	service TemplatesHandlerer
+	tasks   TasksHandlerer
+	logger  *zap.Logger
+}
+
+type TemplatesHandlerer interface {
+	SaveBoardTemplate(body dto.PostBoardTemplateDto, userID uint) (*dto.BoardTemplateDto, error)
+	GetBoardTemplates(userID uint) ([]dto.BoardTemplateDto, error)
+	GetBoardTemplate(id uint, userID uint) (*dto.BoardTemplateDto, error)
+	DeleteBoardTemplate(id uint, userID uint) error
+	CreateBoardFromTemplate(id uint, body dto.PostBoardFromTemplateDto, userID uint) (*models.Board, error)
+	CreateTaskTemplate(boardID uint, body dto.PostTaskTemplateDto, userID uint) (*dto.TaskTemplateDto, error)
+	GetTaskTemplates(boardID uint, userID uint) ([]dto.TaskTemplateDto, error)
+	DeleteTaskTemplate(boardID uint, id uint, userID uint) error
+	TaskFromTemplate(body dto.PostTaskFromTemplateDto, userID uint) (*dto.PostTaskDto, error)
+}
+
+// tasks from templates are created by tasks service, so they are validated and notified as usual
+func NewTemplatesHandler(t TemplatesHandlerer, tasks TasksHandlerer, logger *zap.Logger) TemplatesHandler {
+	return TemplatesHandler{
+		service: t,
+		tasks:   tasks,
+		logger:  logger,
+	}
+}
+
+// Save board as template of user
+func (h *TemplatesHandler) SaveBoardTemplate(w http.ResponseWriter, r *http.Request) {
+	var body dto.PostBoardTemplateDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	tpl, err := h.service.SaveBoardTemplate(body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tpl)
+}
+
+// Get board templates of user
+func (h *TemplatesHandler) GetBoardTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.GetBoardTemplates(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// Get board template with its fields and tasks
+func (h *TemplatesHandler) GetBoardTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	tpl, err := h.service.GetBoardTemplate(id, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tpl)
+}
+
+// Delete board template, boards created from it stay
+func (h *TemplatesHandler) DeleteBoardTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBoardTemplate(id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create board from template, body with name is optional
+func (h *TemplatesHandler) CreateBoardFromTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostBoardFromTemplateDto
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+	}
+
+	board, err := h.service.CreateBoardFromTemplate(id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(board)
+}
+
+// Add task template to board
+func (h *TemplatesHandler) CreateTaskTemplate(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostTaskTemplateDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	tpl, err := h.service.CreateTaskTemplate(boardID, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tpl)
+}
+
+// Get task templates of board
+func (h *TemplatesHandler) GetTaskTemplates(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	templates, err := h.service.GetTaskTemplates(boardID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// Delete task template of board
+func (h *TemplatesHandler) DeleteTaskTemplate(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "templateID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTaskTemplate(boardID, id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create task from template by template_id or by name
+func (h *TemplatesHandler) CreateTaskFromTemplate(w http.ResponseWriter, r *http.Request) {
+	var body dto.PostTaskFromTemplateDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	userID := userIDFromCtx(r)
+	task, err := h.service.TaskFromTemplate(body, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	taskRet, err := h.tasks.SetTask(*task, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(taskRet)
+}
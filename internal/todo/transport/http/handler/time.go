@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+type TimeHandler struct {
+	service TimeHandlerer
+	logger  *zap.Logger
+}
+
+type TimeHandlerer interface {
+	StartTimer(taskID uint, body dto.PostTimerDto, userID uint) (*dto.TimerStartedDto, error)
+	StopTimer(userID uint) (*dto.TimeEntryDto, error)
+	GetTimer(userID uint) (*dto.TimeEntryDto, error)
+	GetTimeEntries(taskID uint, userID uint) ([]dto.TimeEntryDto, error)
+	AddTimeEntry(taskID uint, body dto.PostTimeEntryDto, userID uint) (*dto.TimeEntryDto, error)
+	DeleteTimeEntry(id uint, userID uint) error
+	GetTimeReport(filter dto.TimeReportFilterDto, userID uint) (*dto.TimeReportDto, error)
+}
+
+func NewTimeHandler(t TimeHandlerer, logger *zap.Logger) TimeHandler {
+	return TimeHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Start timer on task, body with note is optional
+func (h *TimeHandler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostTimerDto
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+	}
+
+	started, err := h.service.StartTimer(taskID, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(started)
+}
+
+// Stop running timer of user
+func (h *TimeHandler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	stopped, err := h.service.StopTimer(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stopped)
+}
+
+// Get running timer of user, 204 when there is none
+func (h *TimeHandler) GetTimer(w http.ResponseWriter, r *http.Request) {
+	running, err := h.service.GetTimer(userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if running == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(running)
+}
+
+// Get time entries of task
+func (h *TimeHandler) GetTimeEntries(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetTimeEntries(taskID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// Add time entry to task manually
+func (h *TimeHandler) AddTimeEntry(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostTimeEntryDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.service.AddTimeEntry(taskID, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// Delete own time entry
+func (h *TimeHandler) DeleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTimeEntry(id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Get time report: ?from=&to=&tz=&group_by=user,board,day&board_id=, ?format=csv for csv file
+func (h *TimeHandler) GetTimeReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := dto.TimeReportFilterDto{
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		GroupBy:  query.Get("group_by"),
+		Timezone: query.Get("tz"),
+	}
+
+	if boardIDStr := query.Get("board_id"); boardIDStr != "" {
+		boardID, err := strconv.ParseUint(boardIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid board_id", http.StatusBadRequest)
+			return
+		}
+		filter.BoardId = uint(boardID)
+	}
+
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "Invalid format, expected json or csv", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetTimeReport(filter, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format == "csv" {
+		h.writeTimeReportCSV(w, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// csv has columns of grouped fields, seconds and hours
+func (h *TimeHandler) writeTimeReportCSV(w http.ResponseWriter, report *dto.TimeReportDto) {
+	user := slices.Contains(report.GroupBy, models.TimeGroupUser)
+	board := slices.Contains(report.GroupBy, models.TimeGroupBoard)
+	day := slices.Contains(report.GroupBy, models.TimeGroupDay)
+
+	var header []string
+	if user {
+		header = append(header, "user_id", "username")
+	}
+	if board {
+		header = append(header, "board_id", "board_name")
+	}
+	if day {
+		header = append(header, "day")
+	}
+	header = append(header, "seconds", "hours")
+
+	filename := fmt.Sprintf("time_%s_%s.csv", report.From.Format("20060102"), report.To.Format("20060102"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		h.logger.Error("write time report csv", zap.Error(err))
+		return
+	}
+
+	for _, row := range report.Rows {
+		var record []string
+		if user {
+			record = append(record, strconv.FormatUint(uint64(row.UserId), 10), row.Username)
+		}
+		if board {
+			record = append(record, strconv.FormatUint(uint64(row.BoardId), 10), row.BoardName)
+		}
+		if day {
+			record = append(record, row.Day)
+		}
+		record = append(record, strconv.FormatInt(row.Seconds, 10), strconv.FormatFloat(float64(row.Seconds)/3600, 'f', 2, 64))
+		if err := cw.Write(csvCells(record)); err != nil {
+			h.logger.Error("write time report csv", zap.Error(err))
+			return
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		h.logger.Error("write time report csv", zap.Error(err))
+	}
+}
+
+// csvCells keeps spreadsheets from running user text as formula: cell starting
+// with =, +, -, @, tab or carriage return gets leading quote, as OWASP advises for CSV injection
+func csvCells(record []string) []string {
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+
+	return record
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+
+	"go.uber.org/zap"
+)
+
+type TransfersHandler struct {
+	service TransfersHandlerer
+	logger  *zap.Logger
+}
+
+type TransfersHandlerer interface {
+	MoveTask(id uint, body dto.PostTaskTransferDto, userID uint) (*models.Task, error)
+	CopyTask(id uint, body dto.PostTaskTransferDto, userID uint) (*models.Task, error)
+	CloneBoard(id uint, body dto.PostBoardCloneDto, userID uint) (*models.Board, error)
+	GetTransfers(id uint, userID uint) ([]models.Transfer, error)
+}
+
+func NewTransfersHandler(t TransfersHandlerer, logger *zap.Logger) TransfersHandler {
+	return TransfersHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Move task to another board
+func (h *TransfersHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostTaskTransferDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.service.MoveTask(id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
+}
+
+// Copy task to board
+func (h *TransfersHandler) CopyTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostTaskTransferDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.service.CopyTask(id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(task)
+}
+
+// Clone board, body with options is optional
+func (h *TransfersHandler) CloneBoard(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostBoardCloneDto
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid input", http.StatusBadRequest)
+			return
+		}
+	}
+
+	board, err := h.service.CloneBoard(id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(board)
+}
+
+// Get moves and copies of task
+func (h *TransfersHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	transfers, err := h.service.GetTransfers(id, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transfers)
+}
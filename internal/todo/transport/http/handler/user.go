@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 	"todo/internal/todo/dto"
 	"todo/internal/todo/models"
 	"todo/internal/todo/utils/tokens"
 
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
@@ -22,7 +26,12 @@ type UserHandlerer interface {
 	WriteRefreshToken(userId uint, refreshTokenValue string) error
 	GetAuthUser(id uint) (*models.UserToken, error)
 	UserLogout(id uint) error
-	AddChatID(tgName string, chatID int64) error
+	CreateLinkCode(userID uint) (*dto.TgLinkCodeDto, error)
+	LinkTelegram(body dto.PostTgLinkDto) error
+	UnlinkTelegram(userID uint) error
+	GetUserIDByTg(tgUserID int64) (uint, error)
+	GetUser(id uint) (*dto.UserDto, error)
+	ActivateTelegram(userID uint, chatID int64) (bool, error)
 }
 
 func NewUserHandler(t UserHandlerer, logger *zap.Logger) UserHandler {
@@ -115,38 +124,29 @@ func (h *UserHandler) AuthorizateUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(accessTokenValue)
 }
 
-// Get active user
+// Get active user with state of telegram link
 func (h *UserHandler) GetAuthUser(w http.ResponseWriter, r *http.Request) {
-	var user dto.PostUserDto
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
-		return
-	}
-
-	if user.Username == "" {
-		http.Error(w, "username cannot be empty", http.StatusBadRequest)
-		return
-	}
+	userID := userIDFromCtx(r)
 
-	userID, err := h.service.AuthorizateUser(user)
+	token, err := h.service.GetAuthUser(userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	user2, err := h.service.GetAuthUser(*userID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if token == nil {
+		http.Error(w, "No active user", http.StatusUnauthorized)
 		return
 	}
 
-	if user2 == nil {
-		http.Error(w, "No active user", http.StatusUnauthorized)
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(userID)
+	json.NewEncoder(w).Encode(user)
 }
 
 // Logout user
@@ -200,18 +200,90 @@ func (h *UserHandler) UserLogout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *UserHandler) AddChatID(w http.ResponseWriter, r *http.Request) {
-	var user dto.PostUserDto
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+// Create one-time code for linking telegram
+func (h *UserHandler) CreateTelegramLink(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromCtx(r)
+
+	link, err := h.service.CreateLinkCode(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+// Unlink telegram from current user
+func (h *UserHandler) DeleteTelegramLink(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromCtx(r)
+
+	if err := h.service.UnlinkTelegram(userID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Link telegram account by code, called by tg service on /start <code>
+func (h *UserHandler) LinkTelegram(w http.ResponseWriter, r *http.Request) {
+	var link dto.PostTgLinkDto
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	err := h.service.AddChatID(user.TgName, user.ChatID)
+	err := h.service.LinkTelegram(link)
+	if errors.Is(err, models.ErrLinkCodeInvalid) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		http.Error(w, "No tg user", http.StatusUnauthorized)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
+
+// Activate telegram link again, called by tg service on /start of linked user
+func (h *UserHandler) ActivateTelegram(w http.ResponseWriter, r *http.Request) {
+	var body dto.TgActivateDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	reactivated, err := h.service.ActivateTelegram(userIDFromCtx(r), body.ChatID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.TgActivatedDto{Reactivated: reactivated})
+}
+
+// middleware for routes called by the bot on behalf of telegram user,
+// puts id of linked user to context like JWT does
+func (h *UserHandler) TgUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tgUserID, err := strconv.ParseInt(chi.URLParam(r, "tgUserID"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid telegram user ID", http.StatusBadRequest)
+			return
+		}
+
+		userID, err := h.service.GetUserIDByTg(tgUserID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user_id", userID)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo/internal/todo/dto"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type WebhooksHandler struct {
+	service WebhooksHandlerer
+	logger  *zap.Logger
+}
+
+type WebhooksHandlerer interface {
+	CreateWebhook(boardID uint, body dto.PostWebhookDto, userID uint) (*dto.WebhookDto, error)
+	GetWebhooks(boardID uint, userID uint) ([]dto.WebhookDto, error)
+	UpdateWebhook(boardID uint, id uint, body dto.PostWebhookDto, userID uint) (*dto.WebhookDto, error)
+	DeleteWebhook(boardID uint, id uint, userID uint) error
+	GetDeliveries(boardID uint, id uint, userID uint) ([]dto.WebhookDeliveryDto, error)
+	Redeliver(boardID uint, id uint, deliveryID uint, userID uint) (*dto.WebhookDeliveryDto, error)
+}
+
+func NewWebhooksHandler(t WebhooksHandlerer, logger *zap.Logger) WebhooksHandler {
+	return WebhooksHandler{
+		service: t,
+		logger:  logger,
+	}
+}
+
+// Add webhook to board, secret is returned only here
+func (h *WebhooksHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var body dto.PostWebhookDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := h.service.CreateWebhook(boardID, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// Get webhooks of board
+func (h *WebhooksHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	hooks, err := h.service.GetWebhooks(boardID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// Replace url, events and active flag of webhook, empty secret keeps the current one
+func (h *WebhooksHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "hookID")
+	if !ok {
+		return
+	}
+
+	var body dto.PostWebhookDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := h.service.UpdateWebhook(boardID, id, body, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// Delete webhook with its delivery log
+func (h *WebhooksHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "hookID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(boardID, id, userIDFromCtx(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Get delivery log of webhook
+func (h *WebhooksHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "hookID")
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.service.GetDeliveries(boardID, id, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// Queue delivery again
+func (h *WebhooksHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+	id, ok := urlUint(w, r, "hookID")
+	if !ok {
+		return
+	}
+	deliveryID, ok := urlUint(w, r, "deliveryID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Redeliver(boardID, id, deliveryID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// parse id from url, writes 400 when it is invalid
+func urlUint(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	id, err := strconv.ParseUint(chi.URLParam(r, name), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return uint(id), true
+}
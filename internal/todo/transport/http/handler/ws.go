@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"todo/internal/todo/dto"
+	"todo/internal/todo/models"
+	"todo/internal/todo/services"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	wsWriteWait     = 10 * time.Second
+	wsPingPeriod    = 25 * time.Second
+	wsPongWait      = 60 * time.Second // connection is closed without pong
+	wsMaxMessage    = 64 << 10
+	wsSendBuffer    = 32
+	wsRate          = 5 // commands per second
+	wsBurst         = 20
+	wsMaxViolations = 20 // connection is closed after so many rejected commands
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// access is checked by token, not cookies, so any origin is allowed
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsConn is one collaboration connection, commands are read in its own goroutine
+// and all writes go through the goroutine of the request
+type wsConn struct {
+	conn      *websocket.Conn
+	sub       *services.Subscription
+	tasks     TasksHandlerer
+	transfers TransfersHandlerer
+	send      chan any
+	done      chan struct{} // closed when reader stops
+	stopped   chan struct{} // closed when writer stops
+	limiter   tokenBucket
+	logger    *zap.Logger
+}
+
+// Open collaboration channel of board: events, presence of viewers and commands with acks.
+// ?last_event_id= resumes events after reconnect as in SSE
+func (h *EventsHandler) BoardWs(w http.ResponseWriter, r *http.Request) {
+	boardID, ok := urlUint(w, r, "id")
+	if !ok {
+		return
+	}
+
+	lastIDStr := r.URL.Query().Get("last_event_id")
+	var lastID uint64
+	if lastIDStr != "" {
+		var err error
+		lastID, err = strconv.ParseUint(lastIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid last_event_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// membership is checked before upgrade, so client gets usual 403
+	sub, err := h.service.Subscribe(boardID, userIDFromCtx(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer h.service.Unsubscribe(sub)
+
+	var replay []models.BoardEvent
+	reset := false
+	if lastIDStr != "" {
+		replay, reset, err = h.service.Replay(boardID, uint(lastID))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader has already written the error
+		return
+	}
+	defer conn.Close()
+
+	if err := h.service.Join(sub); err != nil {
+		h.logger.Error("join board", zap.Uint("board_id", boardID), zap.Error(err))
+		return
+	}
+
+	c := &wsConn{
+		conn:      conn,
+		sub:       sub,
+		tasks:     h.tasks,
+		transfers: h.transfers,
+		send:      make(chan any, wsSendBuffer),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		limiter:   tokenBucket{tokens: wsBurst, last: time.Now()},
+		logger:    h.logger,
+	}
+
+	go c.readLoop()
+	c.writeLoop(replay, reset, uint(lastID))
+}
+
+func (c *wsConn) writeLoop(replay []models.BoardEvent, reset bool, sent uint) {
+	defer close(c.stopped)
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	// client has to reload the board, some events are no longer in the log
+	if reset {
+		if err := c.write(map[string]string{"type": "reset"}); err != nil {
+			return
+		}
+	}
+
+	for _, event := range replay {
+		if err := c.write(eventMessage(event)); err != nil {
+			return
+		}
+		sent = event.ID
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			if err := c.write(msg); err != nil {
+				return
+			}
+		case event, ok := <-c.sub.Events:
+			if !ok {
+				// dropped by service, client reconnects with last_event_id
+				c.close(websocket.CloseGoingAway, "resubscribe required")
+				return
+			}
+			// already replayed, ids of board follow commit order, so later events are never skipped
+			if event.ID <= sent {
+				continue
+			}
+			if err := c.write(eventMessage(event)); err != nil {
+				return
+			}
+			sent = event.ID
+		case viewers := <-c.sub.Presence:
+			if err := c.write(presenceMessage(viewers)); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConn) readLoop() {
+	defer close(c.done)
+
+	c.conn.SetReadLimit(wsMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	violations := 0
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				c.logger.Info("websocket closed", zap.Error(err))
+			}
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		// limit is checked before parsing, so flood of messages costs nothing to parse
+		if !c.limiter.allow(time.Now()) {
+			violations++
+			if violations >= wsMaxViolations {
+				c.close(websocket.ClosePolicyViolation, "rate limit exceeded")
+				return
+			}
+			c.reply(wsError("", errors.New("rate limit exceeded")))
+			continue
+		}
+
+		var cmd dto.WsCommandDto
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			c.reply(wsError("", errors.New("invalid message")))
+			continue
+		}
+
+		if !c.reply(c.handle(cmd)) {
+			return
+		}
+	}
+}
+
+// handle runs command of client and returns reply for it
+func (c *wsConn) handle(cmd dto.WsCommandDto) any {
+	userID := c.sub.UserId
+
+	switch cmd.Type {
+	case "ping":
+		return map[string]string{"type": "pong", "id": cmd.Id}
+	case "task.create":
+		var body dto.PostTaskDto
+		if err := json.Unmarshal(cmd.Payload, &body); err != nil {
+			return wsError(cmd.Id, errors.New("invalid payload"))
+		}
+
+		// task is created on board of connection
+		body.BoardId = strconv.FormatUint(uint64(c.sub.BoardId), 10)
+		if body.Title == "" {
+			return wsError(cmd.Id, errors.New("title is required"))
+		}
+
+		task, err := c.tasks.SetTask(body, userID)
+		if err != nil {
+			return wsError(cmd.Id, err)
+		}
+
+		return wsAck(cmd.Id, task)
+	case "task.move":
+		var body dto.WsMoveTaskDto
+		if err := json.Unmarshal(cmd.Payload, &body); err != nil || body.TaskId == 0 || body.StatusId == 0 {
+			return wsError(cmd.Id, errors.New("task_id and status_id are required"))
+		}
+
+		task, err := c.moveTask(body)
+		if err != nil {
+			return wsError(cmd.Id, err)
+		}
+
+		return wsAck(cmd.Id, task)
+	}
+
+	return wsError(cmd.Id, errors.New("unknown command"))
+}
+
+// moveTask changes status of task on board of connection, and its board when it is given
+func (c *wsConn) moveTask(body dto.WsMoveTaskDto) (*models.Task, error) {
+	userID := c.sub.UserId
+
+	task, err := c.tasks.GetTask(body.TaskId, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil || task.BoardId != c.sub.BoardId {
+		return nil, models.ErrNotFound
+	}
+
+	if body.BoardId == 0 || body.BoardId == task.BoardId {
+		return c.tasks.UpdateTaskStatus(task.ID, body.StatusId, userID)
+	}
+
+	// on another board the task gets the given status, fields and labels are mapped by names
+	transfer := dto.PostTaskTransferDto{
+		BoardId:  body.BoardId,
+		Statuses: map[uint]uint{task.StatusId: body.StatusId},
+	}
+
+	return c.transfers.MoveTask(task.ID, transfer, userID)
+}
+
+// reply queues message for writer, false when connection is closing
+func (c *wsConn) reply(msg any) bool {
+	select {
+	case c.send <- msg:
+		return true
+	case <-c.stopped:
+		return false
+	}
+}
+
+func (c *wsConn) write(msg any) error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.conn.WriteJSON(msg)
+}
+
+func (c *wsConn) close(code int, text string) {
+	msg := websocket.FormatCloseMessage(code, text)
+	c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+}
+
+func wsAck(id string, task *models.Task) dto.WsAckDto {
+	ack := dto.WsAckDto{Type: "ack", Id: id, Ok: true}
+	if task != nil {
+		ack.Task = &dto.BoardTaskDto{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			BoardId:     task.BoardId,
+			StatusId:    task.StatusId,
+			UserId:      task.UserId,
+			Priority:    task.Priority,
+			DueAt:       task.DueAt,
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+		}
+	}
+
+	return ack
+}
+
+func wsError(id string, err error) dto.WsAckDto {
+	return dto.WsAckDto{Type: "ack", Id: id, Ok: false, Error: err.Error()}
+}
+
+func eventMessage(event models.BoardEvent) dto.WsEventDto {
+	return dto.WsEventDto{Type: "event", Id: event.ID, Event: event.Event, Data: event.Payload}
+}
+
+func presenceMessage(viewers []models.Viewer) dto.WsPresenceDto {
+	users := make([]dto.WsViewerDto, 0, len(viewers))
+	for _, viewer := range viewers {
+		users = append(users, dto.WsViewerDto{UserId: viewer.UserId, Username: viewer.Username})
+	}
+
+	return dto.WsPresenceDto{Type: "presence", Users: users}
+}
+
+// tokenBucket limits commands of one connection, it is used only by reader
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens = min(wsBurst, b.tokens+now.Sub(b.last).Seconds()*wsRate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
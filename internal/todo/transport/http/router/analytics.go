@@ -0,0 +1,23 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AnalyticsRouter struct{}
+
+type AnalyticsHandler interface {
+	GetBoardAnalytics(w http.ResponseWriter, r *http.Request)
+}
+
+func NewAnalyticsRouter() *AnalyticsRouter {
+	return &AnalyticsRouter{}
+}
+
+func (b *AnalyticsRouter) AnalyticsRoutes(r chi.Router, h AnalyticsHandler) {
+	// Routes for analytics of board, only for board members
+	r.With(middleware.JWT).Get("/api/boards/{id}/analytics", h.GetBoardAnalytics) // lead and cycle time, throughput, cumulative flow, burndown
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AttachmentsRouter struct{}
+
+type AttachmentsHandler interface {
+	GetAttachments(w http.ResponseWriter, r *http.Request)
+	Upload(w http.ResponseWriter, r *http.Request)
+	Download(w http.ResponseWriter, r *http.Request)
+	DeleteAttachment(w http.ResponseWriter, r *http.Request)
+}
+
+func NewAttachmentsRouter() *AttachmentsRouter {
+	return &AttachmentsRouter{}
+}
+
+func (a *AttachmentsRouter) AttachmentsRoutes(r chi.Router, h AttachmentsHandler) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.JWT)
+		r.Get("/api/tasks/{id}/attachments", h.GetAttachments)                     // attachments of task
+		r.Post("/api/tasks/{id}/attachments", h.Upload)                            // upload file, multipart field "file"
+		r.Get("/api/tasks/{id}/attachments/{attachmentID}", h.Download)            // download file
+		r.Delete("/api/tasks/{id}/attachments/{attachmentID}", h.DeleteAttachment) // delete, only uploader or board owner
+	})
+}
@@ -16,6 +16,9 @@ type BoardsHandler interface {
 	UpdateBoard(w http.ResponseWriter, r *http.Request)
 	DeleteBoard(w http.ResponseWriter, r *http.Request)
 	User2Board(w http.ResponseWriter, r *http.Request)
+	LinkChat(w http.ResponseWriter, r *http.Request)
+	UnlinkChat(w http.ResponseWriter, r *http.Request)
+	RemoveUser(w http.ResponseWriter, r *http.Request)
 }
 
 func NewBoardsRouter() *BoardsRouter {
@@ -25,12 +28,14 @@ func NewBoardsRouter() *BoardsRouter {
 func (b *BoardsRouter) BoardsRoutes(r chi.Router, h BoardsHandler) {
 	// Routes for boards
 	r.Route("/api/boards", func(r chi.Router) {
-		r.Use(middleware.JWT)            // need jwt for all methods
-		r.Get("/", h.GetAllBoards)       // get all boards
-		r.Get("/{id}", h.GetBoard)       // get board with id
-		r.Post("/", h.SetBoard)          // add new board
-		r.Put("/{id}", h.UpdateBoard)    // update board
-		r.Delete("/{id}", h.DeleteBoard) // delete board
-		r.Post("/{id}", h.User2Board)    // add user to board
+		r.Use(middleware.JWT)                          // need jwt for all methods
+		r.Get("/", h.GetAllBoards)                     // get all boards
+		r.Get("/{id}", h.GetBoard)                     // get board with id
+		r.Post("/", h.SetBoard)                        // add new board
+		r.Put("/{id}", h.UpdateBoard)                  // update board
+		r.Delete("/{id}", h.DeleteBoard)               // delete board
+		r.Post("/{id}", h.User2Board)                  // add user to board
+		r.Delete("/{id}/users/{userID}", h.RemoveUser) // remove user from board or leave it
+		r.Delete("/{id}/chat", h.UnlinkChat)           // unlink telegram group, only for owner
 	})
 }
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type BulkRouter struct{}
+
+type BulkHandler interface {
+	ApplyBulk(w http.ResponseWriter, r *http.Request)
+}
+
+func NewBulkRouter() *BulkRouter {
+	return &BulkRouter{}
+}
+
+func (b *BulkRouter) BulkRoutes(r chi.Router, h BulkHandler) {
+	r.With(middleware.JWT).Post("/api/tasks/bulk", h.ApplyBulk) // many operations on tasks in one request
+}
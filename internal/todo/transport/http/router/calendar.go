@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CalendarRouter struct{}
+
+type CalendarHandler interface {
+	GetLink(w http.ResponseWriter, r *http.Request)
+	RegenerateToken(w http.ResponseWriter, r *http.Request)
+	DeleteToken(w http.ResponseWriter, r *http.Request)
+	Feed(w http.ResponseWriter, r *http.Request)
+}
+
+func NewCalendarRouter() *CalendarRouter {
+	return &CalendarRouter{}
+}
+
+func (c *CalendarRouter) CalendarRoutes(r chi.Router, h CalendarHandler) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.JWT)
+		r.Get("/api/user/calendar", h.GetLink)                // url of calendar feed
+		r.Post("/api/user/calendar/token", h.RegenerateToken) // new url, the old one stops working
+		r.Delete("/api/user/calendar", h.DeleteToken)         // disable feed
+	})
+
+	r.Get("/calendar/{token}.ics", h.Feed) // ics feed for calendar apps, secret token instead of jwt
+}
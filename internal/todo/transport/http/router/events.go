@@ -0,0 +1,33 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type EventsRouter struct{}
+
+type EventsHandler interface {
+	BoardEvents(w http.ResponseWriter, r *http.Request)
+	BoardWs(w http.ResponseWriter, r *http.Request)
+}
+
+func NewEventsRouter() *EventsRouter {
+	return &EventsRouter{}
+}
+
+func (b *EventsRouter) EventsRoutes(r chi.Router, h EventsHandler) {
+	// Routes for live updates of board, only for board members.
+	// Token may be passed in ?access_token=, EventSource and WebSocket can't set headers
+	r.Route("/api/boards/{id}/events", func(r chi.Router) {
+		r.Use(middleware.JWTQuery) // need jwt for all methods
+		r.Get("/", h.BoardEvents)  // stream events as SSE
+	})
+
+	r.Route("/api/boards/{id}/ws", func(r chi.Router) {
+		r.Use(middleware.JWTQuery) // need jwt for all methods
+		r.Get("/", h.BoardWs)      // collaboration channel
+	})
+}
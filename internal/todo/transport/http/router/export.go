@@ -0,0 +1,24 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ExportRouter struct{}
+
+type ExportHandler interface {
+	ExportBoard(w http.ResponseWriter, r *http.Request)
+	ExportAll(w http.ResponseWriter, r *http.Request)
+}
+
+func NewExportRouter() *ExportRouter {
+	return &ExportRouter{}
+}
+
+func (e *ExportRouter) ExportRoutes(r chi.Router, h ExportHandler) {
+	r.With(middleware.JWT).Get("/api/boards/{id}/export", h.ExportBoard) // board with tasks as json, csv or markdown
+	r.With(middleware.JWT).Get("/api/export", h.ExportAll)               // all boards of user
+}
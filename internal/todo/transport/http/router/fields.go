@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type FieldsRouter struct{}
+
+type FieldsHandler interface {
+	GetFields(w http.ResponseWriter, r *http.Request)
+	CreateField(w http.ResponseWriter, r *http.Request)
+	UpdateField(w http.ResponseWriter, r *http.Request)
+	DeleteField(w http.ResponseWriter, r *http.Request)
+}
+
+func NewFieldsRouter() *FieldsRouter {
+	return &FieldsRouter{}
+}
+
+func (b *FieldsRouter) FieldsRoutes(r chi.Router, h FieldsHandler) {
+	// Routes for custom fields of board, members can read them and owner changes
+	r.Route("/api/boards/{id}/fields", func(r chi.Router) {
+		r.Use(middleware.JWT)                 // need jwt for all methods
+		r.Get("/", h.GetFields)               // get fields of board
+		r.Post("/", h.CreateField)            // add field
+		r.Put("/{fieldID}", h.UpdateField)    // update field
+		r.Delete("/{fieldID}", h.DeleteField) // delete field with its values
+	})
+}
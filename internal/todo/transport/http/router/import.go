@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ImportRouter struct{}
+
+type ImportHandler interface {
+	Import(w http.ResponseWriter, r *http.Request)
+}
+
+func NewImportRouter() *ImportRouter {
+	return &ImportRouter{}
+}
+
+func (i *ImportRouter) ImportRoutes(r chi.Router, h ImportHandler) {
+	r.With(middleware.JWT).Post("/api/import", h.Import) // boards from Trello or Todoist export, with dry run report
+}
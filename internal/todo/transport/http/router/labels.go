@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type LabelsRouter struct{}
+
+type LabelsHandler interface {
+	GetLabels(w http.ResponseWriter, r *http.Request)
+	CreateLabel(w http.ResponseWriter, r *http.Request)
+	UpdateLabel(w http.ResponseWriter, r *http.Request)
+	DeleteLabel(w http.ResponseWriter, r *http.Request)
+}
+
+func NewLabelsRouter() *LabelsRouter {
+	return &LabelsRouter{}
+}
+
+func (b *LabelsRouter) LabelsRoutes(r chi.Router, h LabelsHandler) {
+	// Routes for labels of board, members can read them and owner changes
+	r.Route("/api/boards/{id}/labels", func(r chi.Router) {
+		r.Use(middleware.JWT)                 // need jwt for all methods
+		r.Get("/", h.GetLabels)               // get labels of board
+		r.Post("/", h.CreateLabel)            // add label
+		r.Put("/{labelID}", h.UpdateLabel)    // rename label or change color
+		r.Delete("/{labelID}", h.DeleteLabel) // delete label from board and tasks
+	})
+}
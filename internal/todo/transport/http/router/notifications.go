@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationsRouter struct{}
+
+type NotificationsHandler interface {
+	GetSettings(w http.ResponseWriter, r *http.Request)
+	UpdateSettings(w http.ResponseWriter, r *http.Request)
+	SendEmailCode(w http.ResponseWriter, r *http.Request)
+	ConfirmEmail(w http.ResponseWriter, r *http.Request)
+}
+
+func NewNotificationsRouter() *NotificationsRouter {
+	return &NotificationsRouter{}
+}
+
+func (b *NotificationsRouter) NotificationsRoutes(r chi.Router, h NotificationsHandler) {
+	// Routes for notification settings of user
+	r.Route("/api/user/notifications", func(r chi.Router) {
+		r.Use(middleware.JWT)                    // need jwt for all methods
+		r.Get("/", h.GetSettings)                // get settings
+		r.Put("/", h.UpdateSettings)             // replace settings
+		r.Post("/email/code", h.SendEmailCode)   // send new confirmation code to email
+		r.Post("/email/confirm", h.ConfirmEmail) // confirm email by code
+	})
+}
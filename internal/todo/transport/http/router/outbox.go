@@ -0,0 +1,29 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type OutboxRouter struct{}
+
+type OutboxHandler interface {
+	GetOutbox(w http.ResponseWriter, r *http.Request)
+	ReplayMessage(w http.ResponseWriter, r *http.Request)
+}
+
+func NewOutboxRouter() *OutboxRouter {
+	return &OutboxRouter{}
+}
+
+func (b *OutboxRouter) OutboxRoutes(r chi.Router, h OutboxHandler) {
+	// Routes for notifications outbox, admins only
+	r.Route("/api/admin/outbox", func(r chi.Router) {
+		r.Use(middleware.JWT)                   // need jwt for all methods
+		r.Use(middleware.Admin)                 // and user from ADMIN_IDS
+		r.Get("/", h.GetOutbox)                 // get messages, filter by ?status=
+		r.Post("/{id}/replay", h.ReplayMessage) // send message again
+	})
+}
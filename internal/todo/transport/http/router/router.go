@@ -12,6 +12,23 @@ type Router struct {
 	Statuses StatusesRouter
 	Tasks    TasksRouter
 	User     UserRouter
+	Outbox   OutboxRouter
+	Tg       TgRouter
+
+	Notifications NotificationsRouter
+	Webhooks      WebhooksRouter
+	Events        EventsRouter
+	Time          TimeRouter
+	Analytics     AnalyticsRouter
+	Fields        FieldsRouter
+	Labels        LabelsRouter
+	Templates     TemplatesRouter
+	Transfers     TransfersRouter
+	Bulk          BulkRouter
+	Export        ExportRouter
+	Import        ImportRouter
+	Calendar      CalendarRouter
+	Attachments   AttachmentsRouter
 }
 
 func New(h *handler.TodoHandler) http.Handler {
@@ -22,12 +39,45 @@ func New(h *handler.TodoHandler) http.Handler {
 		Statuses: *NewStatusesRouter(),
 		Tasks:    *NewTasksRouter(),
 		User:     *NewUserRouter(),
+		Outbox:   *NewOutboxRouter(),
+		Tg:       *NewTgRouter(),
+
+		Notifications: *NewNotificationsRouter(),
+		Webhooks:      *NewWebhooksRouter(),
+		Events:        *NewEventsRouter(),
+		Time:          *NewTimeRouter(),
+		Analytics:     *NewAnalyticsRouter(),
+		Fields:        *NewFieldsRouter(),
+		Labels:        *NewLabelsRouter(),
+		Templates:     *NewTemplatesRouter(),
+		Transfers:     *NewTransfersRouter(),
+		Bulk:          *NewBulkRouter(),
+		Export:        *NewExportRouter(),
+		Import:        *NewImportRouter(),
+		Calendar:      *NewCalendarRouter(),
+		Attachments:   *NewAttachmentsRouter(),
 	}
 
 	router.Boards.BoardsRoutes(r, &h.BoardsHandler)
 	router.Statuses.StatusesRoutes(r, &h.StatusesHandler)
 	router.Tasks.TasksRoutes(r, &h.TasksHandler)
 	router.User.UserRoutes(r, &h.UserHandler)
+	router.Outbox.OutboxRoutes(r, &h.OutboxHandler)
+	router.Notifications.NotificationsRoutes(r, &h.NotificationsHandler)
+	router.Webhooks.WebhooksRoutes(r, &h.WebhooksHandler)
+	router.Events.EventsRoutes(r, &h.EventsHandler)
+	router.Time.TimeRoutes(r, &h.TimeHandler)
+	router.Analytics.AnalyticsRoutes(r, &h.AnalyticsHandler)
+	router.Fields.FieldsRoutes(r, &h.FieldsHandler)
+	router.Labels.LabelsRoutes(r, &h.LabelsHandler)
+	router.Templates.TemplatesRoutes(r, &h.TemplatesHandler)
+	router.Transfers.TransfersRoutes(r, &h.TransfersHandler)
+	router.Bulk.BulkRoutes(r, &h.BulkHandler)
+	router.Export.ExportRoutes(r, &h.ExportHandler)
+	router.Import.ImportRoutes(r, &h.ImportHandler)
+	router.Calendar.CalendarRoutes(r, &h.CalendarHandler)
+	router.Attachments.AttachmentsRoutes(r, &h.AttachmentsHandler)
+	router.Tg.TgRoutes(r, &h.UserHandler, &h.BoardsHandler, &h.TasksHandler, &h.StatusesHandler, &h.NotificationsHandler, &h.TimeHandler, &h.TemplatesHandler, &h.AttachmentsHandler)
 
 	return r
 }
@@ -11,6 +11,7 @@ type StatusesRouter struct{}
 
 type StatusesHandler interface {
 	SetStatus(w http.ResponseWriter, r *http.Request)
+	GetAllStatuses(w http.ResponseWriter, r *http.Request)
 	DeleteStatus(w http.ResponseWriter, r *http.Request)
 }
 
@@ -22,6 +23,7 @@ func (b *StatusesRouter) StatusesRoutes(r chi.Router, h StatusesHandler) {
 	// Routes for statuses
 	r.Route("/api/status", func(r chi.Router) {
 		r.Use(middleware.JWT)         // need jwt for all methods
+		r.Get("/", h.GetAllStatuses)  // get all statuses
 		r.Post("/", h.SetStatus)      // add new status
 		r.Delete("/", h.DeleteStatus) // delete status
 	})
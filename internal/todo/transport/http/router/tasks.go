@@ -14,7 +14,12 @@ type TasksHandler interface {
 	GetAllTasks(w http.ResponseWriter, r *http.Request)
 	GetTask(w http.ResponseWriter, r *http.Request)
 	UpdateTask(w http.ResponseWriter, r *http.Request)
+	UpdateTaskStatus(w http.ResponseWriter, r *http.Request)
+	SnoozeTask(w http.ResponseWriter, r *http.Request)
+	AddComment(w http.ResponseWriter, r *http.Request)
+	GetComments(w http.ResponseWriter, r *http.Request)
 	DeleteTask(w http.ResponseWriter, r *http.Request)
+	GetMyTasks(w http.ResponseWriter, r *http.Request)
 	SendAllTasks(w http.ResponseWriter, r *http.Request)
 }
 
@@ -25,13 +30,17 @@ func NewTasksRouter() *TasksRouter {
 func (b *TasksRouter) TasksRoutes(r chi.Router, h TasksHandler) {
 	// Routes for tasks
 	r.Route("/api/tasks", func(r chi.Router) {
-		r.Use(middleware.JWT)           // need jwt for all methods
-		r.Get("/", h.GetAllTasks)       // get all tasks
-		r.Get("/{id}", h.GetTask)       // get task with id
-		r.Post("/", h.SetTask)          // add new task
-		r.Put("/{id}", h.UpdateTask)    // update task
-		r.Delete("/{id}", h.DeleteTask) // delete task
+		r.Use(middleware.JWT)                     // need jwt for all methods
+		r.Get("/", h.GetAllTasks)                 // get all tasks
+		r.Get("/{id}", h.GetTask)                 // get task with id
+		r.Post("/", h.SetTask)                    // add new task
+		r.Put("/{id}", h.UpdateTask)              // update task
+		r.Put("/{id}/status", h.UpdateTaskStatus) // change only status
+		r.Post("/{id}/snooze", h.SnoozeTask)      // remind about task later
+		r.Get("/{id}/comments", h.GetComments)    // get comments of task
+		r.Post("/{id}/comments", h.AddComment)    // add comment to task
+		r.Delete("/{id}", h.DeleteTask)           // delete task
 	})
 
-	r.Post("/sendtasks", h.SendAllTasks)
+	r.With(middleware.Signed).Post("/sendtasks", h.SendAllTasks) // called by tg service
 }
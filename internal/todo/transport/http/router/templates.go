@@ -0,0 +1,48 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TemplatesRouter struct{}
+
+type TemplatesHandler interface {
+	SaveBoardTemplate(w http.ResponseWriter, r *http.Request)
+	GetBoardTemplates(w http.ResponseWriter, r *http.Request)
+	GetBoardTemplate(w http.ResponseWriter, r *http.Request)
+	DeleteBoardTemplate(w http.ResponseWriter, r *http.Request)
+	CreateBoardFromTemplate(w http.ResponseWriter, r *http.Request)
+	CreateTaskTemplate(w http.ResponseWriter, r *http.Request)
+	GetTaskTemplates(w http.ResponseWriter, r *http.Request)
+	DeleteTaskTemplate(w http.ResponseWriter, r *http.Request)
+	CreateTaskFromTemplate(w http.ResponseWriter, r *http.Request)
+}
+
+func NewTemplatesRouter() *TemplatesRouter {
+	return &TemplatesRouter{}
+}
+
+func (b *TemplatesRouter) TemplatesRoutes(r chi.Router, h TemplatesHandler) {
+	// Routes for board templates of user
+	r.Route("/api/templates", func(r chi.Router) {
+		r.Use(middleware.JWT)                             // need jwt for all methods
+		r.Get("/", h.GetBoardTemplates)                   // get templates of user
+		r.Post("/", h.SaveBoardTemplate)                  // save board as template
+		r.Get("/{id}", h.GetBoardTemplate)                // get template
+		r.Delete("/{id}", h.DeleteBoardTemplate)          // delete template
+		r.Post("/{id}/boards", h.CreateBoardFromTemplate) // create board from template
+	})
+
+	// Routes for task templates of board
+	r.Route("/api/boards/{id}/templates", func(r chi.Router) {
+		r.Use(middleware.JWT)                           // need jwt for all methods
+		r.Get("/", h.GetTaskTemplates)                  // get task templates of board
+		r.Post("/", h.CreateTaskTemplate)               // add task template
+		r.Delete("/{templateID}", h.DeleteTaskTemplate) // delete task template
+	})
+
+	r.With(middleware.JWT).Post("/api/tasks/from-template", h.CreateTaskFromTemplate) // create task from template
+}
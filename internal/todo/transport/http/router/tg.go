@@ -0,0 +1,48 @@
+package router
+
+import (
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TgRouter struct{}
+
+func NewTgRouter() *TgRouter {
+	return &TgRouter{}
+}
+
+func (b *TgRouter) TgRoutes(r chi.Router, u UserHandler, bh BoardsHandler, th TasksHandler, sh StatusesHandler, nh NotificationsHandler, tm TimeHandler, tp TemplatesHandler, at AttachmentsHandler) {
+	// Routes for bot commands, bot acts on behalf of linked telegram user
+	// with the same permissions the user has in api
+	r.Route("/internal/tg/{tgUserID}", func(r chi.Router) {
+		r.Use(middleware.Signed) // request must be signed by tg service
+		r.Use(u.TgUser)          // and telegram user must be linked
+
+		r.Post("/telegram/activate", u.ActivateTelegram) // turn notifications on again after /start
+
+		r.Get("/boards", bh.GetAllBoards)            // get boards of user
+		r.Put("/boards/{id}/chat", bh.LinkChat)      // link group chat to board, only for owner
+		r.Delete("/boards/{id}/chat", bh.UnlinkChat) // unlink group chat
+
+		r.Get("/tasks", th.GetAllTasks)                  // get tasks, ?board_id= filters by board
+		r.Get("/tasks/my", th.GetMyTasks)                // get tasks of user by ?status=, for pages of digest
+		r.Get("/tasks/{id}", th.GetTask)                 // get task with id
+		r.Post("/tasks", th.SetTask)                     // add new task
+		r.Put("/tasks/{id}/status", th.UpdateTaskStatus) // change status of task
+		r.Post("/tasks/{id}/snooze", th.SnoozeTask)      // remind about task later
+		r.Post("/tasks/{id}/comments", th.AddComment)    // add comment to task
+		r.Post("/tasks/{id}/attachments", at.Upload)     // attach file sent to bot
+		r.Delete("/tasks/{id}", th.DeleteTask)           // delete task
+
+		r.Post("/tasks/from-template", tp.CreateTaskFromTemplate) // add task by template
+
+		r.Post("/tasks/{id}/timer/start", tm.StartTimer) // start timer on task
+		r.Post("/timer/stop", tm.StopTimer)              // stop running timer
+
+		r.Get("/statuses", sh.GetAllStatuses) // get all statuses
+
+		r.Get("/notifications", nh.GetSettings)    // get notification settings
+		r.Put("/notifications", nh.UpdateSettings) // replace notification settings
+	})
+}
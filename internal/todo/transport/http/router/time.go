@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TimeRouter struct{}
+
+type TimeHandler interface {
+	StartTimer(w http.ResponseWriter, r *http.Request)
+	StopTimer(w http.ResponseWriter, r *http.Request)
+	GetTimer(w http.ResponseWriter, r *http.Request)
+	GetTimeEntries(w http.ResponseWriter, r *http.Request)
+	AddTimeEntry(w http.ResponseWriter, r *http.Request)
+	DeleteTimeEntry(w http.ResponseWriter, r *http.Request)
+	GetTimeReport(w http.ResponseWriter, r *http.Request)
+}
+
+func NewTimeRouter() *TimeRouter {
+	return &TimeRouter{}
+}
+
+func (b *TimeRouter) TimeRoutes(r chi.Router, h TimeHandler) {
+	// Routes for time tracking
+	r.Route("/api/tasks/{id}/time", func(r chi.Router) {
+		r.Use(middleware.JWT)        // need jwt for all methods
+		r.Get("/", h.GetTimeEntries) // get time entries of task
+		r.Post("/", h.AddTimeEntry)  // add time entry manually
+	})
+
+	r.Route("/api/timer", func(r chi.Router) {
+		r.Use(middleware.JWT)        // need jwt for all methods
+		r.Get("/", h.GetTimer)       // get running timer
+		r.Post("/stop", h.StopTimer) // stop running timer
+	})
+
+	r.With(middleware.JWT).Post("/api/tasks/{id}/timer/start", h.StartTimer) // start timer, running one is stopped
+	r.With(middleware.JWT).Delete("/api/time/{id}", h.DeleteTimeEntry)       // delete own time entry
+	r.With(middleware.JWT).Get("/api/reports/time", h.GetTimeReport)         // time report, ?format=csv for csv
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TransfersRouter struct{}
+
+type TransfersHandler interface {
+	MoveTask(w http.ResponseWriter, r *http.Request)
+	CopyTask(w http.ResponseWriter, r *http.Request)
+	CloneBoard(w http.ResponseWriter, r *http.Request)
+	GetTransfers(w http.ResponseWriter, r *http.Request)
+}
+
+func NewTransfersRouter() *TransfersRouter {
+	return &TransfersRouter{}
+}
+
+func (b *TransfersRouter) TransfersRoutes(r chi.Router, h TransfersHandler) {
+	// Routes for moving and copying tasks between boards
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.JWT)                               // need jwt for all methods
+		r.Post("/api/tasks/{id}/move-to-board", h.MoveTask) // move task to another board
+		r.Post("/api/tasks/{id}/copy-to-board", h.CopyTask) // copy task to board
+		r.Get("/api/tasks/{id}/transfers", h.GetTransfers)  // history of moves and copies
+		r.Post("/api/boards/{id}/clone", h.CloneBoard)      // clone board
+	})
+}
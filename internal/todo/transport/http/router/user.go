@@ -14,7 +14,11 @@ type UserHandler interface {
 	AuthorizateUser(w http.ResponseWriter, r *http.Request)
 	GetAuthUser(w http.ResponseWriter, r *http.Request)
 	UserLogout(w http.ResponseWriter, r *http.Request)
-	AddChatID(w http.ResponseWriter, r *http.Request)
+	CreateTelegramLink(w http.ResponseWriter, r *http.Request)
+	DeleteTelegramLink(w http.ResponseWriter, r *http.Request)
+	LinkTelegram(w http.ResponseWriter, r *http.Request)
+	ActivateTelegram(w http.ResponseWriter, r *http.Request)
+	TgUser(next http.Handler) http.Handler
 }
 
 func NewUserRouter() *UserRouter {
@@ -28,7 +32,10 @@ func (b *UserRouter) UserRoutes(r chi.Router, h UserHandler) {
 		r.Post("/login", h.AuthorizateUser)                    // login user
 		r.With(middleware.JWT).Get("/", h.GetAuthUser)         // get active user, need jwt
 		r.With(middleware.JWT).Delete("/logout", h.UserLogout) // logout user, need jwt
+
+		r.With(middleware.JWT).Post("/telegram/link", h.CreateTelegramLink)   // get one-time code for the bot
+		r.With(middleware.JWT).Delete("/telegram/link", h.DeleteTelegramLink) // unlink telegram
 	})
 
-	r.Post("/add-chat-id", h.AddChatID) // add chatID to table users
+	r.With(middleware.Signed).Post("/link-telegram", h.LinkTelegram) // bind telegram by code, called by tg service
 }
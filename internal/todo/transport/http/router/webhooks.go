@@ -0,0 +1,36 @@
+package router
+
+import (
+	"net/http"
+	"todo/internal/todo/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhooksRouter struct{}
+
+type WebhooksHandler interface {
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhooks(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	GetDeliveries(w http.ResponseWriter, r *http.Request)
+	Redeliver(w http.ResponseWriter, r *http.Request)
+}
+
+func NewWebhooksRouter() *WebhooksRouter {
+	return &WebhooksRouter{}
+}
+
+func (b *WebhooksRouter) WebhooksRoutes(r chi.Router, h WebhooksHandler) {
+	// Routes for webhooks of board, only for board owner
+	r.Route("/api/boards/{id}/webhooks", func(r chi.Router) {
+		r.Use(middleware.JWT)                                              // need jwt for all methods
+		r.Get("/", h.GetWebhooks)                                          // get webhooks of board
+		r.Post("/", h.CreateWebhook)                                       // add webhook
+		r.Put("/{hookID}", h.UpdateWebhook)                                // update webhook
+		r.Delete("/{hookID}", h.DeleteWebhook)                             // delete webhook
+		r.Get("/{hookID}/deliveries", h.GetDeliveries)                     // delivery log
+		r.Post("/{hookID}/deliveries/{deliveryID}/redeliver", h.Redeliver) // send delivery again
+	})
+}
@@ -0,0 +1,11 @@
+-- Журнал событий досок для SSE, хранится ограниченное время
+CREATE TABLE IF NOT EXISTS board_events (
+    id BIGSERIAL PRIMARY KEY,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS board_events_board_idx ON board_events (board_id, id);
+CREATE INDEX IF NOT EXISTS board_events_created_idx ON board_events (created_at);
@@ -0,0 +1,10 @@
+-- Открытые WebSocket-соединения с досками, по ним показывается, кто смотрит доску
+CREATE TABLE IF NOT EXISTS board_presence (
+    conn_id VARCHAR(64) PRIMARY KEY,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- обновляется сервером, соединения упавших экземпляров удаляются по нему
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS board_presence_board_idx ON board_presence (board_id);
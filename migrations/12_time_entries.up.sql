@@ -0,0 +1,18 @@
+-- Учёт времени по задачам: таймеры и записи, добавленные вручную
+CREATE TABLE IF NOT EXISTS time_entries (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    -- NULL у запущенного таймера
+    ended_at TIMESTAMPTZ,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS time_entries_task_idx ON time_entries (task_id);
+CREATE INDEX IF NOT EXISTS time_entries_started_idx ON time_entries (started_at);
+
+-- у пользователя может быть только один запущенный таймер
+CREATE UNIQUE INDEX IF NOT EXISTS time_entries_running_idx ON time_entries (user_id) WHERE ended_at IS NULL;
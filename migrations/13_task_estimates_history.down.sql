@@ -0,0 +1,4 @@
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS notify_digest_analytics;
+DROP TABLE IF EXISTS task_status_history;
+ALTER TABLE boards DROP COLUMN IF EXISTS estimate_unit;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate;
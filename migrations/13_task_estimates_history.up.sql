@@ -0,0 +1,24 @@
+-- Оценка задачи в единицах доски: story points или часы
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimate NUMERIC(8, 2);
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS estimate_unit VARCHAR(16) NOT NULL DEFAULT 'points';
+
+-- История смены статусов задач, по ней считается аналитика досок
+CREATE TABLE IF NOT EXISTS task_status_history (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    -- NULL при создании задачи
+    from_status_id INTEGER REFERENCES statuses(id) ON DELETE SET NULL,
+    to_status_id INTEGER REFERENCES statuses(id) ON DELETE SET NULL,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_status_history_task_idx ON task_status_history (task_id, changed_at);
+
+-- у существующих задач история начинается с текущего статуса на момент создания
+INSERT INTO task_status_history (task_id, to_status_id, changed_at)
+SELECT id, status_id, COALESCE(created_at, NOW()) FROM tasks t
+WHERE NOT EXISTS (SELECT 1 FROM task_status_history h WHERE h.task_id = t.id);
+
+-- недельная статистика в ежедневной сводке
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS notify_digest_analytics BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,20 @@
+-- Пользовательские поля досок, новые поля добавляются без изменения схемы
+CREATE TABLE IF NOT EXISTS board_fields (
+    id SERIAL PRIMARY KEY,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    -- text, number, date, select, multi_select, user или url
+    type VARCHAR(16) NOT NULL,
+    -- варианты для select и multi_select
+    options TEXT[] NOT NULL DEFAULT '{}',
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS board_fields_name_idx ON board_fields (board_id, lower(name));
+
+-- Значения полей задачи, ключ — id поля
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS tasks_custom_fields_idx ON tasks USING GIN (custom_fields jsonb_path_ops);
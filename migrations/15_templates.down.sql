@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_templates;
+DROP TABLE IF EXISTS board_templates;
@@ -0,0 +1,33 @@
+-- Шаблоны досок: снимок статусов, пользовательских полей и задач доски
+CREATE TABLE IF NOT EXISTS board_templates (
+    id SERIAL PRIMARY KEY,
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    estimate_unit VARCHAR(16) NOT NULL DEFAULT 'points',
+    -- названия статусов, при создании доски недостающие статусы добавляются
+    statuses TEXT[] NOT NULL DEFAULT '{}',
+    -- поля и задачи хранятся целиком, значения полей задач — по названию поля
+    fields JSONB NOT NULL DEFAULT '[]',
+    tasks JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS board_templates_name_idx ON board_templates (owner_id, lower(name));
+
+-- Шаблоны задач доски, создаются через api и командой /new в боте
+CREATE TABLE IF NOT EXISTS task_templates (
+    id SERIAL PRIMARY KEY,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    estimate NUMERIC(8, 2),
+    -- срок задачи отсчитывается от ее создания
+    due_in_minutes INTEGER,
+    custom_fields JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS task_templates_name_idx ON task_templates (board_id, lower(name));
@@ -0,0 +1,18 @@
+-- История переносов и копирования задач между досками и клонирования досок
+CREATE TABLE IF NOT EXISTS transfers (
+    id SERIAL PRIMARY KEY,
+    -- move — перенос задачи, copy — копия задачи, clone — клон доски
+    kind VARCHAR(16) NOT NULL,
+    -- задача после переноса или копия, NULL для клона доски
+    task_id INTEGER REFERENCES tasks(id) ON DELETE CASCADE,
+    -- исходная задача копии
+    source_task_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL,
+    from_board_id INTEGER REFERENCES boards(id) ON DELETE SET NULL,
+    to_board_id INTEGER REFERENCES boards(id) ON DELETE SET NULL,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS transfers_task_idx ON transfers (task_id);
+CREATE INDEX IF NOT EXISTS transfers_source_task_idx ON transfers (source_task_id);
+CREATE INDEX IF NOT EXISTS transfers_to_board_idx ON transfers (to_board_id);
@@ -0,0 +1,18 @@
+-- Импортированные из Trello и Todoist доски и задачи, по ним повторный импорт пропускает уже созданное
+CREATE TABLE IF NOT EXISTS imports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- trello или todoist
+    source VARCHAR(16) NOT NULL,
+    -- board или task
+    kind VARCHAR(16) NOT NULL,
+    -- id доски или задачи в исходном сервисе
+    external_id VARCHAR(128) NOT NULL,
+    -- при удалении доски ее можно импортировать заново
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    -- удаленная задача остается в журнале и повторно не создается
+    task_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS imports_item_idx ON imports (user_id, source, kind, external_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_attachments;
+DROP FUNCTION IF EXISTS task_attachments_deleted();
+DROP TABLE IF EXISTS deleted_blobs;
@@ -0,0 +1,34 @@
+-- Вложения задач, сами файлы лежат в хранилище файлов (каталог или S3) по ключу storage_key
+CREATE TABLE IF NOT EXISTS task_attachments (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    -- загрузивший пользователь, вложение остается после удаления пользователя
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    -- hex sha256 содержимого
+    sha256 CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_attachments_task_idx ON task_attachments (task_id, id);
+
+-- Ключи файлов удаленных вложений, фоновая задача удаляет файлы из хранилища
+CREATE TABLE IF NOT EXISTS deleted_blobs (
+    storage_key VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Вложения удаляются и вместе с задачами и досками, поэтому ключ файла сохраняет триггер
+CREATE OR REPLACE FUNCTION task_attachments_deleted() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO deleted_blobs (storage_key) VALUES (OLD.storage_key) ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_attachments_deleted ON task_attachments;
+CREATE TRIGGER task_attachments_deleted AFTER DELETE ON task_attachments
+    FOR EACH ROW EXECUTE FUNCTION task_attachments_deleted();
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS tg_inactive_at;
+ALTER TABLE users DROP COLUMN IF EXISTS tg_active;
@@ -0,0 +1,4 @@
+-- Связь с телеграмом выключается, когда пользователь заблокировал бота или удалил чат,
+-- уведомления в телеграм ему не отправляются до следующего /start
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tg_active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tg_inactive_at TIMESTAMPTZ;
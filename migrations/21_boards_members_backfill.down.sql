@@ -0,0 +1,2 @@
+-- Участники, добавленные миграцией, не отличаются от добавленных вручную, поэтому откатывается только отметка
+DELETE FROM data_backfills WHERE name = 'boards_members';
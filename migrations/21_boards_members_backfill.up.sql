@@ -0,0 +1,32 @@
+-- Миграции выполняются при каждом запуске, а удаленный участник не должен вернуться на доску,
+-- поэтому заполнение данных отмечается в data_backfills и выполняется один раз
+CREATE TABLE IF NOT EXISTS data_backfills (
+    name VARCHAR(100) PRIMARY KEY,
+    done_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+    INSERT INTO data_backfills (name) VALUES ('boards_members') ON CONFLICT DO NOTHING;
+    IF NOT FOUND THEN
+        RETURN;
+    END IF;
+
+    -- До проверки участников любой пользователь видел все доски, а доски создавались без участников.
+    -- Такие доски остаются доступными всем существующим пользователям, как и раньше
+    INSERT INTO boards_users (user_id, board_id)
+    SELECT u.id, b.id FROM boards b CROSS JOIN users u
+    WHERE NOT EXISTS (SELECT 1 FROM boards_users bu WHERE bu.board_id = b.id)
+    ON CONFLICT (board_id, user_id) DO NOTHING;
+
+    -- исполнители задач доски не теряют доступ к своим задачам
+    INSERT INTO boards_users (user_id, board_id)
+    SELECT DISTINCT t.user_id, t.board_id FROM tasks t
+    WHERE t.user_id IS NOT NULL AND t.board_id IS NOT NULL
+    ON CONFLICT (board_id, user_id) DO NOTHING;
+
+    -- владельцем доски без владельца становится первый участник
+    UPDATE boards b SET owner_id = (
+        SELECT bu.user_id FROM boards_users bu WHERE bu.board_id = b.id ORDER BY bu.id LIMIT 1
+    ) WHERE owner_id IS NULL;
+END $$;
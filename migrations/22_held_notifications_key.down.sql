@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS held_notifications_key_uniq;
+ALTER TABLE held_notifications DROP COLUMN IF EXISTS idempotency_key;
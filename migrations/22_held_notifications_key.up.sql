@@ -0,0 +1,4 @@
+-- Повтор доставки из outbox не добавляет отложенное уведомление второй раз
+ALTER TABLE held_notifications ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS held_notifications_key_uniq ON held_notifications (idempotency_key);
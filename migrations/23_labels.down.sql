@@ -0,0 +1,4 @@
+ALTER TABLE task_templates DROP COLUMN IF EXISTS labels;
+ALTER TABLE board_templates DROP COLUMN IF EXISTS labels;
+DROP TABLE IF EXISTS task_labels;
+DROP TABLE IF EXISTS board_labels;
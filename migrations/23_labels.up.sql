@@ -0,0 +1,25 @@
+-- Метки досок, задача может иметь несколько меток своей доски
+CREATE TABLE IF NOT EXISTS board_labels (
+    id SERIAL PRIMARY KEY,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name VARCHAR(32) NOT NULL,
+    -- цвет в формате #rrggbb или пустая строка
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS board_labels_name_idx ON board_labels (board_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    label_id INTEGER NOT NULL REFERENCES board_labels(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_labels_label_idx ON task_labels (label_id);
+
+-- Шаблоны досок хранят метки целиком, метки их задач — по названию
+ALTER TABLE board_templates ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '[]';
+
+-- Шаблоны задач хранят id меток своей доски
+ALTER TABLE task_templates ADD COLUMN IF NOT EXISTS labels INTEGER[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS email_code_expires_at;
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS email_code;
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS email_confirmed;
@@ -0,0 +1,5 @@
+-- Письма уходят только на подтвержденный адрес: после смены email на него отправляется код,
+-- адреса, сохраненные до этой миграции, тоже нужно подтвердить
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS email_confirmed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS email_code VARCHAR(32);
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS email_code_expires_at TIMESTAMPTZ;
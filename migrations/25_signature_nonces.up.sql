@@ -0,0 +1,8 @@
+-- Использованные nonce подписанных внутренних запросов общие для всех экземпляров todo,
+-- поэтому перехваченный запрос нельзя повторить на другом экземпляре
+CREATE TABLE IF NOT EXISTS signature_nonces (
+    nonce VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS signature_nonces_expires_idx ON signature_nonces (expires_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tg_link_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS tg_user_id;
@@ -0,0 +1,11 @@
+-- Привязка телеграма по одноразовому коду и числовому id пользователя
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tg_user_id BIGINT UNIQUE;
+ALTER TABLE users ALTER COLUMN chat_id TYPE BIGINT;
+ALTER TABLE users ALTER COLUMN tg_name DROP NOT NULL;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tg_name_key;
+
+CREATE TABLE IF NOT EXISTS tg_link_codes (
+    code VARCHAR(32) PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
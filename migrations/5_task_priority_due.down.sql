@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS boards_users_uniq;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
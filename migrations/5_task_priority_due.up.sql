@@ -0,0 +1,5 @@
+-- Приоритет и срок выполнения задачи
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS boards_users_uniq ON boards_users (board_id, user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_comments;
+ALTER TABLE boards DROP COLUMN IF EXISTS chat_id;
+ALTER TABLE boards DROP COLUMN IF EXISTS owner_id;
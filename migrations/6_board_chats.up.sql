@@ -0,0 +1,15 @@
+-- Владелец доски, групповой чат доски и комментарии к задачам
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE boards ADD COLUMN IF NOT EXISTS chat_id BIGINT UNIQUE;
+
+-- владелец существующих досок заполняется один раз в 21_boards_members_backfill
+
+CREATE TABLE IF NOT EXISTS task_comments (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_comments_task_idx ON task_comments (task_id);
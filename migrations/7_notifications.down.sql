@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS held_notifications;
+DROP TABLE IF EXISTS board_mutes;
+DROP TABLE IF EXISTS notification_settings;
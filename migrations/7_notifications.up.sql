@@ -0,0 +1,31 @@
+-- Настройки уведомлений пользователя
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    notify_created BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_assigned BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_commented BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_due BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_status_changed BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_digest BOOLEAN NOT NULL DEFAULT TRUE,
+    -- тихие часы в минутах от начала суток в часовом поясе пользователя
+    quiet_from SMALLINT,
+    quiet_to SMALLINT,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC'
+);
+
+-- Доски, уведомления которых пользователь отключил
+CREATE TABLE IF NOT EXISTS board_mutes (
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    board_id INTEGER REFERENCES boards(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, board_id)
+);
+
+-- Уведомления, отложенные на время тихих часов
+CREATE TABLE IF NOT EXISTS held_notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS held_notifications_user_idx ON held_notifications (user_id);
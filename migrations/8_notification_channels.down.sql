@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_channels;
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS webhook_secret;
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS webhook_url;
+ALTER TABLE notification_settings DROP COLUMN IF EXISTS email;
@@ -0,0 +1,12 @@
+-- Каналы доставки уведомлений: telegram, email, webhook
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS webhook_url TEXT;
+ALTER TABLE notification_settings ADD COLUMN IF NOT EXISTS webhook_secret TEXT;
+
+-- каналы для типа уведомления, без записей используется telegram
+CREATE TABLE IF NOT EXISTS notification_channels (
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    channel VARCHAR(16) NOT NULL,
+    PRIMARY KEY (user_id, kind, channel)
+);
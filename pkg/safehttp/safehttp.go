@@ -0,0 +1,124 @@
+// Package safehttp makes requests to urls given by users, such requests must not reach
+// the internal network: loopback, private, link-local and other special addresses are refused
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress means host of url is an internal address
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// special ranges which aren't covered by methods of net.IP
+var reserved = mustParseCIDRs(
+	"0.0.0.0/8",     // this network
+	"100.64.0.0/10", // carrier-grade nat
+	"192.0.0.0/24",  // protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved, broadcast included
+	"64:ff9b::/96",  // nat64 and 6to4 wrap any ipv4 address
+	"2002::/16",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+
+	return nets
+}
+
+// Allowed reports ip is a public address
+func Allowed(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, n := range reserved {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL parses http or https url and checks its host resolves only to public addresses.
+// Address may change after the check, so requests are made by NewClient which checks it again
+func CheckURL(ctx context.Context, raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, errors.New("must be http or https url")
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if !Allowed(ip) {
+			return nil, ErrForbiddenAddress
+		}
+		return u, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("host can't be resolved: %w", err)
+	}
+
+	for _, ip := range ips {
+		if !Allowed(ip) {
+			return nil, ErrForbiddenAddress
+		}
+	}
+
+	return u, nil
+}
+
+// NewClient returns client which connects only to public addresses. The address is checked
+// after name resolution, so redirects and dns rebinding don't reach internal network too
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip := net.ParseIP(host)
+			if ip == nil || !Allowed(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+
+			return nil
+		},
+	}
+
+	// proxy from environment would connect to its own address instead of checked one
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
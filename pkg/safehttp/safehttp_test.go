@@ -0,0 +1,80 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := Allowed(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("Allowed(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{"https://8.8.8.8/hook", nil},
+		{"http://127.0.0.1:8080/hook", ErrForbiddenAddress},
+		{"http://[::1]/hook", ErrForbiddenAddress},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenAddress},
+		{"http://localhost/hook", ErrForbiddenAddress},
+	}
+
+	for _, tt := range tests {
+		_, err := CheckURL(context.Background(), tt.url)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("CheckURL(%s) = %v, want %v", tt.url, err, tt.wantErr)
+		}
+	}
+
+	for _, raw := range []string{"ftp://example.com", "example.com/hook", "http://"} {
+		if _, err := CheckURL(context.Background(), raw); err == nil {
+			t.Errorf("CheckURL(%s) accepted", raw)
+		}
+	}
+}
+
+// client refuses to connect to loopback even when url passed the check earlier
+func TestNewClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(time.Second).Get(srv.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("request to %s: %v", srv.URL, err)
+	}
+}
@@ -0,0 +1,173 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+
+	// requests older or newer than window are rejected
+	DefaultWindow = 5 * time.Minute
+
+	// the largest signed body is file sent to bot, up to 20 MB with multipart headers
+	MaxBodySize = 21 << 20
+)
+
+// Compute returns hex encoded HMAC-SHA256 of timestamp, nonce, method, uri and body
+func Compute(secret string, timestamp string, nonce string, method string, uri string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n", timestamp, nonce, method, uri)
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign adds timestamp, random nonce and signature headers to request with given body
+func Sign(req *http.Request, body []byte, secret string) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := newNonce()
+
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Compute(secret, timestamp, nonce, req.Method, req.URL.RequestURI(), body))
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// NonceStore remembers used nonces till expiresAt, Add is false when nonce is already used.
+// Store must be shared by all instances of service, otherwise request can be replayed to another one
+type NonceStore interface {
+	Add(nonce string, expiresAt time.Time) (bool, error)
+}
+
+// Verify returns middleware which rejects requests without valid signature.
+// Nonces are kept in memory of the process, so it fits only service run as a single instance
+func Verify(secret string, window time.Duration) func(http.Handler) http.Handler {
+	return VerifyWith(secret, window, newReplayCache(window))
+}
+
+// VerifyWith is Verify with nonces in given store.
+// Every nonce is accepted once per window, so a captured request can't be replayed,
+// while two equal requests made in the same second have different nonces and both pass
+func VerifyWith(secret string, window time.Duration, seen NonceStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// without secret nobody can call internal routes
+			if secret == "" {
+				http.Error(w, "internal api is not configured", http.StatusUnauthorized)
+				return
+			}
+
+			timestamp := r.Header.Get(HeaderTimestamp)
+			nonce := r.Header.Get(HeaderNonce)
+			sign := r.Header.Get(HeaderSignature)
+			if timestamp == "" || nonce == "" || sign == "" {
+				http.Error(w, "signature is missing", http.StatusUnauthorized)
+				return
+			}
+
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid signature timestamp", http.StatusUnauthorized)
+				return
+			}
+
+			age := time.Since(time.Unix(unix, 0))
+			if age > window || age < -window {
+				http.Error(w, "signature expired", http.StatusUnauthorized)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body is too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Invalid input", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			expected := Compute(secret, timestamp, nonce, r.Method, r.URL.RequestURI(), body)
+			if !hmac.Equal([]byte(expected), []byte(sign)) {
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+
+			// request is accepted till timestamp leaves window, nonce is kept as long
+			fresh, err := seen.Add(nonce, time.Unix(unix, 0).Add(window))
+			if err != nil {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if !fresh {
+				http.Error(w, "request already processed", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type replayCache struct {
+	mu        sync.Mutex
+	window    time.Duration
+	items     map[string]time.Time // nonce to its expiration
+	lastSweep time.Time
+}
+
+func newReplayCache(window time.Duration) *replayCache {
+	return &replayCache{
+		window:    window,
+		items:     make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Add stores nonce, false if it was already used.
+// Expired nonces are dropped once per window, not on every request
+func (c *replayCache) Add(nonce string, expiresAt time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.window {
+		for k, expiresAt := range c.items {
+			if now.After(expiresAt) {
+				delete(c.items, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if _, ok := c.items[nonce]; ok {
+		return false, nil
+	}
+
+	c.items[nonce] = expiresAt
+	return true, nil
+}
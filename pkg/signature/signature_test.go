@@ -0,0 +1,99 @@
+package signature
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "secret"
+
+func signedRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/create-task?x=1", strings.NewReader(body))
+	Sign(req, []byte(body), testSecret)
+	return req
+}
+
+func serve(h http.Handler, req *http.Request) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestVerify(t *testing.T) {
+	h := Verify(testSecret, DefaultWindow)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := signedRequest(`{"a":1}`)
+	if code := serve(h, req); code != http.StatusCreated {
+		t.Fatalf("signed request: got %d", code)
+	}
+
+	// the same request with the same nonce is a replay
+	replay := httptest.NewRequest(http.MethodPost, "/create-task?x=1", strings.NewReader(`{"a":1}`))
+	replay.Header = req.Header.Clone()
+	if code := serve(h, replay); code != http.StatusUnauthorized {
+		t.Fatalf("replayed request: got %d", code)
+	}
+
+	// equal requests made in the same second are both accepted
+	if code := serve(h, signedRequest(`{"a":1}`)); code != http.StatusCreated {
+		t.Fatalf("second equal request: got %d", code)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *http.Request)
+		body   string
+		want   int
+	}{
+		{"missing nonce", func(r *http.Request) { r.Header.Del(HeaderNonce) }, `{}`, http.StatusUnauthorized},
+		{"wrong nonce", func(r *http.Request) { r.Header.Set(HeaderNonce, "other") }, `{}`, http.StatusUnauthorized},
+		{"changed body", nil, `{"b":2}`, http.StatusUnauthorized},
+		{"expired", func(r *http.Request) {
+			timestamp := "1000"
+			nonce := r.Header.Get(HeaderNonce)
+			r.Header.Set(HeaderTimestamp, timestamp)
+			r.Header.Set(HeaderSignature, Compute(testSecret, timestamp, nonce, r.Method, r.URL.RequestURI(), []byte(`{}`)))
+		}, `{}`, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := signedRequest(`{}`)
+			if tt.modify != nil {
+				tt.modify(req)
+			}
+			req.Body = io.NopCloser(strings.NewReader(tt.body))
+			if code := serve(h, req); code != tt.want {
+				t.Fatalf("got %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyBodyLimit(t *testing.T) {
+	h := Verify(testSecret, DefaultWindow)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := strings.Repeat("a", MaxBodySize+1)
+	if code := serve(h, signedRequest(body)); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got %d, want 413", code)
+	}
+}
+
+func TestReplayCacheSweep(t *testing.T) {
+	c := newReplayCache(time.Minute)
+	c.items["old"] = time.Now().Add(-3 * time.Minute)
+	c.lastSweep = time.Now().Add(-2 * time.Minute)
+
+	if ok, _ := c.Add("new", time.Now().Add(time.Minute)); !ok {
+		t.Fatal("new nonce is rejected")
+	}
+	if _, ok := c.items["old"]; ok {
+		t.Fatal("expired nonce is kept")
+	}
+}